@@ -2,18 +2,32 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 
+	"github.com/mikeewhite/ship-locator/backend/internal/core/ports"
 	"github.com/mikeewhite/ship-locator/backend/internal/core/services/collectorsrv"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/capture"
 	"github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka/producer"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/nmea"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/replay"
 	"github.com/mikeewhite/ship-locator/backend/internal/handlers/websocket"
 	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
 	"github.com/mikeewhite/ship-locator/backend/pkg/config"
 	"github.com/mikeewhite/ship-locator/backend/pkg/metrics"
+	"github.com/mikeewhite/ship-locator/backend/pkg/tracing"
+)
+
+const (
+	sourceAISStream = "aisstream"
+	sourceNMEA      = "nmea"
+	sourceReplay    = "replay"
 )
 
 func main() {
@@ -28,9 +42,16 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	gracefulShutdownOnSignal(cancel)
 
+	traceProvider, err := tracing.NewTraceProvider(ctx, *cfg, "collector")
+	if err != nil {
+		panic(fmt.Sprintf("error on initialising trace provider: %s", err.Error()))
+	}
+	// flushed once the buffered reports have been published (by which time ctx has been cancelled)
+	defer traceProvider.Shutdown(context.Background())
+
 	metricsClient := metrics.New(*cfg)
 	go func() {
-		if err := metricsClient.Serve(ctx); err != nil && err != http.ErrServerClosed {
+		if err := metricsClient.Serve(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			clog.Errorf("metrics client stopped due to error: %s", err.Error())
 		}
 	}()
@@ -39,18 +60,103 @@ func main() {
 	if err != nil {
 		panic(fmt.Sprintf("failed to initialise Kafka producer: %s", err.Error()))
 	}
+	// deferred calls run in reverse order so the Kafka writer is closed once the buffered reports have been flushed
 	defer producer.Shutdown()
 
-	service := collectorsrv.New(ctx, producer)
+	service, err := collectorsrv.New(ctx, *cfg, producer, metricsClient)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialise collector service: %s", err.Error()))
+	}
 	defer service.Shutdown()
-	listener, err := websocket.NewWebSocketListener(*cfg, service)
+	var recorder websocket.FrameRecorder
+	if cfg.CaptureDirectory != "" {
+		captureRecorder, err := capture.NewRecorder(*cfg, sourceAISStream)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialise capture recorder: %s", err.Error()))
+		}
+		defer func() {
+			if err := captureRecorder.Close(); err != nil {
+				clog.Errorf("failed to close capture recorder: %s", err.Error())
+			}
+		}()
+		recorder = captureRecorder
+	}
+	var quarantine websocket.Quarantine
+	if cfg.QuarantineFile != "" {
+		fileQuarantine, err := capture.NewQuarantine(*cfg)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialise quarantine: %s", err.Error()))
+		}
+		defer func() {
+			if err := fileQuarantine.Close(); err != nil {
+				clog.Errorf("failed to close quarantine: %s", err.Error())
+			}
+		}()
+		quarantine = fileQuarantine
+	}
+	sources, err := newSources(*cfg, service, metricsClient, recorder, quarantine)
 	if err != nil {
-		panic(fmt.Sprintf("failed to initialise websocket listener: %s", err.Error()))
+		panic(fmt.Sprintf("failed to initialise sources: %s", err.Error()))
 	}
-	defer listener.Shutdown()
-	if err := listener.Listen(ctx); err != nil {
-		clog.Errorf("websocket listener stopped due to error: %s", err.Error())
+
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source ports.Source) {
+			defer wg.Done()
+			clog.Infow("starting source", "source", source.Name())
+			if err := source.Listen(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				clog.Errorw("source stopped due to error",
+					"source", source.Name(),
+					"error", err.Error())
+			}
+		}(source)
 	}
+	wg.Wait()
+	// stop the collector service once every source has finished (e.g. all capture files have been replayed)
+	cancel()
+}
+
+// newSources creates the sources named in the config, all of which feed into the given collector service
+func newSources(cfg config.Config, service ports.CollectorService, metricsClient *metrics.Client,
+	recorder websocket.FrameRecorder, quarantine websocket.Quarantine) ([]ports.Source, error) {
+	var sources []ports.Source
+	for _, name := range cfg.CollectorSources {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case sourceAISStream:
+			// each shard of bounding boxes gets its own connection and reconnects independently of the others
+			listeners, err := websocket.NewShardedWebSocketListeners(cfg, service, metricsClient, recorder, quarantine)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialise websocket listeners: %w", err)
+			}
+			reloadSubscriptionsOnSignal(listeners)
+			for _, listener := range listeners {
+				sources = append(sources, listener)
+			}
+		case sourceNMEA:
+			for _, address := range cfg.NMEAAddresses {
+				listener, err := nmea.NewListener(cfg, address, service)
+				if err != nil {
+					return nil, fmt.Errorf("failed to initialise NMEA listener: %w", err)
+				}
+				sources = append(sources, listener)
+			}
+		case sourceReplay:
+			for _, path := range cfg.ReplayFiles {
+				replayer, err := replay.NewReplayer(cfg, path, service)
+				if err != nil {
+					return nil, fmt.Errorf("failed to initialise replayer: %w", err)
+				}
+				sources = append(sources, replayer)
+			}
+		default:
+			return nil, fmt.Errorf("unknown source '%s'", name)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("at least one source must be configured")
+	}
+	return sources, nil
 }
 
 func gracefulShutdownOnSignal(cancel context.CancelFunc) {
@@ -63,3 +169,28 @@ func gracefulShutdownOnSignal(cancel context.CancelFunc) {
 		cancel()
 	}()
 }
+
+// reloadSubscriptionsOnSignal re-reads the config on SIGHUP and re-sends the web socket subscriptions so that the
+// bounding boxes and filters in the subscription file can be changed without restarting the collector (the env vars
+// can't change while it's running). Shards can't be added or removed this way as the connections are only opened at
+// startup.
+func reloadSubscriptionsOnSignal(listeners []*websocket.WebSocketListener) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			cfg, err := config.Load()
+			if err != nil {
+				clog.Errorf("failed to reload config: %s", err.Error())
+				continue
+			}
+			for _, listener := range listeners {
+				if err := listener.UpdateSubscription(*cfg); err != nil {
+					clog.Errorw("failed to update web socket subscription",
+						"source", listener.Name(),
+						"error", err.Error())
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,270 @@
+// Command dlq inspects the messages on the dead-letter topic and re-drives them to the topics they came from.
+//
+// Usage:
+//
+//	dlq inspect [-source topic] [-limit n]
+//	dlq redrive [-idle duration]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	kafka2 "github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka/producer"
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+// redriveGroupSuffix is appended to the consumer group to give the group used to track which dead letters have been
+// re-driven
+const redriveGroupSuffix = "-dead-letter-redrive"
+
+const usage = `Usage:
+  dlq inspect [-source topic] [-limit n]   print the dead letters as JSON lines
+  dlq redrive [-idle duration]             publish the dead letters back to their source topics`
+
+// inspectedMessage is printed for each dead letter by the inspect command
+type inspectedMessage struct {
+	Partition       int       `json:"partition"`
+	Offset          int64     `json:"offset"`
+	Key             string    `json:"key"`
+	SourceTopic     string    `json:"sourceTopic"`
+	SourcePartition int       `json:"sourcePartition"`
+	SourceOffset    int64     `json:"sourceOffset"`
+	Error           string    `json:"error"`
+	Attempts        int       `json:"attempts"`
+	FailedAt        time.Time `json:"failedAt"`
+	Value           string    `json:"value"`
+}
+
+// offsetRange is the range of offsets held by a partition, where last is the offset the next message will be given
+type offsetRange struct {
+	partition int
+	first     int64
+	last      int64
+}
+
+func main() {
+	defer clog.Flush()
+
+	if len(os.Args) < 2 {
+		exit(errors.New(usage))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		exit(fmt.Errorf("error on loading config: %w", err))
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "inspect":
+		flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+		source := flags.String("source", "", "only print dead letters from this topic")
+		limit := flags.Int("limit", 0, "maximum number of dead letters to print (0 prints them all)")
+		_ = flags.Parse(args)
+		err = inspect(ctx, *cfg, *source, *limit)
+	case "redrive":
+		flags := flag.NewFlagSet("redrive", flag.ExitOnError)
+		idle := flags.Duration("idle", 10*time.Second, "stop once no dead letters have been read for this long")
+		_ = flags.Parse(args)
+		err = redrive(ctx, *cfg, *idle)
+	default:
+		err = fmt.Errorf("unknown command '%s'\n%s", command, usage)
+	}
+	if err != nil {
+		exit(err)
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err.Error())
+	clog.Flush()
+	os.Exit(1)
+}
+
+// inspect prints every message on the dead-letter topic without affecting the re-drive consumer group
+func inspect(ctx context.Context, cfg config.Config, source string, limit int) error {
+	offsets, err := partitionOffsets(ctx, cfg, cfg.KafkaDeadLetterTopic)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	printed := 0
+	for _, offsets := range offsets {
+		if offsets.first >= offsets.last {
+			continue
+		}
+		if limit > 0 && printed >= limit {
+			break
+		}
+		partition := offsets.partition
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:   []string{cfg.KafkaAddress},
+			Topic:     cfg.KafkaDeadLetterTopic,
+			Partition: partition,
+			MaxBytes:  10e6, // 10MB
+		})
+		err := func() error {
+			defer reader.Close()
+			if err := reader.SetOffset(offsets.first); err != nil {
+				return fmt.Errorf("error on seeking partition %d: %w", partition, err)
+			}
+			for limit == 0 || printed < limit {
+				m, err := reader.ReadMessage(ctx)
+				if err != nil {
+					return fmt.Errorf("error on reading partition %d: %w", partition, err)
+				}
+				dl, err := kafka2.NewDeadLetterFromKafkaMsg(&m)
+				if err != nil {
+					clog.Warnw("skipping invalid dead letter",
+						"partition", m.Partition,
+						"offset", m.Offset,
+						"error", err.Error())
+				} else if source == "" || dl.SourceTopic == source {
+					if err := encoder.Encode(newInspectedMessage(&m, dl)); err != nil {
+						return fmt.Errorf("error on printing dead letter: %w", err)
+					}
+					printed++
+				}
+				if m.Offset+1 >= offsets.last {
+					return nil
+				}
+			}
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newInspectedMessage(m *kafka.Message, dl *kafka2.DeadLetter) inspectedMessage {
+	return inspectedMessage{
+		Partition:       m.Partition,
+		Offset:          m.Offset,
+		Key:             string(m.Key),
+		SourceTopic:     dl.SourceTopic,
+		SourcePartition: dl.SourcePartition,
+		SourceOffset:    dl.SourceOffset,
+		Error:           dl.Error,
+		Attempts:        dl.Attempts,
+		FailedAt:        dl.FailedAt,
+		Value:           string(m.Value),
+	}
+}
+
+// redrive publishes the dead letters back to their source topics, committing the offset of each one once it has been
+// published so that it isn't re-driven again. Only the dead letters present when the re-drive started are re-driven,
+// so messages that fail again are left on the dead-letter topic for the next run rather than being re-driven in a
+// loop.
+func redrive(ctx context.Context, cfg config.Config, idle time.Duration) error {
+	offsets, err := partitionOffsets(ctx, cfg, cfg.KafkaDeadLetterTopic)
+	if err != nil {
+		return err
+	}
+	pending := make(map[int]int64)
+	for _, offsets := range offsets {
+		if offsets.first < offsets.last {
+			pending[offsets.partition] = offsets.last
+		}
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  []string{cfg.KafkaAddress},
+		GroupID:  cfg.KafkaConsumerGroup + redriveGroupSuffix,
+		Topic:    cfg.KafkaDeadLetterTopic,
+		MaxBytes: 10e6, // 10MB
+	})
+	defer reader.Close()
+	deadLetterProducer, err := producer.NewDeadLetterProducer(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialise dead-letter producer: %w", err)
+	}
+	defer deadLetterProducer.Shutdown()
+
+	redriven := 0
+	for len(pending) > 0 {
+		// a partition whose dead letters have all been re-driven by a previous run yields nothing, so give up waiting
+		// for it once the reader has been idle for a while
+		fetchCtx, cancel := context.WithTimeout(ctx, idle)
+		m, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			return fmt.Errorf("error on fetching dead letter: %w", err)
+		}
+
+		end, found := pending[m.Partition]
+		if !found || m.Offset >= end {
+			// dead-lettered after the re-drive started so left for the next run
+			delete(pending, m.Partition)
+			continue
+		}
+		if _, err := kafka2.NewDeadLetterFromKafkaMsg(&m); err != nil {
+			clog.Warnw("skipping invalid dead letter",
+				"partition", m.Partition,
+				"offset", m.Offset,
+				"error", err.Error())
+		} else {
+			if err := deadLetterProducer.Redrive(ctx, m); err != nil {
+				return fmt.Errorf("error on re-driving dead letter: %w", err)
+			}
+			redriven++
+		}
+		if err := reader.CommitMessages(ctx, m); err != nil {
+			return fmt.Errorf("error on committing offset %d of partition %d: %w", m.Offset, m.Partition, err)
+		}
+		if m.Offset+1 >= end {
+			delete(pending, m.Partition)
+		}
+	}
+
+	clog.Infow("re-drove dead letters",
+		"count", redriven)
+	return nil
+}
+
+// partitionOffsets returns the range of offsets held by each partition of the topic
+func partitionOffsets(ctx context.Context, cfg config.Config, topic string) ([]offsetRange, error) {
+	conn, err := kafka.DialContext(ctx, "tcp", cfg.KafkaAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error on connecting to Kafka: %w", err)
+	}
+	defer conn.Close()
+	partitions, err := conn.ReadPartitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("error on reading partitions of topic '%s': %w", topic, err)
+	}
+
+	offsets := make([]offsetRange, 0, len(partitions))
+	for _, partition := range partitions {
+		leader, err := kafka.DialLeader(ctx, "tcp", cfg.KafkaAddress, topic, partition.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error on connecting to leader of partition %d: %w", partition.ID, err)
+		}
+		first, last, err := leader.ReadOffsets()
+		leader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error on reading offsets of partition %d: %w", partition.ID, err)
+		}
+		offsets = append(offsets, offsetRange{partition: partition.ID, first: first, last: last})
+	}
+	return offsets, nil
+}
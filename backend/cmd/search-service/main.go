@@ -12,6 +12,7 @@ import (
 	"github.com/mikeewhite/ship-locator/backend/internal/core/services/shipsrcsrv"
 	"github.com/mikeewhite/ship-locator/backend/internal/handlers/graphql/searchgraph"
 	"github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka/consumer"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka/producer"
 	"github.com/mikeewhite/ship-locator/backend/internal/repositories/shipsrc/elasticsearch"
 	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
 	"github.com/mikeewhite/ship-locator/backend/pkg/config"
@@ -45,8 +46,13 @@ func main() {
 	}
 	searchService := shipsrcsrv.New(searchRepo)
 
-	// start the ship event consumer
-	shipEventConsumer, err := consumer.NewShipEventConsumer(*cfg, searchService, metricsClient)
+	// start the ship event consumer, routing events it can't process to the dead-letter topic
+	deadLetterProducer, err := producer.NewDeadLetterProducer(*cfg)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialise dead-letter producer: %s", err.Error()))
+	}
+	defer deadLetterProducer.Shutdown()
+	shipEventConsumer, err := consumer.NewShipEventConsumer(*cfg, searchService, deadLetterProducer, metricsClient)
 	if err != nil {
 		panic(fmt.Sprintf("failed to initialise ship event consumer: %s", err.Error()))
 	}
@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/mikeewhite/ship-locator/backend/internal/core/services/safetysrv"
 	"github.com/mikeewhite/ship-locator/backend/internal/core/services/shipsrcsrv"
 	"github.com/mikeewhite/ship-locator/backend/internal/handlers/graphql/shipgraph"
 	"github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka/consumer"
@@ -16,6 +17,7 @@ import (
 	"github.com/mikeewhite/ship-locator/backend/internal/repositories/shipsrc/elasticsearch"
 
 	"github.com/mikeewhite/ship-locator/backend/internal/core/services/shipsrv"
+	"github.com/mikeewhite/ship-locator/backend/internal/core/services/stationsrv"
 	"github.com/mikeewhite/ship-locator/backend/internal/repositories/postgres"
 	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
 	"github.com/mikeewhite/ship-locator/backend/pkg/config"
@@ -63,13 +65,38 @@ func main() {
 	if err != nil {
 		panic(fmt.Sprintf("failed to initialise Postgres repository: %s", err.Error()))
 	}
-	shipEventProducer, err := producer.NewShipEventProducer(*cfg)
+	shipEventProducer, err := producer.NewShipEventProducer(*cfg, "ship-data-service")
 	if err != nil {
 		panic(fmt.Sprintf("failed to initialise ship event producer: %s", err.Error()))
 	}
 	service := shipsrv.New(repo, shipEventProducer)
 
-	consumer, err := consumer.NewShipDataConsumer(*cfg, service, searchService, metricsClient)
+	// initialise the aids to navigation and base station service
+	stationService := stationsrv.New(repo, searchService)
+
+	// initialise the safety alert service
+	safetyService := safetysrv.New(repo)
+
+	// messages the consumers can't process are routed to the dead-letter topic (this is closed after the consumers)
+	deadLetterProducer, err := producer.NewDeadLetterProducer(*cfg)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialise dead-letter producer: %s", err.Error()))
+	}
+	defer deadLetterProducer.Shutdown()
+
+	safetyAlertConsumer, err := consumer.NewSafetyAlertConsumer(*cfg, safetyService, deadLetterProducer, metricsClient)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialise Kafka safety alert consumer: %s", err.Error()))
+	}
+	defer safetyAlertConsumer.Shutdown()
+	go func() {
+		if err := safetyAlertConsumer.Read(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			clog.Errorf("kafka safety alert consumer stopped due to error: %s", err.Error())
+		}
+	}()
+
+	consumer, err := consumer.NewShipDataConsumer(*cfg, service, stationService, searchService, deadLetterProducer,
+		metricsClient)
 	if err != nil {
 		panic(fmt.Sprintf("failed to initialise Kafka consumer: %s", err.Error()))
 	}
@@ -80,7 +107,7 @@ func main() {
 		}
 	}()
 
-	server, err := shipgraph.New(*cfg, service)
+	server, err := shipgraph.New(*cfg, service, stationService, safetyService)
 	if err != nil {
 		panic(fmt.Sprintf("failed to initialise GraphQL server: %s", err.Error()))
 	}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// AidToNavigation is a buoy, beacon, lighthouse or other navigational mark that broadcasts its position over AIS
+// (message type 21)
+type AidToNavigation struct {
+	MMSI int32
+	Name string
+	// AidType is the AIS aid to navigation type code, e.g. 1 = reference point, 20 = north cardinal mark (0 if not
+	// specified)
+	AidType              int32
+	Latitude             float64
+	Longitude            float64
+	DimensionToBow       int32
+	DimensionToStern     int32
+	DimensionToPort      int32
+	DimensionToStarboard int32
+	// VirtualAid is set for aids that are broadcast by a shore station but do not physically exist
+	VirtualAid bool
+	// OffPosition is set when a floating aid has drifted from its assigned position
+	OffPosition bool
+	// LastUpdated is the time the position was observed by the aid's transponder
+	LastUpdated time.Time
+	// ReceivedAt is the time the report was received by the collector
+	ReceivedAt time.Time
+}
+
+// Normalise strips the whitespace and '@' padding that AIS uses for unset characters in the name
+func (a *AidToNavigation) Normalise() {
+	a.Name = trimAISText(a.Name)
+	a.LastUpdated = a.LastUpdated.UTC()
+	a.ReceivedAt = a.ReceivedAt.UTC()
+}
+
+func (a *AidToNavigation) Validate() error {
+	if a.MMSI == 0 {
+		return errors.New("mmsi must be non-zero")
+	}
+
+	if err := validatePosition(a.Latitude, a.Longitude); err != nil {
+		return err
+	}
+
+	if a.DimensionToBow < 0 || a.DimensionToStern < 0 || a.DimensionToPort < 0 || a.DimensionToStarboard < 0 {
+		return errors.New("invalid dimensions")
+	}
+
+	return nil
+}
+
+func validatePosition(latitude, longitude float64) error {
+	if latitude < -90 || latitude > 90 {
+		return errors.New("invalid latitude")
+	}
+
+	if longitude < -180 || longitude > 180 {
+		return errors.New("invalid longitude")
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAidToNavigation_Normalise(t *testing.T) {
+	aid := AidToNavigation{MMSI: 992351000, Name: "  BRAMBLE BANK@@@@ "}
+	aid.Normalise()
+	assert.Equal(t, "BRAMBLE BANK", aid.Name)
+}
+
+func TestAidToNavigation_Validate(t *testing.T) {
+	tt := map[string]struct {
+		aid   AidToNavigation
+		valid bool
+	}{
+		"valid":                {aid: AidToNavigation{MMSI: 992351000, Latitude: 50.79, Longitude: -1.29}, valid: true},
+		"missing mmsi":         {aid: AidToNavigation{Latitude: 50.79, Longitude: -1.29}},
+		"position unavailable": {aid: AidToNavigation{MMSI: 992351000, Latitude: 91, Longitude: 181}},
+		"negative dimensions":  {aid: AidToNavigation{MMSI: 992351000, DimensionToBow: -1}},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			err := tc.aid.Validate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestBoundingBox_Validate(t *testing.T) {
+	assert.NoError(t, BoundingBox{MinLatitude: 50, MinLongitude: -2, MaxLatitude: 51, MaxLongitude: -1}.Validate())
+	assert.Error(t, BoundingBox{MinLatitude: 51, MinLongitude: -2, MaxLatitude: 50, MaxLongitude: -1}.Validate())
+	assert.Error(t, BoundingBox{MinLatitude: -91, MinLongitude: -2, MaxLatitude: 50, MaxLongitude: -1}.Validate())
+}
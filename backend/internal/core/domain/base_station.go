@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// BaseStation is a fixed shore station that broadcasts its position and the time kept by its clock over AIS
+// (message type 4)
+type BaseStation struct {
+	MMSI      int32
+	Latitude  float64
+	Longitude float64
+	// LastUpdated is the time the report was made according to the base station's clock
+	LastUpdated time.Time
+	// ReceivedAt is the time the report was received by the collector
+	ReceivedAt time.Time
+}
+
+func (b *BaseStation) Validate() error {
+	if b.MMSI == 0 {
+		return errors.New("mmsi must be non-zero")
+	}
+
+	return validatePosition(b.Latitude, b.Longitude)
+}
+
+// BaseStationTime converts the UTC date and time reported by a base station into a timestamp. AIS uses a year of 0,
+// month of 0, day of 0, hour of 24 or minute/second of 60 or above to indicate that the time is not available in
+// which case the given fallback is returned.
+func BaseStationTime(year, month, day, hour, minute, second int32, fallback time.Time) time.Time {
+	if year < 1 || month < 1 || month > 12 || day < 1 || day > 31 || hour < 0 || hour > 23 ||
+		minute < 0 || minute > 59 || second < 0 || second > 59 {
+		return fallback.UTC()
+	}
+	return time.Date(int(year), time.Month(month), int(day), int(hour), int(minute), int(second), 0, time.UTC)
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseStationTime(t *testing.T) {
+	fallback := time.Date(2023, time.September, 11, 17, 4, 5, 0, time.UTC)
+
+	tt := map[string]struct {
+		year, month, day, hour, minute, second int32
+		expected                               time.Time
+	}{
+		"valid time":             {2023, 9, 11, 17, 3, 59, time.Date(2023, time.September, 11, 17, 3, 59, 0, time.UTC)},
+		"year not available":     {0, 9, 11, 17, 3, 59, fallback},
+		"month not available":    {2023, 0, 11, 17, 3, 59, fallback},
+		"day not available":      {2023, 9, 0, 17, 3, 59, fallback},
+		"hour not available":     {2023, 9, 11, 24, 3, 59, fallback},
+		"minute not available":   {2023, 9, 11, 17, 60, 59, fallback},
+		"second not available":   {2023, 9, 11, 17, 3, 60, fallback},
+		"all fields unavailable": {0, 0, 0, 24, 60, 60, fallback},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, BaseStationTime(tc.year, tc.month, tc.day, tc.hour, tc.minute, tc.second, fallback))
+		})
+	}
+}
+
+func TestBaseStation_Validate(t *testing.T) {
+	station := BaseStation{MMSI: 2320001, Latitude: 50.8, Longitude: -1.3}
+	assert.NoError(t, station.Validate())
+
+	station.Latitude = 91
+	assert.Error(t, station.Validate())
+}
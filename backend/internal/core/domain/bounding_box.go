@@ -0,0 +1,27 @@
+package domain
+
+import "errors"
+
+// BoundingBox is a geographic area defined by its south-west and north-east corners
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+func (b BoundingBox) Validate() error {
+	if err := validatePosition(b.MinLatitude, b.MinLongitude); err != nil {
+		return err
+	}
+
+	if err := validatePosition(b.MaxLatitude, b.MaxLongitude); err != nil {
+		return err
+	}
+
+	if b.MinLatitude > b.MaxLatitude || b.MinLongitude > b.MaxLongitude {
+		return errors.New("minimum coordinates must not exceed maximum coordinates")
+	}
+
+	return nil
+}
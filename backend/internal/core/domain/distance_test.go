@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	tt := map[string]struct {
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+	}{
+		"same position":             {66.02695, 12.25382, 66.02695, 12.25382, 0},
+		"one minute of latitude":    {50, -1, 50 + 1.0/60, -1, 1853},
+		"across the antimeridian":   {0, 179.99, 0, -179.99, 2224},
+		"southampton to portsmouth": {50.8998, -1.4044, 50.7989, -1.0912, 24617},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, tc.expected, Distance(tc.lat1, tc.lon1, tc.lat2, tc.lon2), tc.expected*0.005+0.001)
+		})
+	}
+}
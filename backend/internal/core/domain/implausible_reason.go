@@ -0,0 +1,12 @@
+package domain
+
+// ImplausibleReason explains why the collector considers a ship's position to be implausible
+type ImplausibleReason string
+
+const (
+	// ImplausibleReasonNullIsland is a position of exactly 0,0, which faulty GPS receivers report when they have no fix
+	ImplausibleReasonNullIsland ImplausibleReason = "null_island"
+	// ImplausibleReasonImpliedSpeed is a position too far from the ship's previous position to have been reached in the
+	// time between them
+	ImplausibleReasonImpliedSpeed ImplausibleReason = "implied_speed"
+)
@@ -0,0 +1,85 @@
+package domain
+
+import "math"
+
+// AIS values used to indicate that a kinematic field is not available
+const (
+	speedOverGroundNotAvailable    = 102.3
+	courseOverGroundNotAvailable   = 360
+	trueHeadingNotAvailable        = 511
+	navigationalStatusNotDefined   = 15
+	rateOfTurnNotAvailable         = -128
+	rateOfTurnNoTurnIndicatorRight = 127
+	rateOfTurnNoTurnIndicatorLeft  = -127
+)
+
+// rateOfTurnNoTurnIndicator is the rate of turn, in degrees per minute, that a transponder without a turn indicator is
+// known to exceed when it reports that the ship is turning (more than 5 degrees per 30 seconds)
+const rateOfTurnNoTurnIndicator = 10.0
+
+// Kinematics holds the motion data reported alongside a ship's position. Fields are nil where the transponder
+// reported the AIS "not available" value.
+type Kinematics struct {
+	// SpeedOverGround is the speed in knots
+	SpeedOverGround *float64
+	// CourseOverGround is the course in degrees relative to true north
+	CourseOverGround *float64
+	// TrueHeading is the heading in degrees relative to true north
+	TrueHeading *int32
+	// NavigationalStatus is the AIS navigational status code (e.g. 0 = under way using engine, 1 = at anchor)
+	NavigationalStatus *int32
+	// RateOfTurn is the rate of turn in degrees per minute (positive when turning to starboard)
+	RateOfTurn *float64
+	// RateOfTurnNoTurnIndicator is set when the transponder has no turn indicator and only reported the direction of a
+	// turn, in which case RateOfTurn holds the ±10 degrees per minute that the actual rate is known to exceed
+	RateOfTurnNoTurnIndicator bool
+}
+
+// NewKinematics creates kinematics from the raw values of an AIS position report, mapping "not available"
+// sentinel values (and values outside of their valid range) to nil
+func NewKinematics(sog, cog float64, trueHeading, navigationalStatus, rateOfTurn int32) Kinematics {
+	var k Kinematics
+	if sog >= 0 && sog < speedOverGroundNotAvailable {
+		k.SpeedOverGround = &sog
+	}
+	if cog >= 0 && cog < courseOverGroundNotAvailable {
+		k.CourseOverGround = &cog
+	}
+	if trueHeading >= 0 && trueHeading < 360 && trueHeading != trueHeadingNotAvailable {
+		k.TrueHeading = &trueHeading
+	}
+	if navigationalStatus >= 0 && navigationalStatus < navigationalStatusNotDefined {
+		k.NavigationalStatus = &navigationalStatus
+	}
+	k.RateOfTurn, k.RateOfTurnNoTurnIndicator = decodeRateOfTurn(rateOfTurn)
+	return k
+}
+
+// NewClassBKinematics creates kinematics from the raw values of a Class B position report, which carries no
+// navigational status or rate of turn
+func NewClassBKinematics(sog, cog float64, trueHeading int32) Kinematics {
+	return NewKinematics(sog, cog, trueHeading, navigationalStatusNotDefined, rateOfTurnNotAvailable)
+}
+
+// decodeRateOfTurn converts the AIS rate of turn indicator (ROT_AIS = 4.733 * sqrt(ROT_sensor)) into degrees per
+// minute. Nil is returned when the rate is not available (or the indicator is out of range). The ±127 values sent by
+// transponders without a turn indicator are returned as a saturated ±10 degrees per minute, flagged as such.
+func decodeRateOfTurn(raw int32) (rot *float64, noTurnIndicator bool) {
+	switch {
+	case raw <= rateOfTurnNotAvailable || raw > rateOfTurnNoTurnIndicatorRight:
+		return nil, false
+	case raw == rateOfTurnNoTurnIndicatorRight:
+		v := rateOfTurnNoTurnIndicator
+		return &v, true
+	case raw == rateOfTurnNoTurnIndicatorLeft:
+		v := -rateOfTurnNoTurnIndicator
+		return &v, true
+	}
+	v := math.Pow(float64(raw)/4.733, 2)
+	if raw < 0 {
+		v = -v
+	}
+	// round to one decimal place given the precision of the underlying indicator
+	v = math.Round(v*10) / 10
+	return &v, false
+}
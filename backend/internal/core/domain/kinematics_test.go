@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKinematics(t *testing.T) {
+	k := NewKinematics(12.3, 308, 235, 0, 4)
+
+	require.NotNil(t, k.SpeedOverGround)
+	assert.Equal(t, 12.3, *k.SpeedOverGround)
+	require.NotNil(t, k.CourseOverGround)
+	assert.Equal(t, 308.0, *k.CourseOverGround)
+	require.NotNil(t, k.TrueHeading)
+	assert.Equal(t, int32(235), *k.TrueHeading)
+	require.NotNil(t, k.NavigationalStatus)
+	assert.Equal(t, int32(0), *k.NavigationalStatus)
+	require.NotNil(t, k.RateOfTurn)
+	assert.Equal(t, 0.7, *k.RateOfTurn)
+}
+
+func TestNewKinematics_MapsNotAvailableValuesToNil(t *testing.T) {
+	k := NewKinematics(102.3, 360, 511, 15, -128)
+
+	assert.Nil(t, k.SpeedOverGround)
+	assert.Nil(t, k.CourseOverGround)
+	assert.Nil(t, k.TrueHeading)
+	assert.Nil(t, k.NavigationalStatus)
+	assert.Nil(t, k.RateOfTurn)
+}
+
+func TestNewKinematics_RateOfTurn(t *testing.T) {
+	tt := map[string]struct {
+		raw                     int32
+		expected                *float64
+		expectedNoTurnIndicator bool
+	}{
+		"not turning":                           {raw: 0, expected: ptr(0.0)},
+		"turning to starboard":                  {raw: 20, expected: ptr(17.9)},
+		"turning to port":                       {raw: -20, expected: ptr(-17.9)},
+		"maximum indicated rate (to starboard)": {raw: 126, expected: ptr(708.7)},
+		"maximum indicated rate (to port)":      {raw: -126, expected: ptr(-708.7)},
+		"no turn indicator (to starboard)":      {raw: 127, expected: ptr(10.0), expectedNoTurnIndicator: true},
+		"no turn indicator (to port)":           {raw: -127, expected: ptr(-10.0), expectedNoTurnIndicator: true},
+		"not available":                         {raw: -128, expected: nil},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			k := NewKinematics(0, 0, 0, 0, tc.raw)
+			assert.Equal(t, tc.expected, k.RateOfTurn)
+			assert.Equal(t, tc.expectedNoTurnIndicator, k.RateOfTurnNoTurnIndicator)
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestNewClassBKinematics(t *testing.T) {
+	k := NewClassBKinematics(5.5, 90, 511)
+
+	require.NotNil(t, k.SpeedOverGround)
+	assert.Equal(t, 5.5, *k.SpeedOverGround)
+	require.NotNil(t, k.CourseOverGround)
+	assert.Equal(t, 90.0, *k.CourseOverGround)
+	assert.Nil(t, k.TrueHeading)
+	assert.Nil(t, k.NavigationalStatus)
+	assert.Nil(t, k.RateOfTurn)
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StationType is the kind of AIS station identified by an MMSI, as allocated by ITU-R M.585
+type StationType string
+
+const (
+	StationTypeShip            StationType = "ship"
+	StationTypeGroup           StationType = "group"
+	StationTypeCoastStation    StationType = "coast_station"
+	StationTypeSARAircraft     StationType = "sar_aircraft"
+	StationTypeAidToNavigation StationType = "aid_to_navigation"
+	// StationTypeAuxiliaryCraft is a craft associated with a parent ship, e.g. a tender or lifeboat
+	StationTypeAuxiliaryCraft StationType = "auxiliary_craft"
+	StationTypeHandheld       StationType = "handheld"
+	StationTypeSART           StationType = "sart"
+	StationTypeMOB            StationType = "mob"
+	StationTypeEPIRB          StationType = "epirb"
+	// StationTypeUnknown is a 9 digit MMSI that does not follow any of the allocated formats
+	StationTypeUnknown StationType = "unknown"
+)
+
+// StationTypes lists every station type in the order they are documented
+var StationTypes = []StationType{StationTypeShip, StationTypeGroup, StationTypeCoastStation, StationTypeSARAircraft,
+	StationTypeAidToNavigation, StationTypeAuxiliaryCraft, StationTypeHandheld, StationTypeSART, StationTypeMOB,
+	StationTypeEPIRB, StationTypeUnknown}
+
+// MMSIInfo is the information encoded in an MMSI
+type MMSIInfo struct {
+	StationType StationType
+	// MID is the Maritime Identification Digits (0 if the station type does not have any)
+	MID int32
+	// Flag is the ISO 3166-1 alpha-2 code of the country the MID is allocated to (empty if not known)
+	Flag string
+}
+
+//go:embed mid.csv
+var midCSV string
+
+// flags maps each MID to the ISO 3166-1 alpha-2 code of the country it is allocated to. Territories with their own
+// MID and country code (e.g. Gibraltar) map to the territory rather than the administering country.
+var flags = mustLoadFlags(midCSV)
+
+func mustLoadFlags(table string) map[int32]string {
+	records, err := csv.NewReader(strings.NewReader(table)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("failed to read MID table: %v", err))
+	}
+	flags := make(map[int32]string, len(records))
+	// skip the header
+	for _, record := range records[1:] {
+		mid, err := strconv.ParseInt(record[0], 10, 32)
+		if err != nil {
+			panic(fmt.Sprintf("invalid MID '%s' in MID table: %v", record[0], err))
+		}
+		flags[int32(mid)] = record[1]
+	}
+	return flags
+}
+
+// IsDistressBeacon reports whether the station is a device that is only activated in an emergency
+func (t StationType) IsDistressBeacon() bool {
+	return t == StationTypeSART || t == StationTypeMOB || t == StationTypeEPIRB
+}
+
+// ClassifyMMSI decodes the station type and flag from an MMSI. MMSIs have 9 digits, with leading zeros (as used by
+// group and coast station MMSIs) being lost when stored as an integer.
+func ClassifyMMSI(mmsi int32) (MMSIInfo, error) {
+	if mmsi <= 0 || mmsi > 999999999 {
+		return MMSIInfo{}, errors.New("mmsi must have 9 digits")
+	}
+
+	digits := fmt.Sprintf("%09d", mmsi)
+	var info MMSIInfo
+	var mid string
+	switch {
+	case strings.HasPrefix(digits, "00"):
+		info.StationType, mid = StationTypeCoastStation, digits[2:5]
+	case strings.HasPrefix(digits, "0"):
+		info.StationType, mid = StationTypeGroup, digits[1:4]
+	case strings.HasPrefix(digits, "111"):
+		info.StationType, mid = StationTypeSARAircraft, digits[3:6]
+	case digits[0] >= '2' && digits[0] <= '7':
+		info.StationType, mid = StationTypeShip, digits[0:3]
+	case strings.HasPrefix(digits, "8"):
+		info.StationType, mid = StationTypeHandheld, digits[1:4]
+	case strings.HasPrefix(digits, "970"):
+		info.StationType = StationTypeSART
+	case strings.HasPrefix(digits, "972"):
+		info.StationType = StationTypeMOB
+	case strings.HasPrefix(digits, "974"):
+		info.StationType = StationTypeEPIRB
+	case strings.HasPrefix(digits, "98"):
+		info.StationType, mid = StationTypeAuxiliaryCraft, digits[2:5]
+	case strings.HasPrefix(digits, "99"):
+		info.StationType, mid = StationTypeAidToNavigation, digits[2:5]
+	default:
+		info.StationType = StationTypeUnknown
+	}
+
+	if mid != "" {
+		// the digits are known to be numeric
+		m, _ := strconv.ParseInt(mid, 10, 32)
+		info.MID = int32(m)
+		info.Flag = flags[info.MID]
+	}
+	return info, nil
+}
+
+// ParseStationType converts the name of a station type into a StationType
+func ParseStationType(name string) (StationType, error) {
+	stationType := StationType(strings.ToLower(strings.TrimSpace(name)))
+	for _, t := range StationTypes {
+		if t == stationType {
+			return stationType, nil
+		}
+	}
+	return "", fmt.Errorf("unknown station type '%s'", name)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyMMSI(t *testing.T) {
+	tests := []struct {
+		name string
+		mmsi int32
+		want MMSIInfo
+	}{
+		{"ship", 235000000, MMSIInfo{StationType: StationTypeShip, MID: 235, Flag: "GB"}},
+		{"ship with unallocated MID", 299000000, MMSIInfo{StationType: StationTypeShip, MID: 299}},
+		{"group", 23100000, MMSIInfo{StationType: StationTypeGroup, MID: 231, Flag: "FO"}},
+		{"coast station", 2320001, MMSIInfo{StationType: StationTypeCoastStation, MID: 232, Flag: "GB"}},
+		{"SAR aircraft", 111232506, MMSIInfo{StationType: StationTypeSARAircraft, MID: 232, Flag: "GB"}},
+		{"handheld", 823512345, MMSIInfo{StationType: StationTypeHandheld, MID: 235, Flag: "GB"}},
+		{"AIS-SART", 970012345, MMSIInfo{StationType: StationTypeSART}},
+		{"MOB", 972012345, MMSIInfo{StationType: StationTypeMOB}},
+		{"EPIRB", 974012345, MMSIInfo{StationType: StationTypeEPIRB}},
+		{"auxiliary craft", 982351234, MMSIInfo{StationType: StationTypeAuxiliaryCraft, MID: 235, Flag: "GB"}},
+		{"aid to navigation", 992351000, MMSIInfo{StationType: StationTypeAidToNavigation, MID: 235, Flag: "GB"}},
+		{"unknown", 150000000, MMSIInfo{StationType: StationTypeUnknown}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ClassifyMMSI(tt.mmsi)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, info)
+		})
+	}
+}
+
+func TestClassifyMMSI_RejectsInvalidMMSI(t *testing.T) {
+	for _, mmsi := range []int32{0, -235000000, 1000000000} {
+		_, err := ClassifyMMSI(mmsi)
+		assert.Error(t, err, mmsi)
+	}
+}
+
+func TestParseStationType(t *testing.T) {
+	stationType, err := ParseStationType(" Coast_Station ")
+	require.NoError(t, err)
+	assert.Equal(t, StationTypeCoastStation, stationType)
+
+	_, err = ParseStationType("lighthouse")
+	assert.Error(t, err)
+}
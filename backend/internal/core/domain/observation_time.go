@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// ObservationTime derives the time a position was observed by a transponder from the time the report was received
+// and the UTC second (0-59) that AIS position reports carry. Reports are assumed to have been made within the
+// minute before being received. Values of 60 and above indicate that the time stamp is not available in which case
+// the time the report was received is used.
+func ObservationTime(receivedAt time.Time, utcSecond int32) time.Time {
+	receivedAt = receivedAt.UTC()
+	if utcSecond < 0 || utcSecond > 59 {
+		return receivedAt
+	}
+
+	observedAt := receivedAt.Truncate(time.Minute).Add(time.Duration(utcSecond) * time.Second)
+	if observedAt.After(receivedAt) {
+		// the report was made in the previous minute
+		observedAt = observedAt.Add(-time.Minute)
+	}
+	return observedAt
+}
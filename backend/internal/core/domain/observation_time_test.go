@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObservationTime(t *testing.T) {
+	receivedAt := time.Date(2022, time.December, 29, 18, 22, 32, 318353000, time.UTC)
+
+	tt := map[string]struct {
+		utcSecond int32
+		expected  time.Time
+	}{
+		"same minute":              {utcSecond: 31, expected: time.Date(2022, time.December, 29, 18, 22, 31, 0, time.UTC)},
+		"previous minute":          {utcSecond: 59, expected: time.Date(2022, time.December, 29, 18, 21, 59, 0, time.UTC)},
+		"time stamp not available": {utcSecond: 60, expected: receivedAt},
+		"manual input mode":        {utcSecond: 61, expected: receivedAt},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ObservationTime(receivedAt, tc.utcSecond))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SafetyAlertKind is the kind of transmission that raised a safety alert
+type SafetyAlertKind string
+
+const (
+	// SafetyAlertKindBroadcast is a safety related broadcast message (message type 14)
+	SafetyAlertKindBroadcast SafetyAlertKind = "broadcast"
+	// SafetyAlertKindAddressed is a safety related message addressed to a single station (message type 12)
+	SafetyAlertKindAddressed SafetyAlertKind = "addressed"
+	// SafetyAlertKindBeacon is a position report from an AIS-SART, MOB or EPIRB-AIS device
+	SafetyAlertKindBeacon SafetyAlertKind = "beacon"
+)
+
+// SafetyAlert is a safety related transmission, such as a man overboard beacon being activated
+type SafetyAlert struct {
+	MMSI int32
+	Kind SafetyAlertKind
+	// DestinationMMSI is 0 unless the alert was addressed to a station
+	DestinationMMSI int32
+	// Text is empty for beacon position reports
+	Text string
+	// Latitude and Longitude are nil unless the alert was raised by a beacon's position report
+	Latitude  *float64
+	Longitude *float64
+	// TransmittedAt is the time the alert was transmitted. Safety messages do not carry a timestamp so this is the
+	// time they were received by the AIS receiver.
+	TransmittedAt time.Time
+	// ReceivedAt is the time the alert was received by the collector
+	ReceivedAt time.Time
+}
+
+// Normalise tidies up the padding used by AIS text fields and converts the timestamps to UTC
+func (a *SafetyAlert) Normalise() {
+	a.Text = trimAISText(a.Text)
+	a.TransmittedAt = a.TransmittedAt.UTC()
+	a.ReceivedAt = a.ReceivedAt.UTC()
+}
+
+func (a *SafetyAlert) Validate() error {
+	if a.MMSI == 0 {
+		return errors.New("mmsi must be non-zero")
+	}
+
+	switch a.Kind {
+	case SafetyAlertKindBroadcast:
+		return nil
+	case SafetyAlertKindAddressed:
+		if a.DestinationMMSI == 0 {
+			return errors.New("destination mmsi must be non-zero for addressed alerts")
+		}
+		return nil
+	case SafetyAlertKindBeacon:
+		if a.Latitude == nil || a.Longitude == nil {
+			return errors.New("position must be set for beacon alerts")
+		}
+		return validatePosition(*a.Latitude, *a.Longitude)
+	default:
+		return fmt.Errorf("unknown safety alert kind '%s'", a.Kind)
+	}
+}
+
+// NewBeaconAlert creates the alert raised by a position report from an AIS-SART, MOB or EPIRB-AIS device
+func NewBeaconAlert(ship Ship) SafetyAlert {
+	latitude, longitude := ship.Latitude, ship.Longitude
+	return SafetyAlert{
+		MMSI:          ship.MMSI,
+		Kind:          SafetyAlertKindBeacon,
+		Latitude:      &latitude,
+		Longitude:     &longitude,
+		TransmittedAt: ship.LastUpdated,
+		ReceivedAt:    ship.ReceivedAt,
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafetyAlert_Validate(t *testing.T) {
+	latitude, longitude, invalidLatitude := 50.8, -1.3, 91.0
+
+	tt := map[string]struct {
+		alert SafetyAlert
+		valid bool
+	}{
+		"broadcast":                    {SafetyAlert{MMSI: 972123456, Kind: SafetyAlertKindBroadcast, Text: "MOB ACTIVE"}, true},
+		"broadcast without mmsi":       {SafetyAlert{Kind: SafetyAlertKindBroadcast}, false},
+		"addressed":                    {SafetyAlert{MMSI: 235000000, Kind: SafetyAlertKindAddressed, DestinationMMSI: 2320001}, true},
+		"addressed without dest":       {SafetyAlert{MMSI: 235000000, Kind: SafetyAlertKindAddressed}, false},
+		"beacon":                       {SafetyAlert{MMSI: 970123456, Kind: SafetyAlertKindBeacon, Latitude: &latitude, Longitude: &longitude}, true},
+		"beacon without position":      {SafetyAlert{MMSI: 970123456, Kind: SafetyAlertKindBeacon}, false},
+		"beacon with invalid latitude": {SafetyAlert{MMSI: 970123456, Kind: SafetyAlertKindBeacon, Latitude: &invalidLatitude, Longitude: &longitude}, false},
+		"unknown kind":                 {SafetyAlert{MMSI: 235000000, Kind: "distress"}, false},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			if tc.valid {
+				assert.NoError(t, tc.alert.Validate())
+			} else {
+				assert.Error(t, tc.alert.Validate())
+			}
+		})
+	}
+}
+
+func TestNewBeaconAlert(t *testing.T) {
+	observedAt := time.Date(2023, time.September, 11, 17, 4, 30, 0, time.UTC)
+	ship := NewShip(972123456, "", 50.8, -1.3, observedAt)
+
+	alert := NewBeaconAlert(*ship)
+	assert.Equal(t, SafetyAlertKindBeacon, alert.Kind)
+	assert.Equal(t, int32(972123456), alert.MMSI)
+	assert.Equal(t, 50.8, *alert.Latitude)
+	assert.Equal(t, -1.3, *alert.Longitude)
+	assert.Equal(t, observedAt, alert.TransmittedAt)
+	assert.NoError(t, alert.Validate())
+}
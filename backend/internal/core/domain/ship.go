@@ -7,11 +7,21 @@ import (
 )
 
 type Ship struct {
-	MMSI        int32
-	Name        string
-	Latitude    float64
-	Longitude   float64
+	MMSI      int32
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Kinematics
+	// TransponderClass is empty if the class of transponder is not known
+	TransponderClass TransponderClass
+	// ImplausibleReason is empty unless the collector has flagged the position as implausible
+	ImplausibleReason ImplausibleReason
+	// LastUpdated is the time the position was observed by the ship's transponder
 	LastUpdated time.Time
+	// ReceivedAt is the time the position report was received by the collector
+	ReceivedAt time.Time
+	// StaticData is nil if no static data has been received for the ship
+	StaticData *ShipStaticData
 }
 
 func NewShip(mmsi int32, name string, latitude, longitude float64, lastUpdated time.Time) *Ship {
@@ -30,13 +40,5 @@ func (s *Ship) Validate() error {
 		return errors.New("mmsi must be non-zero")
 	}
 
-	if s.Latitude < -90 || s.Latitude > 90 {
-		return errors.New("invalid latitude")
-	}
-
-	if s.Longitude < -180 || s.Longitude > 180 {
-		return errors.New("invalid longitude")
-	}
-
-	return nil
+	return validatePosition(s.Latitude, s.Longitude)
 }
@@ -3,6 +3,25 @@ package domain
 type ShipSearchResult struct {
 	MMSI int32
 	Name string
+	// StationType is set for results that are known not to be ships (e.g. aids to navigation), otherwise the station
+	// type is classified from the MMSI
+	StationType StationType
+}
+
+// ShipSearchFilter narrows a search to the ships matching each of its non-empty fields
+type ShipSearchFilter struct {
+	// Flag is an ISO 3166-1 alpha-2 country code
+	Flag        string
+	StationType StationType
+}
+
+// ExcludedStationTypes returns the station types left out of the search results. Aids to navigation and coast stations
+// are indexed alongside ships but aren't returned unless the filter asks for them, as they can't be looked up as ships.
+func (f ShipSearchFilter) ExcludedStationTypes() []StationType {
+	if f.StationType != "" {
+		return nil
+	}
+	return []StationType{StationTypeAidToNavigation, StationTypeCoastStation}
 }
 
 func NewShipSearchResult(mmsi int32, name string) ShipSearchResult {
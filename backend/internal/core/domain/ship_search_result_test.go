@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShipSearchFilter_ExcludedStationTypes(t *testing.T) {
+	assert.ElementsMatch(t, []StationType{StationTypeAidToNavigation, StationTypeCoastStation},
+		ShipSearchFilter{Flag: "GB"}.ExcludedStationTypes())
+	assert.Empty(t, ShipSearchFilter{StationType: StationTypeAidToNavigation}.ExcludedStationTypes())
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ShipStaticData holds the static and voyage related data broadcast by a ship (AIS message type 5)
+type ShipStaticData struct {
+	MMSI                 int32
+	Name                 string
+	IMONumber            int32
+	CallSign             string
+	ShipType             int32
+	DimensionToBow       int32
+	DimensionToStern     int32
+	DimensionToPort      int32
+	DimensionToStarboard int32
+	Draught              float64
+	Destination          string
+	ETA                  *time.Time
+	LastUpdated          time.Time
+}
+
+// Normalise strips the whitespace and '@' padding that AIS uses for unset characters in text fields
+func (d *ShipStaticData) Normalise() {
+	d.Name = trimAISText(d.Name)
+	d.CallSign = trimAISText(d.CallSign)
+	d.Destination = trimAISText(d.Destination)
+	d.LastUpdated = d.LastUpdated.UTC()
+}
+
+func (d *ShipStaticData) Validate() error {
+	if d.MMSI == 0 {
+		return errors.New("mmsi must be non-zero")
+	}
+
+	if d.Draught < 0 {
+		return errors.New("invalid draught")
+	}
+
+	if d.DimensionToBow < 0 || d.DimensionToStern < 0 || d.DimensionToPort < 0 || d.DimensionToStarboard < 0 {
+		return errors.New("invalid dimensions")
+	}
+
+	return nil
+}
+
+// Length returns the overall length of the ship in metres
+func (d *ShipStaticData) Length() int32 {
+	return d.DimensionToBow + d.DimensionToStern
+}
+
+// Beam returns the overall width of the ship in metres
+func (d *ShipStaticData) Beam() int32 {
+	return d.DimensionToPort + d.DimensionToStarboard
+}
+
+// NewETA converts the month, day, hour and minute reported by AIS into a full timestamp. AIS does not include the
+// year so the ETA is assumed to be the occurrence of that date within six months either side of when it was reported,
+// which keeps ETAs that have recently passed (i.e. ships that haven't updated their voyage data since arriving) in the
+// past rather than moving them to the following year. Nil is returned if the ETA is not available.
+func NewETA(month, day, hour, minute int32, reportedAt time.Time) *time.Time {
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return nil
+	}
+
+	reportedAt = reportedAt.UTC()
+	eta := time.Date(reportedAt.Year(), time.Month(month), int(day), int(hour), int(minute), 0, 0, time.UTC)
+	if eta.Before(reportedAt.AddDate(0, -6, 0)) {
+		eta = eta.AddDate(1, 0, 0)
+	} else if !eta.Before(reportedAt.AddDate(0, 6, 0)) {
+		eta = eta.AddDate(-1, 0, 0)
+	}
+	return &eta
+}
+
+func trimAISText(s string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(s), "@"))
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShipStaticData_Normalise(t *testing.T) {
+	d := ShipStaticData{
+		MMSI:        259000420,
+		Name:        "AUGUSTSON@@@@@@@",
+		CallSign:    " LAGV@@",
+		Destination: "BODO   @@@@",
+	}
+	d.Normalise()
+
+	assert.Equal(t, "AUGUSTSON", d.Name)
+	assert.Equal(t, "LAGV", d.CallSign)
+	assert.Equal(t, "BODO", d.Destination)
+}
+
+func TestShipStaticData_Dimensions(t *testing.T) {
+	d := ShipStaticData{DimensionToBow: 40, DimensionToStern: 10, DimensionToPort: 5, DimensionToStarboard: 6}
+	assert.Equal(t, int32(50), d.Length())
+	assert.Equal(t, int32(11), d.Beam())
+}
+
+func TestNewETA(t *testing.T) {
+	reportedAt := time.Date(2023, time.December, 20, 12, 0, 0, 0, time.UTC)
+
+	eta := NewETA(12, 24, 18, 30, reportedAt)
+	require.NotNil(t, eta)
+	assert.Equal(t, time.Date(2023, time.December, 24, 18, 30, 0, 0, time.UTC), *eta)
+
+	// ETAs early in the year are assumed to be for the following year
+	eta = NewETA(1, 3, 6, 0, reportedAt)
+	require.NotNil(t, eta)
+	assert.Equal(t, time.Date(2024, time.January, 3, 6, 0, 0, 0, time.UTC), *eta)
+
+	// recently passed ETAs are kept in the current year
+	eta = NewETA(12, 1, 6, 0, reportedAt)
+	require.NotNil(t, eta)
+	assert.Equal(t, time.Date(2023, time.December, 1, 6, 0, 0, 0, time.UTC), *eta)
+
+	// an ETA just before the report time is kept rather than moved to the following year
+	eta = NewETA(12, 20, 11, 59, reportedAt)
+	require.NotNil(t, eta)
+	assert.Equal(t, time.Date(2023, time.December, 20, 11, 59, 0, 0, time.UTC), *eta)
+
+	// recently passed ETAs late in the year are kept in the previous year
+	eta = NewETA(12, 28, 6, 0, time.Date(2024, time.January, 2, 12, 0, 0, 0, time.UTC))
+	require.NotNil(t, eta)
+	assert.Equal(t, time.Date(2023, time.December, 28, 6, 0, 0, 0, time.UTC), *eta)
+}
+
+func TestNewETA_NotAvailable(t *testing.T) {
+	reportedAt := time.Date(2023, time.December, 20, 12, 0, 0, 0, time.UTC)
+	assert.Nil(t, NewETA(0, 0, 24, 60, reportedAt))
+	assert.Nil(t, NewETA(5, 0, 12, 0, reportedAt))
+	assert.Nil(t, NewETA(5, 4, 24, 0, reportedAt))
+}
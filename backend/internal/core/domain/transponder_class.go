@@ -0,0 +1,11 @@
+package domain
+
+// TransponderClass is the class of AIS transponder that reported a ship's position. Class A transponders are
+// mandatory for larger commercial vessels whereas Class B transponders are typically fitted to leisure craft and
+// small fishing vessels.
+type TransponderClass string
+
+const (
+	TransponderClassA TransponderClass = "A"
+	TransponderClassB TransponderClass = "B"
+)
@@ -8,4 +8,17 @@ import (
 
 type Producer interface {
 	Write(context.Context, domain.Ship) error
+	WriteStaticData(context.Context, domain.ShipStaticData) error
+	WriteAidToNavigation(context.Context, domain.AidToNavigation) error
+	WriteBaseStation(context.Context, domain.BaseStation) error
+	WriteSafetyAlert(context.Context, domain.SafetyAlert) error
+}
+
+// Source is a feed of AIS data (e.g. aisstream.io or a local AIS receiver) that passes the reports it receives to a
+// CollectorService
+type Source interface {
+	// Name identifies the source in logs
+	Name() string
+	// Listen receives data until the context is cancelled or an unrecoverable error occurs
+	Listen(ctx context.Context) error
 }
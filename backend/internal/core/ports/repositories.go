@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
 )
@@ -9,9 +10,23 @@ import (
 type ShipRepository interface {
 	Get(ctx context.Context, mmsi int32) (domain.Ship, error)
 	Store(ctx context.Context, ships []domain.Ship) error
+	StoreStaticData(ctx context.Context, data []domain.ShipStaticData) error
+}
+
+type StationRepository interface {
+	ListAidsToNavigation(ctx context.Context, bbox domain.BoundingBox) ([]domain.AidToNavigation, error)
+	ListBaseStations(ctx context.Context) ([]domain.BaseStation, error)
+	StoreAidsToNavigation(ctx context.Context, aids []domain.AidToNavigation) error
+	StoreBaseStations(ctx context.Context, stations []domain.BaseStation) error
+}
+
+type SafetyAlertRepository interface {
+	ListSafetyAlerts(ctx context.Context, since time.Time, limit int) ([]domain.SafetyAlert, error)
+	// StoreSafetyAlerts stores the alerts, ignoring any that have already been stored, and returns those that were new
+	StoreSafetyAlerts(ctx context.Context, alerts []domain.SafetyAlert) ([]domain.SafetyAlert, error)
 }
 
 type ShipSearchRepository interface {
-	Search(ctx context.Context, query string) ([]domain.ShipSearchResult, error)
+	Search(ctx context.Context, query string, filter domain.ShipSearchFilter) ([]domain.ShipSearchResult, error)
 	Index(ctx context.Context, ships []domain.ShipSearchResult) error
 }
@@ -2,20 +2,46 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
 )
 
 type CollectorService interface {
-	Process(mmsi int32, shipName string, latitude, longitude float64) error
+	Process(ctx context.Context, ship domain.Ship) error
+	ProcessStaticData(ctx context.Context, data domain.ShipStaticData) error
+	ProcessAidToNavigation(ctx context.Context, aid domain.AidToNavigation) error
+	ProcessBaseStation(ctx context.Context, station domain.BaseStation) error
+	ProcessSafetyAlert(ctx context.Context, alert domain.SafetyAlert) error
 }
 
 type ShipService interface {
 	Get(ctx context.Context, mmsi int32) (domain.Ship, error)
 	Store(ctx context.Context, ships []domain.Ship) error
+	StoreStaticData(ctx context.Context, data []domain.ShipStaticData) error
 }
 
 type ShipSearchService interface {
-	Search(ctx context.Context, searchTerm string) ([]domain.ShipSearchResult, error)
+	Search(ctx context.Context, searchTerm string, filter domain.ShipSearchFilter) ([]domain.ShipSearchResult, error)
 	Store(ctx context.Context, ships []domain.ShipSearchResult) error
 }
+
+// SafetyAlertService stores safety alerts and notifies subscribers of them as they are received. Alerts are stored
+// once, by whichever instance of the service reads them first, but every instance notifies its own subscribers.
+type SafetyAlertService interface {
+	// ListSafetyAlerts returns up to limit alerts transmitted since the given time, most recent first
+	ListSafetyAlerts(ctx context.Context, since time.Time, limit int) ([]domain.SafetyAlert, error)
+	StoreSafetyAlerts(ctx context.Context, alerts []domain.SafetyAlert) error
+	// NotifySafetyAlerts passes the alerts to the subscribers of this instance
+	NotifySafetyAlerts(alerts []domain.SafetyAlert)
+	// SubscribeSafetyAlerts returns a channel of alerts received from now on, which is closed once the context is done
+	SubscribeSafetyAlerts(ctx context.Context) <-chan domain.SafetyAlert
+}
+
+// StationService manages the fixed stations (aids to navigation and base stations) that broadcast over AIS
+type StationService interface {
+	ListAidsToNavigation(ctx context.Context, bbox domain.BoundingBox) ([]domain.AidToNavigation, error)
+	ListBaseStations(ctx context.Context) ([]domain.BaseStation, error)
+	StoreAidsToNavigation(ctx context.Context, aids []domain.AidToNavigation) error
+	StoreBaseStations(ctx context.Context, stations []domain.BaseStation) error
+}
@@ -0,0 +1,76 @@
+package collectorsrv
+
+import (
+	"container/list"
+	"time"
+)
+
+// mmsiCache holds a value per ship. Ships are forgotten once their value has not been put within the TTL or, if the
+// cache is full, in least recently put order. It is not safe for concurrent use.
+type mmsiCache[V any] struct {
+	ttl      time.Duration
+	capacity int
+	// order holds the *cacheEntry values from least to most recently put
+	order   *list.List
+	entries map[int32]*list.Element
+	now     func() time.Time
+}
+
+type cacheEntry[V any] struct {
+	mmsi      int32
+	value     V
+	expiresAt time.Time
+}
+
+func newMMSICache[V any](ttl time.Duration, capacity int) *mmsiCache[V] {
+	return &mmsiCache[V]{
+		ttl:      ttl,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[int32]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func (c *mmsiCache[V]) get(mmsi int32) (V, bool) {
+	c.expire()
+	e, ok := c.entries[mmsi]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return e.Value.(*cacheEntry[V]).value, true
+}
+
+func (c *mmsiCache[V]) put(mmsi int32, value V) {
+	c.expire()
+	expiresAt := c.now().Add(c.ttl)
+	if e, ok := c.entries[mmsi]; ok {
+		entry := e.Value.(*cacheEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToBack(e)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		c.remove(c.order.Front())
+	}
+	c.entries[mmsi] = c.order.PushBack(&cacheEntry[V]{mmsi: mmsi, value: value, expiresAt: expiresAt})
+}
+
+func (c *mmsiCache[V]) len() int {
+	return c.order.Len()
+}
+
+func (c *mmsiCache[V]) expire() {
+	now := c.now()
+	for e := c.order.Front(); e != nil && !now.Before(e.Value.(*cacheEntry[V]).expiresAt); e = c.order.Front() {
+		c.remove(e)
+	}
+}
+
+func (c *mmsiCache[V]) remove(e *list.Element) {
+	delete(c.entries, e.Value.(*cacheEntry[V]).mmsi)
+	c.order.Remove(e)
+}
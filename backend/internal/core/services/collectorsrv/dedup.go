@@ -0,0 +1,65 @@
+package collectorsrv
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+)
+
+// SuppressReason is recorded in the metrics when a position is suppressed by the dedup cache
+type SuppressReason string
+
+const (
+	// SuppressReasonDuplicate is a position already accepted (e.g. the same report heard by several receivers)
+	SuppressReasonDuplicate SuppressReason = "duplicate"
+	// SuppressReasonOutOfOrder is a position observed before the last accepted position for the ship
+	SuppressReasonOutOfOrder SuppressReason = "out_of_order"
+)
+
+// lastObservation holds the last accepted observation for a ship
+type lastObservation struct {
+	observedAt time.Time
+	// positions accepted with the observation time (usually one)
+	positions []coordinates
+}
+
+type coordinates struct {
+	latitude  float64
+	longitude float64
+}
+
+// dedup suppresses duplicate and out-of-order positions. As positions observed before the last accepted one are
+// suppressed, only those sharing its observation time need to be remembered to detect duplicates.
+type dedup struct {
+	mu    sync.Mutex
+	cache *mmsiCache[lastObservation]
+}
+
+func newDedup(ttl time.Duration, capacity int) *dedup {
+	return &dedup{cache: newMMSICache[lastObservation](ttl, capacity)}
+}
+
+// admit reports whether the position should be processed and, if not, why it was suppressed
+func (d *dedup) admit(ship domain.Ship) (bool, SuppressReason) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pos := coordinates{latitude: ship.Latitude, longitude: ship.Longitude}
+	last, ok := d.cache.get(ship.MMSI)
+	switch {
+	case !ok || ship.LastUpdated.After(last.observedAt):
+		last = lastObservation{observedAt: ship.LastUpdated, positions: []coordinates{pos}}
+	case ship.LastUpdated.Before(last.observedAt):
+		return false, SuppressReasonOutOfOrder
+	default:
+		for _, p := range last.positions {
+			if p == pos {
+				return false, SuppressReasonDuplicate
+			}
+		}
+		last.positions = append(last.positions, pos)
+	}
+	d.cache.put(ship.MMSI, last)
+	return true, ""
+}
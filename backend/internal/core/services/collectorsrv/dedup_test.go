@@ -0,0 +1,106 @@
+package collectorsrv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+var observedAt = time.Date(2023, time.September, 11, 17, 4, 30, 0, time.UTC)
+
+func observation(mmsi int32, latitude float64, lastUpdated time.Time) domain.Ship {
+	return *domain.NewShip(mmsi, "", latitude, -1.3, lastUpdated)
+}
+
+func newTestDedup(ttl time.Duration, capacity int) (*dedup, *fakeClock) {
+	clock := &fakeClock{now: observedAt}
+	d := newDedup(ttl, capacity)
+	d.cache.now = clock.Now
+	return d, clock
+}
+
+func TestDedup_Admit(t *testing.T) {
+	d, _ := newTestDedup(time.Minute, 10)
+
+	tests := []struct {
+		name   string
+		ship   domain.Ship
+		ok     bool
+		reason SuppressReason
+	}{
+		{"first position", observation(1, 50.8, observedAt), true, ""},
+		{"same report from another receiver", observation(1, 50.8, observedAt), false, SuppressReasonDuplicate},
+		{"different position at the same time", observation(1, 50.9, observedAt), true, ""},
+		{"other ship", observation(2, 50.8, observedAt), true, ""},
+		{"later position", observation(1, 50.8, observedAt.Add(10*time.Second)), true, ""},
+		{"earlier position", observation(1, 50.9, observedAt.Add(5*time.Second)), false, SuppressReasonOutOfOrder},
+		{"repeat of an earlier position", observation(1, 50.8, observedAt), false, SuppressReasonOutOfOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason := d.admit(tt.ship)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestDedup_ForgetsShipsAfterTTL(t *testing.T) {
+	d, clock := newTestDedup(time.Minute, 10)
+
+	ok, _ := d.admit(observation(1, 50.8, observedAt))
+	require.True(t, ok)
+	clock.now = clock.now.Add(time.Minute)
+
+	ok, _ = d.admit(observation(1, 50.8, observedAt))
+	assert.True(t, ok)
+}
+
+func TestDedup_EvictsLeastRecentlyAcceptedShipWhenFull(t *testing.T) {
+	d, _ := newTestDedup(time.Minute, 2)
+
+	for _, mmsi := range []int32{1, 2, 3} {
+		ok, _ := d.admit(observation(mmsi, 50.8, observedAt))
+		require.True(t, ok)
+	}
+	assert.Equal(t, 2, d.cache.len())
+
+	ok, _ := d.admit(observation(1, 50.8, observedAt))
+	assert.True(t, ok, "evicted ship is accepted again")
+	ok, _ = d.admit(observation(3, 50.8, observedAt))
+	assert.False(t, ok)
+}
+
+func TestService_SuppressesDuplicatePositions(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockProducer := &MockProducer{}
+	metrics := &RecordingMetricsClient{}
+	s, err := New(ctx, *cfg, mockProducer, metrics)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Process(context.Background(), observation(1, 50.8, observedAt)))
+	require.NoError(t, s.Process(context.Background(), observation(1, 50.8, observedAt)))
+	require.NoError(t, s.Process(context.Background(), observation(1, 50.8, observedAt.Add(-time.Second))))
+
+	require.Eventually(t, func() bool {
+		mockProducer.mu.Lock()
+		defer mockProducer.mu.Unlock()
+		return len(mockProducer.queue) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	metrics.mu.Lock()
+	assert.Equal(t, map[string]int{"duplicate": 1, "out_of_order": 1}, metrics.suppressed)
+	metrics.mu.Unlock()
+
+	cancel()
+	s.Shutdown()
+}
@@ -0,0 +1,106 @@
+package collectorsrv
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+)
+
+// PlausibilityMode determines what happens to a position that is found to be implausible
+type PlausibilityMode string
+
+const (
+	// PlausibilityOff disables the plausibility checks
+	PlausibilityOff PlausibilityMode = "off"
+	// PlausibilityReject drops implausible positions
+	PlausibilityReject PlausibilityMode = "reject"
+	// PlausibilityFlag publishes implausible positions with the reason set on the ship
+	PlausibilityFlag PlausibilityMode = "flag"
+)
+
+const (
+	metresPerSecondPerKnot = 1852.0 / 3600.0
+	// jitterDistance is the distance in metres a ship may move between positions without checking the implied speed,
+	// as GPS jitter between positions reported close together can otherwise imply implausible speeds
+	jitterDistance = 500.0
+	// minImpliedSpeedInterval is the minimum time between positions used to calculate the implied speed (AIS
+	// observation times only have a resolution of one second)
+	minImpliedSpeedInterval = time.Second
+)
+
+// ParsePlausibilityMode converts the name of a mode (as used in config) into a PlausibilityMode
+func ParsePlausibilityMode(name string) (PlausibilityMode, error) {
+	mode := PlausibilityMode(strings.ToLower(strings.TrimSpace(name)))
+	switch mode {
+	case PlausibilityOff, PlausibilityReject, PlausibilityFlag:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown plausibility mode '%s' (expected off, reject or flag)", name)
+	}
+}
+
+// fix is a plausible position of a ship
+type fix struct {
+	latitude   float64
+	longitude  float64
+	observedAt time.Time
+}
+
+// plausibility checks positions against the previous plausible position for the ship. Ships are forgotten once no
+// plausible position has been received for them within the TTL so that a ship is not stuck with a bad first position.
+type plausibility struct {
+	mu sync.Mutex
+	// maxSpeed is the fastest speed in knots a ship is expected to travel at
+	maxSpeed float64
+	cache    *mmsiCache[fix]
+	// rejections limits logging rejected positions to at most once per reason per log interval
+	rejections *clog.Sampler
+}
+
+func newPlausibility(maxSpeed float64, ttl time.Duration, capacity int, logInterval time.Duration) *plausibility {
+	return &plausibility{
+		maxSpeed:   maxSpeed,
+		cache:      newMMSICache[fix](ttl, capacity),
+		rejections: clog.NewSampler(logInterval),
+	}
+}
+
+// check returns the reason the position is implausible (empty if it is plausible)
+func (p *plausibility) check(ship domain.Ship) domain.ImplausibleReason {
+	if ship.Latitude == 0 && ship.Longitude == 0 {
+		return domain.ImplausibleReasonNullIsland
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current := fix{latitude: ship.Latitude, longitude: ship.Longitude, observedAt: ship.LastUpdated}
+	if previous, ok := p.cache.get(ship.MMSI); ok && p.impliedSpeed(previous, current) > p.maxSpeed {
+		return domain.ImplausibleReasonImpliedSpeed
+	}
+
+	p.cache.put(ship.MMSI, current)
+	return ""
+}
+
+// impliedSpeed returns the speed in knots needed to travel between the positions (0 if they are within the jitter
+// distance of each other)
+func (p *plausibility) impliedSpeed(from, to fix) float64 {
+	distance := domain.Distance(from.latitude, from.longitude, to.latitude, to.longitude)
+	if distance <= jitterDistance {
+		return 0
+	}
+
+	elapsed := to.observedAt.Sub(from.observedAt)
+	if elapsed < 0 {
+		elapsed = -elapsed
+	}
+	if elapsed < minImpliedSpeedInterval {
+		elapsed = minImpliedSpeedInterval
+	}
+	return distance / elapsed.Seconds() / metresPerSecondPerKnot
+}
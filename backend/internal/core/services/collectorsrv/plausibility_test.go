@@ -0,0 +1,109 @@
+package collectorsrv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+func fixAt(mmsi int32, latitude, longitude float64, lastUpdated time.Time) domain.Ship {
+	return *domain.NewShip(mmsi, "", latitude, longitude, lastUpdated)
+}
+
+func TestPlausibility_Check(t *testing.T) {
+	p := newPlausibility(60, time.Minute, 10, time.Second)
+
+	tests := []struct {
+		name   string
+		ship   domain.Ship
+		reason domain.ImplausibleReason
+	}{
+		{"first position", fixAt(1, 50.8, -1.3, observedAt), ""},
+		// roughly 1 km in a minute is about 32 knots
+		{"plausible move", fixAt(1, 50.809, -1.3, observedAt.Add(time.Minute)), ""},
+		{"jump across the Atlantic", fixAt(1, 40.7, -74.0, observedAt.Add(2*time.Minute)), domain.ImplausibleReasonImpliedSpeed},
+		// compared against the last plausible position rather than the jump
+		{"move after the jump", fixAt(1, 50.818, -1.3, observedAt.Add(3*time.Minute)), ""},
+		{"jitter within a second", fixAt(1, 50.8184, -1.3, observedAt.Add(3*time.Minute)), ""},
+		{"null island", fixAt(1, 0, 0, observedAt.Add(4*time.Minute)), domain.ImplausibleReasonNullIsland},
+		{"null island for a new ship", fixAt(2, 0, 0, observedAt), domain.ImplausibleReasonNullIsland},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.reason, p.check(tt.ship))
+		})
+	}
+}
+
+func TestPlausibility_ForgetsShipsAfterTTL(t *testing.T) {
+	clock := &fakeClock{now: observedAt}
+	p := newPlausibility(60, time.Minute, 10, time.Second)
+	p.cache.now = clock.Now
+
+	require.Empty(t, p.check(fixAt(1, 40.7, -74.0, observedAt)))
+	require.NotEmpty(t, p.check(fixAt(1, 50.8, -1.3, observedAt.Add(time.Second))))
+
+	clock.now = clock.now.Add(time.Minute)
+	assert.Empty(t, p.check(fixAt(1, 50.8, -1.3, observedAt.Add(time.Minute))))
+}
+
+func TestParsePlausibilityMode(t *testing.T) {
+	mode, err := ParsePlausibilityMode(" Flag ")
+	require.NoError(t, err)
+	assert.Equal(t, PlausibilityFlag, mode)
+
+	_, err = ParsePlausibilityMode("warn")
+	assert.Error(t, err)
+}
+
+func TestService_ImplausiblePositions(t *testing.T) {
+	tests := []struct {
+		mode      string
+		published int
+	}{
+		{"reject", 1},
+		{"flag", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg, err := config.Load()
+			require.NoError(t, err)
+			cfg.CollectorPlausibilityMode = tt.mode
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			mockProducer := &MockProducer{}
+			metrics := &RecordingMetricsClient{}
+			s, err := New(ctx, *cfg, mockProducer, metrics)
+			require.NoError(t, err)
+
+			require.NoError(t, s.Process(context.Background(), fixAt(1, 50.8, -1.3, observedAt)))
+			require.NoError(t, s.Process(context.Background(), fixAt(1, 40.7, -74.0, observedAt.Add(time.Minute))))
+
+			require.Eventually(t, func() bool {
+				mockProducer.mu.Lock()
+				defer mockProducer.mu.Unlock()
+				return len(mockProducer.queue) == tt.published
+			}, 5*time.Second, 10*time.Millisecond)
+			cancel()
+			s.Shutdown()
+
+			metrics.mu.Lock()
+			defer metrics.mu.Unlock()
+			assert.Equal(t, map[string]int{"implied_speed": 1}, metrics.implausible)
+			flagged := 0
+			for _, ship := range mockProducer.queue {
+				if ship.ImplausibleReason == domain.ImplausibleReasonImpliedSpeed {
+					flagged++
+				}
+			}
+			assert.Equal(t, tt.published-1, flagged)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package collectorsrv
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy determines what happens to a report that is processed while the queue is full
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for space in the queue, dropping the report if none frees up within the block timeout
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest drops the report at the head of the queue to make room
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowDropNewest drops the report being processed
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowCoalesce replaces any queued report of the same kind for the same MMSI (whether or not the queue is full)
+	// and otherwise drops the report at the head of the queue to make room
+	OverflowCoalesce OverflowPolicy = "coalesce"
+)
+
+// Reasons for dropping a report
+const (
+	DropReasonBlockTimeout = "block_timeout"
+	DropReasonDropOldest   = "drop_oldest"
+	DropReasonDropNewest   = "drop_newest"
+	DropReasonCoalesced    = "coalesced"
+	DropReasonShutdown     = "shutdown"
+)
+
+// ParseOverflowPolicy converts the name of a policy (as used in config) into an OverflowPolicy
+func ParseOverflowPolicy(name string) (OverflowPolicy, error) {
+	policy := OverflowPolicy(strings.ToLower(strings.TrimSpace(name)))
+	switch policy {
+	case OverflowBlock, OverflowDropOldest, OverflowDropNewest, OverflowCoalesce:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown overflow policy '%s' (expected block, drop_oldest, drop_newest or coalesce)", name)
+	}
+}
+
+type reportKind int
+
+const (
+	reportKindShip reportKind = iota
+	reportKindStaticData
+	reportKindAidToNavigation
+	reportKindBaseStation
+)
+
+// reportKey identifies the reports that supersede each other when coalescing
+type reportKey struct {
+	kind reportKind
+	mmsi int32
+}
+
+func (r report) key() reportKey {
+	switch {
+	case r.staticData != nil:
+		return reportKey{kind: reportKindStaticData, mmsi: r.staticData.MMSI}
+	case r.aidToNavigation != nil:
+		return reportKey{kind: reportKindAidToNavigation, mmsi: r.aidToNavigation.MMSI}
+	case r.baseStation != nil:
+		return reportKey{kind: reportKindBaseStation, mmsi: r.baseStation.MMSI}
+	default:
+		return reportKey{kind: reportKindShip, mmsi: r.ship.MMSI}
+	}
+}
+
+// queue is a bounded FIFO queue of reports that applies an overflow policy when full
+type queue struct {
+	mu       sync.Mutex
+	items    *list.List
+	elements map[reportKey]*list.Element // only populated when coalescing
+
+	capacity     int
+	policy       OverflowPolicy
+	blockTimeout time.Duration
+	metrics      Metrics
+
+	// notEmpty and notFull wake a waiting worker or a blocked producer respectively
+	notEmpty chan struct{}
+	notFull  chan struct{}
+	// closed is closed once the queue stops accepting reports
+	closed   chan struct{}
+	isClosed bool
+}
+
+func newQueue(capacity int, policy OverflowPolicy, blockTimeout time.Duration, metrics Metrics) *queue {
+	return &queue{
+		items:        list.New(),
+		elements:     make(map[reportKey]*list.Element),
+		capacity:     capacity,
+		policy:       policy,
+		blockTimeout: blockTimeout,
+		metrics:      metrics,
+		notEmpty:     make(chan struct{}, 1),
+		notFull:      make(chan struct{}, 1),
+		closed:       make(chan struct{}),
+	}
+}
+
+// push adds a report to the tail of the queue, applying the overflow policy if the queue is full. When blocking, a
+// block timeout of zero waits until there is space or done is closed.
+func (q *queue) push(done <-chan struct{}, r report) {
+	var timeout <-chan time.Time
+	if q.policy == OverflowBlock && q.blockTimeout > 0 {
+		timer := time.NewTimer(q.blockTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		dropReason, queued := q.tryPush(r)
+		if dropReason != "" {
+			q.metrics.CollectorReportDropped(dropReason)
+		}
+		if queued {
+			return
+		}
+
+		select {
+		case <-q.notFull:
+		case <-timeout:
+			q.metrics.CollectorReportDropped(DropReasonBlockTimeout)
+			return
+		case <-done:
+			q.metrics.CollectorReportDropped(DropReasonShutdown)
+			return
+		}
+	}
+}
+
+// tryPush adds the report without waiting. It returns the reason for any report that was dropped (either the given
+// report or one that it displaced) and whether the caller is finished with the report, i.e. it should not wait for
+// space to free up.
+func (q *queue) tryPush(r report) (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.isClosed {
+		return DropReasonShutdown, true
+	}
+
+	if q.policy == OverflowCoalesce {
+		if element, ok := q.elements[r.key()]; ok {
+			element.Value = r
+			return DropReasonCoalesced, true
+		}
+	}
+
+	var dropReason string
+	if q.items.Len() >= q.capacity {
+		switch q.policy {
+		case OverflowDropNewest:
+			return DropReasonDropNewest, true
+		case OverflowDropOldest, OverflowCoalesce:
+			q.remove(q.items.Front())
+			dropReason = DropReasonDropOldest
+		default:
+			return "", false
+		}
+	}
+
+	element := q.items.PushBack(r)
+	if q.policy == OverflowCoalesce {
+		q.elements[r.key()] = element
+	}
+	q.metrics.CollectorQueueDepth(q.items.Len())
+	signal(q.notEmpty)
+	if q.items.Len() < q.capacity {
+		// pass on the wake up to any other blocked producer
+		signal(q.notFull)
+	}
+	return dropReason, true
+}
+
+// pop removes the report at the head of the queue, waiting until one is available. It returns false if done is
+// closed while waiting or if the queue has been closed and is empty.
+func (q *queue) pop(done <-chan struct{}) (report, bool) {
+	for {
+		q.mu.Lock()
+		if front := q.items.Front(); front != nil {
+			r := q.remove(front)
+			q.metrics.CollectorQueueDepth(q.items.Len())
+			if q.items.Len() > 0 {
+				// pass on the wake up to any other waiting worker
+				signal(q.notEmpty)
+			}
+			q.mu.Unlock()
+			signal(q.notFull)
+			return r, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.notEmpty:
+		case <-q.closed:
+			return report{}, false
+		case <-done:
+			return report{}, false
+		}
+	}
+}
+
+// close stops the queue accepting reports. Reports that are already queued can still be popped.
+func (q *queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.isClosed {
+		q.isClosed = true
+		close(q.closed)
+	}
+}
+
+func (q *queue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Len()
+}
+
+// remove must be called with the mutex held
+func (q *queue) remove(element *list.Element) report {
+	r := q.items.Remove(element).(report)
+	if q.policy == OverflowCoalesce {
+		delete(q.elements, r.key())
+	}
+	return r
+}
+
+// signal wakes a waiting goroutine without blocking if one has already been woken
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,206 @@
+package collectorsrv
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+)
+
+type RecordingMetricsClient struct {
+	mu          sync.Mutex
+	depth       int
+	dropped     map[string]int
+	throttled   int
+	suppressed  map[string]int
+	implausible map[string]int
+}
+
+func (r *RecordingMetricsClient) CollectorQueueDepth(depth int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.depth = depth
+}
+
+func (r *RecordingMetricsClient) CollectorReportDropped(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dropped == nil {
+		r.dropped = make(map[string]int)
+	}
+	r.dropped[reason]++
+}
+
+func (r *RecordingMetricsClient) CollectorPositionThrottled() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.throttled++
+}
+
+func (r *RecordingMetricsClient) CollectorPositionSuppressed(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.suppressed == nil {
+		r.suppressed = make(map[string]int)
+	}
+	r.suppressed[reason]++
+}
+
+func (r *RecordingMetricsClient) CollectorPositionImplausible(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.implausible == nil {
+		r.implausible = make(map[string]int)
+	}
+	r.implausible[reason]++
+}
+
+func shipReport(mmsi int32, name string) report {
+	return report{ship: domain.NewShip(mmsi, name, 66.02695, 12.253821666666665, time.Now())}
+}
+
+// drain pops every queued report and returns the names of the ships
+func drain(t *testing.T, q *queue) []string {
+	t.Helper()
+
+	done := make(chan struct{})
+	close(done)
+	var names []string
+	for {
+		r, ok := q.pop(done)
+		if !ok {
+			return names
+		}
+		names = append(names, r.ship.Name)
+	}
+}
+
+func TestQueue_DropNewest(t *testing.T) {
+	metrics := &RecordingMetricsClient{}
+	q := newQueue(2, OverflowDropNewest, 0, metrics)
+
+	q.push(nil, shipReport(1, "A"))
+	q.push(nil, shipReport(2, "B"))
+	q.push(nil, shipReport(3, "C"))
+
+	assert.Equal(t, 2, metrics.depth)
+	assert.Equal(t, 1, metrics.dropped[DropReasonDropNewest])
+	assert.Equal(t, []string{"A", "B"}, drain(t, q))
+	assert.Equal(t, 0, metrics.depth)
+}
+
+func TestQueue_DropOldest(t *testing.T) {
+	metrics := &RecordingMetricsClient{}
+	q := newQueue(2, OverflowDropOldest, 0, metrics)
+
+	q.push(nil, shipReport(1, "A"))
+	q.push(nil, shipReport(2, "B"))
+	q.push(nil, shipReport(3, "C"))
+
+	assert.Equal(t, 1, metrics.dropped[DropReasonDropOldest])
+	assert.Equal(t, []string{"B", "C"}, drain(t, q))
+}
+
+func TestQueue_Coalesce(t *testing.T) {
+	metrics := &RecordingMetricsClient{}
+	q := newQueue(2, OverflowCoalesce, 0, metrics)
+
+	q.push(nil, shipReport(1, "A"))
+	q.push(nil, shipReport(2, "B"))
+	// replaces the queued position for the same MMSI without losing its place in the queue
+	q.push(nil, shipReport(1, "A2"))
+	// static data for the same MMSI is a different kind of report so is not coalesced with the position
+	q.push(nil, report{staticData: &domain.ShipStaticData{MMSI: 2, Name: "B"}})
+
+	assert.Equal(t, 1, metrics.dropped[DropReasonCoalesced])
+	assert.Equal(t, 1, metrics.dropped[DropReasonDropOldest])
+
+	done := make(chan struct{})
+	close(done)
+	r, ok := q.pop(done)
+	require.True(t, ok)
+	assert.Equal(t, "B", r.ship.Name)
+	r, ok = q.pop(done)
+	require.True(t, ok)
+	require.NotNil(t, r.staticData)
+
+	// coalescing only applies to queued reports
+	q.push(nil, shipReport(1, "A3"))
+	assert.Equal(t, []string{"A3"}, drain(t, q))
+}
+
+func TestQueue_BlockTimesOut(t *testing.T) {
+	metrics := &RecordingMetricsClient{}
+	q := newQueue(1, OverflowBlock, 50*time.Millisecond, metrics)
+
+	q.push(nil, shipReport(1, "A"))
+	start := time.Now()
+	q.push(nil, shipReport(2, "B"))
+
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, 1, metrics.dropped[DropReasonBlockTimeout])
+	assert.Equal(t, []string{"A"}, drain(t, q))
+}
+
+func TestQueue_BlockStopsWhenDone(t *testing.T) {
+	metrics := &RecordingMetricsClient{}
+	q := newQueue(1, OverflowBlock, 0, metrics)
+	q.push(nil, shipReport(1, "A"))
+
+	done := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(done)
+	}()
+	q.push(done, shipReport(2, "B"))
+
+	// a report abandoned because the collector is shutting down didn't time out
+	assert.Equal(t, 1, metrics.dropped[DropReasonShutdown])
+	assert.Zero(t, metrics.dropped[DropReasonBlockTimeout])
+	assert.Equal(t, []string{"A"}, drain(t, q))
+}
+
+func TestQueue_BlockWaitsForSpace(t *testing.T) {
+	metrics := &RecordingMetricsClient{}
+	q := newQueue(1, OverflowBlock, 5*time.Second, metrics)
+	q.push(nil, shipReport(1, "A"))
+
+	pushed := make(chan struct{})
+	go func() {
+		q.push(nil, shipReport(2, "B"))
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push should block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	r, ok := q.pop(nil)
+	require.True(t, ok)
+	assert.Equal(t, "A", r.ship.Name)
+	select {
+	case <-pushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("push should complete once space is freed")
+	}
+	assert.Empty(t, metrics.dropped)
+	assert.Equal(t, []string{"B"}, drain(t, q))
+}
+
+func TestQueue_PopStopsWhenDone(t *testing.T) {
+	q := newQueue(1, OverflowBlock, 0, &RecordingMetricsClient{})
+
+	done := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(done)
+	}()
+	_, ok := q.pop(done)
+	assert.False(t, ok)
+}
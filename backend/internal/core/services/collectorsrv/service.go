@@ -4,71 +4,435 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
 	"github.com/mikeewhite/ship-locator/backend/internal/core/ports"
+	"github.com/mikeewhite/ship-locator/backend/pkg/apperrors"
 	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
 )
 
-const (
-	dataChanSize   = 500
-	workerPoolSize = 5
-)
+type Metrics interface {
+	CollectorQueueDepth(depth int)
+	CollectorReportDropped(reason string)
+	CollectorPositionThrottled()
+	CollectorPositionSuppressed(reason string)
+	CollectorPositionImplausible(reason string)
+}
+
+// alertBufferSize is the number of safety alerts that can wait to be published. Alerts bypass the queue so that they
+// are neither held up behind nor dropped in favour of routine reports.
+const alertBufferSize = 100
+
+// report is a unit of work queued for publishing. Exactly one of its entity fields is set.
+type report struct {
+	// ctx is the context the report was received with (nil for positions released by the throttle), whose values
+	// (such as the trace the report is part of) are passed on when the report is published
+	ctx             context.Context
+	ship            *domain.Ship
+	staticData      *domain.ShipStaticData
+	aidToNavigation *domain.AidToNavigation
+	baseStation     *domain.BaseStation
+	safetyAlert     *domain.SafetyAlert
+}
+
+// reportContext is cancelled along with the context used to publish reports but carries the values of the context
+// the report was received with, which may already have been cancelled by the time the report is published
+type reportContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c reportContext) Value(key any) any {
+	return c.values.Value(key)
+}
+
+// DrainResult counts the reports that were published or given up on while the service was shutting down
+type DrainResult struct {
+	Flushed   int
+	Abandoned int
+}
 
 type Service struct {
-	ch           chan *domain.Ship
+	queue *queue
+	// dedup is nil if duplicate and out-of-order positions are not suppressed
+	dedup *dedup
+	// plausibility is nil if positions are not checked for plausibility
+	plausibility     *plausibility
+	plausibilityMode PlausibilityMode
+	// throttle is nil if positions are not throttled
+	throttle *throttle
+	alerts   chan report
+	// alertsMu guards closing the alerts channel against alerts being pushed to it
+	alertsMu     sync.RWMutex
+	alertsClosed bool
 	msgPublisher ports.Producer
-	wg           sync.WaitGroup
+	metrics      Metrics
+
+	// closing is closed once the service starts shutting down
+	closing   chan struct{}
+	closeOnce sync.Once
+	// publishCtx is used to publish reports and is cancelled once the drain timeout elapses
+	publishCtx    context.Context
+	cancelPublish context.CancelFunc
+	drainTimeout  time.Duration
+	draining      atomic.Bool
+	flushed       atomic.Int64
+	abandoned     atomic.Int64
+	// wg tracks the workers and flusherWG the routine that flushes throttled positions
+	wg        sync.WaitGroup
+	flusherWG sync.WaitGroup
 }
 
-func New(ctx context.Context, publisher ports.Producer) *Service {
+// New creates the service and starts its pool of workers, which publish queued reports until the service is shut down.
+// Reports that are dropped because the queue is full are counted in the metrics rather than returned as errors so that
+// sources keep reading during a broker outage. Cancelling the context starts the shutdown early so that sources
+// waiting for space in the queue give up, but buffered reports are only published once Shutdown is called.
+func New(ctx context.Context, cfg config.Config, publisher ports.Producer, metrics Metrics) (*Service, error) {
+	if cfg.CollectorQueueSize < 1 {
+		return nil, fmt.Errorf("collector queue size must be at least 1 (got %d)", cfg.CollectorQueueSize)
+	}
+	if cfg.CollectorWorkers < 1 {
+		return nil, fmt.Errorf("collector workers must be at least 1 (got %d)", cfg.CollectorWorkers)
+	}
+	if cfg.CollectorDedupTTL > 0 && cfg.CollectorDedupCacheSize < 1 {
+		return nil, fmt.Errorf("collector dedup cache size must be at least 1 (got %d)", cfg.CollectorDedupCacheSize)
+	}
+	policy, err := ParseOverflowPolicy(cfg.CollectorOverflowPolicy)
+	if err != nil {
+		return nil, err
+	}
+	plausibilityMode, err := ParsePlausibilityMode(cfg.CollectorPlausibilityMode)
+	if err != nil {
+		return nil, err
+	}
+	if plausibilityMode != PlausibilityOff {
+		if cfg.CollectorPlausibilityMaxSpeed <= 0 {
+			return nil, fmt.Errorf("collector plausibility max speed must be positive (got %g)",
+				cfg.CollectorPlausibilityMaxSpeed)
+		}
+		if cfg.CollectorPlausibilityCacheSize < 1 {
+			return nil, fmt.Errorf("collector plausibility cache size must be at least 1 (got %d)",
+				cfg.CollectorPlausibilityCacheSize)
+		}
+	}
+
+	publishCtx, cancelPublish := context.WithCancel(context.Background())
 	s := &Service{
-		ch:           make(chan *domain.Ship, dataChanSize),
-		msgPublisher: publisher,
+		queue:            newQueue(cfg.CollectorQueueSize, policy, cfg.CollectorBlockTimeout, metrics),
+		msgPublisher:     publisher,
+		metrics:          metrics,
+		plausibilityMode: plausibilityMode,
+		alerts:           make(chan report, alertBufferSize),
+		closing:          make(chan struct{}),
+		publishCtx:       publishCtx,
+		cancelPublish:    cancelPublish,
+		drainTimeout:     cfg.CollectorDrainTimeout,
 	}
 
-	for i := 0; i < workerPoolSize; i++ {
+	for i := 0; i < cfg.CollectorWorkers; i++ {
 		s.wg.Add(1)
-		go s.worker(ctx)
+		go s.worker()
+	}
+	s.wg.Add(1)
+	go s.alertWorker()
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.stopAccepting()
+		case <-s.closing:
+		}
+	}()
+
+	if cfg.CollectorDedupTTL > 0 {
+		s.dedup = newDedup(cfg.CollectorDedupTTL, cfg.CollectorDedupCacheSize)
+	}
+
+	if plausibilityMode != PlausibilityOff {
+		s.plausibility = newPlausibility(cfg.CollectorPlausibilityMaxSpeed, cfg.CollectorPlausibilityTTL,
+			cfg.CollectorPlausibilityCacheSize, cfg.CollectorPlausibilityLogInterval)
 	}
 
-	return s
+	if cfg.CollectorThrottleWindow > 0 {
+		s.throttle = newThrottle(cfg.CollectorThrottleWindow, cfg.CollectorThrottleDistance)
+		s.flusherWG.Add(1)
+		go s.flushThrottledPositions()
+	}
+
+	return s, nil
 }
 
-func (s *Service) Process(mmsi int32, shipName string, latitude, longitude float64) error {
-	ship := domain.NewShip(mmsi, shipName, latitude, longitude, time.Now())
+func (s *Service) Process(ctx context.Context, ship domain.Ship) error {
 	if err := ship.Validate(); err != nil {
-		return fmt.Errorf("invalid ship entity: %w", err)
+		return apperrors.NewInvalidReportErr(fmt.Errorf("invalid ship entity: %w", err))
+	}
+
+	if s.dedup != nil {
+		if ok, reason := s.dedup.admit(ship); !ok {
+			s.metrics.CollectorPositionSuppressed(string(reason))
+			return nil
+		}
+	}
+
+	// positions from distress beacons raise an alert as well as being published as positions
+	if info, err := domain.ClassifyMMSI(ship.MMSI); err == nil && info.StationType.IsDistressBeacon() {
+		alert := domain.NewBeaconAlert(ship)
+		s.pushAlert(report{ctx: ctx, safetyAlert: &alert})
+	}
+
+	if s.plausibility != nil {
+		if reason := s.plausibility.check(ship); reason != "" {
+			s.metrics.CollectorPositionImplausible(string(reason))
+			if s.plausibilityMode == PlausibilityReject {
+				if log, unlogged := s.plausibility.rejections.Sample(string(reason), time.Now()); log {
+					clog.Warnw("rejecting implausible report",
+						"mmsi", ship.MMSI,
+						"reason", string(reason),
+						"unlogged", unlogged)
+				}
+				return nil
+			}
+			ship.ImplausibleReason = reason
+		}
+	}
+
+	if s.throttle != nil && !s.throttle.admit(ship) {
+		s.metrics.CollectorPositionThrottled()
+		return nil
+	}
+
+	s.queue.push(s.closing, report{ctx: ctx, ship: &ship})
+
+	return nil
+}
+
+func (s *Service) ProcessStaticData(ctx context.Context, data domain.ShipStaticData) error {
+	data.Normalise()
+	if err := data.Validate(); err != nil {
+		return apperrors.NewInvalidReportErr(fmt.Errorf("invalid ship static data: %w", err))
 	}
 
-	s.ch <- ship
+	s.queue.push(s.closing, report{ctx: ctx, staticData: &data})
 
 	return nil
 }
 
-func (s *Service) Shutdown() {
-	s.wg.Wait()
-	close(s.ch)
+func (s *Service) ProcessAidToNavigation(ctx context.Context, aid domain.AidToNavigation) error {
+	aid.Normalise()
+	if err := aid.Validate(); err != nil {
+		return apperrors.NewInvalidReportErr(fmt.Errorf("invalid aid to navigation: %w", err))
+	}
+
+	s.queue.push(s.closing, report{ctx: ctx, aidToNavigation: &aid})
+
+	return nil
 }
 
-func (s *Service) worker(ctx context.Context) {
+func (s *Service) ProcessBaseStation(ctx context.Context, station domain.BaseStation) error {
+	if err := station.Validate(); err != nil {
+		return apperrors.NewInvalidReportErr(fmt.Errorf("invalid base station: %w", err))
+	}
+
+	s.queue.push(s.closing, report{ctx: ctx, baseStation: &station})
+
+	return nil
+}
+
+func (s *Service) ProcessSafetyAlert(ctx context.Context, alert domain.SafetyAlert) error {
+	alert.Normalise()
+	if err := alert.Validate(); err != nil {
+		return apperrors.NewInvalidReportErr(fmt.Errorf("invalid safety alert: %w", err))
+	}
+
+	s.pushAlert(report{ctx: ctx, safetyAlert: &alert})
+
+	return nil
+}
+
+// pushAlert waits for room in the alert buffer rather than dropping the alert, unless the service is shutting down
+func (s *Service) pushAlert(alert report) {
+	s.alertsMu.RLock()
+	defer s.alertsMu.RUnlock()
+	if s.alertsClosed {
+		s.metrics.CollectorReportDropped(DropReasonShutdown)
+		return
+	}
+	// alerts are still accepted while there is room after the service has started shutting down
+	select {
+	case s.alerts <- alert:
+		return
+	default:
+	}
+	select {
+	case s.alerts <- alert:
+	case <-s.closing:
+		s.metrics.CollectorReportDropped(DropReasonShutdown)
+	}
+}
+
+// Shutdown stops the service accepting reports and publishes the reports it has buffered (including any positions
+// held back by the throttle). Reports that have not been published once the drain timeout has elapsed are abandoned.
+// The producer must not be shut down until this returns.
+func (s *Service) Shutdown() DrainResult {
+	start := time.Now()
+	s.draining.Store(true)
+	s.stopAccepting()
+
+	s.flusherWG.Wait()
+	if s.throttle != nil {
+		for _, ship := range s.throttle.flushAll() {
+			ship := ship
+			if _, queued := s.queue.tryPush(report{ship: &ship}); !queued {
+				s.abandoned.Add(1)
+			}
+		}
+	}
+	s.queue.close()
+	s.alertsMu.Lock()
+	if !s.alertsClosed {
+		s.alertsClosed = true
+		close(s.alerts)
+	}
+	s.alertsMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
+	timer := time.NewTimer(s.drainTimeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+	case <-timer.C:
+		clog.Warnw("drain timeout elapsed before all buffered reports were published",
+			"timeout", s.drainTimeout.String())
+		s.cancelPublish()
+		<-drained
+	}
+	s.cancelPublish()
+
+	s.abandoned.Add(int64(s.queue.len() + len(s.alerts)))
+	result := DrainResult{
+		Flushed:   int(s.flushed.Load()),
+		Abandoned: int(s.abandoned.Load()),
+	}
+	clog.Infow("collector service drained",
+		"flushed", result.Flushed,
+		"abandoned", result.Abandoned,
+		"duration", time.Since(start).String())
+	return result
+}
+
+func (s *Service) stopAccepting() {
+	s.closeOnce.Do(func() {
+		close(s.closing)
+	})
+}
+
+func (s *Service) worker() {
+	defer s.wg.Done()
+	for {
+		r, ok := s.queue.pop(s.publishCtx.Done())
+		if !ok {
+			clog.Info("worker routine stopped")
+			return
+		}
+		s.countDrained(s.publish(r))
+	}
+}
+
+func (s *Service) alertWorker() {
 	defer s.wg.Done()
 	for {
 		select {
-		case ship, ok := <-s.ch:
+		case alert, ok := <-s.alerts:
 			if !ok {
 				return
 			}
-			if err := s.msgPublisher.Write(ctx, *ship); err != nil {
-				clog.Errorw("failed to write ship data to msg publisher",
-					"error", err.Error(),
-					"mmsi", ship.MMSI,
-					"name", ship.Name)
+			s.countDrained(s.publish(alert))
+		case <-s.publishCtx.Done():
+			return
+		}
+	}
+}
+
+// countDrained counts the outcome of publishing a report while the service is shutting down
+func (s *Service) countDrained(err error) {
+	if !s.draining.Load() {
+		return
+	}
+	if err != nil {
+		s.abandoned.Add(1)
+		return
+	}
+	s.flushed.Add(1)
+}
+
+// flushThrottledPositions queues the positions held back by the throttle once they are due to be published. The
+// throttle doesn't keep the context the positions were received with, so they are published outside of the trace of
+// the message that carried them.
+func (s *Service) flushThrottledPositions() {
+	defer s.flusherWG.Done()
+	ticker := time.NewTicker(s.throttle.flushInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, ship := range s.throttle.flush() {
+				ship := ship
+				s.queue.push(s.closing, report{ship: &ship})
 			}
-		case <-ctx.Done():
-			clog.Info("worker routine stopped")
+		case <-s.closing:
 			return
 		}
 	}
 }
+
+// publish writes the report to the message publisher, logging any error
+func (s *Service) publish(r report) error {
+	var ctx context.Context = s.publishCtx
+	if r.ctx != nil {
+		ctx = reportContext{Context: s.publishCtx, values: r.ctx}
+	}
+	var err error
+	switch {
+	case r.ship != nil:
+		if err = s.msgPublisher.Write(ctx, *r.ship); err != nil {
+			clog.Errorw("failed to write ship data to msg publisher",
+				"error", err.Error(),
+				"mmsi", r.ship.MMSI,
+				"name", r.ship.Name)
+		}
+	case r.staticData != nil:
+		if err = s.msgPublisher.WriteStaticData(ctx, *r.staticData); err != nil {
+			clog.Errorw("failed to write ship static data to msg publisher",
+				"error", err.Error(),
+				"mmsi", r.staticData.MMSI,
+				"name", r.staticData.Name)
+		}
+	case r.aidToNavigation != nil:
+		if err = s.msgPublisher.WriteAidToNavigation(ctx, *r.aidToNavigation); err != nil {
+			clog.Errorw("failed to write aid to navigation to msg publisher",
+				"error", err.Error(),
+				"mmsi", r.aidToNavigation.MMSI,
+				"name", r.aidToNavigation.Name)
+		}
+	case r.baseStation != nil:
+		if err = s.msgPublisher.WriteBaseStation(ctx, *r.baseStation); err != nil {
+			clog.Errorw("failed to write base station to msg publisher",
+				"error", err.Error(),
+				"mmsi", r.baseStation.MMSI)
+		}
+	case r.safetyAlert != nil:
+		if err = s.msgPublisher.WriteSafetyAlert(ctx, *r.safetyAlert); err != nil {
+			clog.Errorw("failed to write safety alert to msg publisher",
+				"error", err.Error(),
+				"mmsi", r.safetyAlert.MMSI,
+				"kind", r.safetyAlert.Kind)
+		}
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package collectorsrv
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -9,30 +10,304 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/apperrors"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
 )
 
 type MockProducer struct {
-	queue []domain.Ship
+	mu                   sync.Mutex
+	queue                []domain.Ship
+	staticDataQueue      []domain.ShipStaticData
+	aidToNavigationQueue []domain.AidToNavigation
+	baseStationQueue     []domain.BaseStation
+	safetyAlertQueue     []domain.SafetyAlert
+	// contexts holds the context each ship was written with
+	contexts []context.Context
 }
 
 func (mp *MockProducer) Write(ctx context.Context, data domain.Ship) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
 	mp.queue = append(mp.queue, data)
+	mp.contexts = append(mp.contexts, ctx)
 	return nil
 }
 
+func (mp *MockProducer) WriteStaticData(ctx context.Context, data domain.ShipStaticData) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.staticDataQueue = append(mp.staticDataQueue, data)
+	return nil
+}
+
+func (mp *MockProducer) WriteAidToNavigation(ctx context.Context, data domain.AidToNavigation) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.aidToNavigationQueue = append(mp.aidToNavigationQueue, data)
+	return nil
+}
+
+func (mp *MockProducer) WriteBaseStation(ctx context.Context, data domain.BaseStation) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.baseStationQueue = append(mp.baseStationQueue, data)
+	return nil
+}
+
+func (mp *MockProducer) WriteSafetyAlert(ctx context.Context, alert domain.SafetyAlert) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.safetyAlertQueue = append(mp.safetyAlertQueue, alert)
+	return nil
+}
+
+type NoopMetricsClient struct{}
+
+func (n *NoopMetricsClient) CollectorQueueDepth(_ int) {}
+
+func (n *NoopMetricsClient) CollectorReportDropped(_ string) {}
+
+func (n *NoopMetricsClient) CollectorPositionThrottled() {}
+
+func (n *NoopMetricsClient) CollectorPositionSuppressed(_ string) {}
+
+func (n *NoopMetricsClient) CollectorPositionImplausible(_ string) {}
+
+func newTestService(t *testing.T, producer *MockProducer) *Service {
+	t.Helper()
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	s, err := New(context.Background(), *cfg, producer, &NoopMetricsClient{})
+	require.NoError(t, err)
+	return s
+}
+
 func TestService_Process(t *testing.T) {
 	mockProducer := &MockProducer{}
-	s := New(context.Background(), mockProducer)
+	s := newTestService(t, mockProducer)
 
-	require.NoError(t, s.Process(12345, "CALL SIGN", 66.02695, 12.253821666666665))
+	ship := domain.NewShip(12345, "CALL SIGN", 66.02695, 12.253821666666665, time.Now())
+	ship.Kinematics = domain.NewKinematics(12.3, 308, 235, 0, 0)
+	require.NoError(t, s.Process(context.Background(), *ship))
 
 	time.Sleep(1 * time.Second) // sleep to allow time for worker pool to process job
 
+	mockProducer.mu.Lock()
+	defer mockProducer.mu.Unlock()
 	require.NotEmpty(t, mockProducer.queue)
 	require.Len(t, mockProducer.queue, 1)
-	ship := mockProducer.queue[0]
-	assert.Equal(t, int32(12345), ship.MMSI)
-	assert.Equal(t, "CALL SIGN", ship.Name)
-	assert.Equal(t, 66.02695, ship.Latitude)
-	assert.Equal(t, 12.253821666666665, ship.Longitude)
+	published := mockProducer.queue[0]
+	assert.Equal(t, int32(12345), published.MMSI)
+	assert.Equal(t, "CALL SIGN", published.Name)
+	assert.Equal(t, 66.02695, published.Latitude)
+	assert.Equal(t, 12.253821666666665, published.Longitude)
+	require.NotNil(t, published.SpeedOverGround)
+	assert.Equal(t, 12.3, *published.SpeedOverGround)
+	require.NotNil(t, published.TrueHeading)
+	assert.Equal(t, int32(235), *published.TrueHeading)
+}
+
+func TestService_ProcessStaticData(t *testing.T) {
+	mockProducer := &MockProducer{}
+	s := newTestService(t, mockProducer)
+
+	require.NoError(t, s.ProcessStaticData(context.Background(), domain.ShipStaticData{
+		MMSI:        259000420,
+		Name:        "AUGUSTSON@@@",
+		CallSign:    "LAGV",
+		IMONumber:   9000000,
+		Destination: "BODO",
+	}))
+
+	time.Sleep(1 * time.Second) // sleep to allow time for worker pool to process job
+
+	mockProducer.mu.Lock()
+	defer mockProducer.mu.Unlock()
+	require.Len(t, mockProducer.staticDataQueue, 1)
+	data := mockProducer.staticDataQueue[0]
+	assert.Equal(t, int32(259000420), data.MMSI)
+	assert.Equal(t, "AUGUSTSON", data.Name)
+	assert.Equal(t, "LAGV", data.CallSign)
+	assert.Equal(t, int32(9000000), data.IMONumber)
+	assert.Equal(t, "BODO", data.Destination)
+}
+
+func TestService_ProcessStaticData_RejectsInvalidData(t *testing.T) {
+	s := newTestService(t, &MockProducer{})
+	assert.ErrorIs(t, s.ProcessStaticData(context.Background(), domain.ShipStaticData{Name: "NO MMSI"}),
+		apperrors.ErrInvalidReport)
+}
+
+func TestService_Process_RejectsInvalidShip(t *testing.T) {
+	s := newTestService(t, &MockProducer{})
+	assert.ErrorIs(t, s.Process(context.Background(), *domain.NewShip(12345, "CALL SIGN", 91, 12.253821666666665, time.Now())),
+		apperrors.ErrInvalidReport)
+}
+
+func TestService_ProcessAidToNavigationAndBaseStation(t *testing.T) {
+	mockProducer := &MockProducer{}
+	s := newTestService(t, mockProducer)
+
+	require.NoError(t, s.ProcessAidToNavigation(context.Background(), domain.AidToNavigation{
+		MMSI:      992351000,
+		Name:      "BRAMBLE BANK@@@",
+		AidType:   20,
+		Latitude:  50.79,
+		Longitude: -1.29,
+	}))
+	require.NoError(t, s.ProcessBaseStation(context.Background(), domain.BaseStation{MMSI: 2320001, Latitude: 50.8, Longitude: -1.3}))
+	assert.Error(t, s.ProcessAidToNavigation(context.Background(), domain.AidToNavigation{Name: "NO MMSI"}))
+	assert.Error(t, s.ProcessBaseStation(context.Background(), domain.BaseStation{MMSI: 2320001, Latitude: 91, Longitude: 181}))
+
+	time.Sleep(1 * time.Second) // sleep to allow time for worker pool to process job
+
+	mockProducer.mu.Lock()
+	defer mockProducer.mu.Unlock()
+	require.Len(t, mockProducer.aidToNavigationQueue, 1)
+	assert.Equal(t, "BRAMBLE BANK", mockProducer.aidToNavigationQueue[0].Name)
+	require.Len(t, mockProducer.baseStationQueue, 1)
+	assert.Equal(t, int32(2320001), mockProducer.baseStationQueue[0].MMSI)
+}
+
+func TestService_ProcessSafetyAlerts(t *testing.T) {
+	mockProducer := &MockProducer{}
+	s := newTestService(t, mockProducer)
+
+	require.NoError(t, s.ProcessSafetyAlert(context.Background(), domain.SafetyAlert{
+		MMSI:          2320001,
+		Kind:          domain.SafetyAlertKindBroadcast,
+		Text:          "GALE WARNING@@@",
+		TransmittedAt: time.Now(),
+	}))
+	assert.Error(t, s.ProcessSafetyAlert(context.Background(), domain.SafetyAlert{MMSI: 235000000, Kind: domain.SafetyAlertKindAddressed}))
+	// positions from a MOB device are published as positions as well as raising an alert
+	require.NoError(t, s.Process(context.Background(), *domain.NewShip(972123456, "", 50.8, -1.3, time.Now())))
+	require.NoError(t, s.Process(context.Background(), *domain.NewShip(235000000, "SEA BREEZE", 50.8, -1.3, time.Now())))
+
+	time.Sleep(1 * time.Second) // sleep to allow time for worker pool to process job
+
+	mockProducer.mu.Lock()
+	defer mockProducer.mu.Unlock()
+	require.Len(t, mockProducer.safetyAlertQueue, 2)
+	assert.Equal(t, "GALE WARNING", mockProducer.safetyAlertQueue[0].Text)
+	beacon := mockProducer.safetyAlertQueue[1]
+	assert.Equal(t, int32(972123456), beacon.MMSI)
+	assert.Equal(t, domain.SafetyAlertKindBeacon, beacon.Kind)
+	require.NotNil(t, beacon.Latitude)
+	assert.Equal(t, 50.8, *beacon.Latitude)
+	assert.Len(t, mockProducer.queue, 2)
+}
+
+type contextKey struct{}
+
+func TestService_Process_PassesOnContextValues(t *testing.T) {
+	mockProducer := &MockProducer{}
+	s := newTestService(t, mockProducer)
+
+	// the context the report is received with may be cancelled before it is published
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "trace"))
+	ship := domain.NewShip(12345, "CALL SIGN", 66.02695, 12.253821666666665, time.Now())
+	require.NoError(t, s.Process(ctx, *ship))
+	cancel()
+
+	require.Eventually(t, func() bool {
+		mockProducer.mu.Lock()
+		defer mockProducer.mu.Unlock()
+		return len(mockProducer.contexts) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	mockProducer.mu.Lock()
+	defer mockProducer.mu.Unlock()
+	assert.Equal(t, "trace", mockProducer.contexts[0].Value(contextKey{}))
+	assert.NoError(t, mockProducer.contexts[0].Err())
+}
+
+func TestNew_RejectsInvalidConfig(t *testing.T) {
+	tt := map[string]func(cfg *config.Config){
+		"zero queue size":         func(cfg *config.Config) { cfg.CollectorQueueSize = 0 },
+		"zero workers":            func(cfg *config.Config) { cfg.CollectorWorkers = 0 },
+		"unknown overflow policy": func(cfg *config.Config) { cfg.CollectorOverflowPolicy = "drop_everything" },
+	}
+
+	for name, mutate := range tt {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := config.Load()
+			require.NoError(t, err)
+			mutate(cfg)
+
+			_, err = New(context.Background(), *cfg, &MockProducer{}, &NoopMetricsClient{})
+			assert.Error(t, err)
+		})
+	}
+}
+
+// BlockingProducer holds up writing positions until it is released or the write is cancelled
+type BlockingProducer struct {
+	MockProducer
+	release chan struct{}
+}
+
+func (bp *BlockingProducer) Write(ctx context.Context, data domain.Ship) error {
+	select {
+	case <-bp.release:
+		return bp.MockProducer.Write(ctx, data)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestService_Shutdown_FlushesBufferedReports(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.CollectorThrottleWindow = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	producer := &BlockingProducer{release: make(chan struct{})}
+	metrics := &RecordingMetricsClient{}
+	s, err := New(ctx, *cfg, producer, metrics)
+	require.NoError(t, err)
+
+	for mmsi := int32(1); mmsi <= 10; mmsi++ {
+		require.NoError(t, s.Process(context.Background(), position(mmsi, 50.8, -1.3, 0)))
+	}
+	// held back by the throttle but still published on shutdown
+	require.NoError(t, s.Process(context.Background(), position(1, 50.8001, -1.3, 0)))
+
+	cancel()
+	close(producer.release)
+	result := s.Shutdown()
+
+	assert.Equal(t, DrainResult{Flushed: 11, Abandoned: 0}, result)
+	producer.mu.Lock()
+	assert.Len(t, producer.queue, 11)
+	producer.mu.Unlock()
+
+	// reports processed after shutting down are dropped rather than published
+	require.NoError(t, s.Process(context.Background(), position(11, 50.8, -1.3, 0)))
+	require.NoError(t, s.ProcessSafetyAlert(context.Background(), domain.SafetyAlert{MMSI: 2320001, Kind: domain.SafetyAlertKindBroadcast}))
+	metrics.mu.Lock()
+	assert.Equal(t, 2, metrics.dropped[DropReasonShutdown])
+	metrics.mu.Unlock()
+}
+
+func TestService_Shutdown_AbandonsReportsAfterDrainTimeout(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.CollectorWorkers = 1
+	cfg.CollectorDrainTimeout = 100 * time.Millisecond
+
+	producer := &BlockingProducer{release: make(chan struct{})}
+	s, err := New(context.Background(), *cfg, producer, &NoopMetricsClient{})
+	require.NoError(t, err)
+
+	for mmsi := int32(1); mmsi <= 10; mmsi++ {
+		require.NoError(t, s.Process(context.Background(), position(mmsi, 50.8, -1.3, 0)))
+	}
+
+	start := time.Now()
+	result := s.Shutdown()
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, DrainResult{Flushed: 0, Abandoned: 10}, result)
 }
@@ -0,0 +1,115 @@
+package collectorsrv
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+)
+
+// maxThrottleFlushInterval caps how often throttled positions are checked for being due to publish
+const maxThrottleFlushInterval = time.Second
+
+// throttleState tracks the positions of a single ship
+type throttleState struct {
+	published   domain.Ship
+	publishedAt time.Time
+	// pending is the latest position held back since the last one was published (nil if there is none)
+	pending *domain.Ship
+}
+
+// throttle limits each ship to publishing at most one position per window. A position is published within the window
+// regardless if the ship has moved more than the distance threshold or its navigational status has changed since the
+// last published position. Otherwise the latest position is held back and published once the window has elapsed.
+type throttle struct {
+	mu       sync.Mutex
+	states   map[int32]*throttleState
+	window   time.Duration
+	distance float64
+	now      func() time.Time
+}
+
+func newThrottle(window time.Duration, distance float64) *throttle {
+	return &throttle{
+		states:   make(map[int32]*throttleState),
+		window:   window,
+		distance: distance,
+		now:      time.Now,
+	}
+}
+
+// admit reports whether the position should be published now. Positions that are not admitted are held back until
+// they are returned by flush (unless superseded by a later position).
+func (t *throttle) admit(ship domain.Ship) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	state, ok := t.states[ship.MMSI]
+	if !ok || now.Sub(state.publishedAt) >= t.window || t.isSignificant(state.published, ship) {
+		t.states[ship.MMSI] = &throttleState{published: ship, publishedAt: now}
+		return true
+	}
+
+	state.pending = &ship
+	return false
+}
+
+// flush returns the held back positions whose window has elapsed and forgets ships that have no positions held back,
+// as their next position will be published straight away in any case
+func (t *throttle) flush() []domain.Ship {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	var due []domain.Ship
+	for mmsi, state := range t.states {
+		if now.Sub(state.publishedAt) < t.window {
+			continue
+		}
+		if state.pending == nil {
+			delete(t.states, mmsi)
+			continue
+		}
+		due = append(due, *state.pending)
+		t.states[mmsi] = &throttleState{published: *state.pending, publishedAt: now}
+	}
+	return due
+}
+
+// flushAll returns every held back position regardless of whether its window has elapsed
+func (t *throttle) flushAll() []domain.Ship {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var pending []domain.Ship
+	for mmsi, state := range t.states {
+		if state.pending != nil {
+			pending = append(pending, *state.pending)
+		}
+		delete(t.states, mmsi)
+	}
+	return pending
+}
+
+// flushInterval is how often flush should be called
+func (t *throttle) flushInterval() time.Duration {
+	if t.window < maxThrottleFlushInterval {
+		return t.window
+	}
+	return maxThrottleFlushInterval
+}
+
+func (t *throttle) isSignificant(published, ship domain.Ship) bool {
+	if !sameNavigationalStatus(published.NavigationalStatus, ship.NavigationalStatus) {
+		return true
+	}
+	return domain.Distance(published.Latitude, published.Longitude, ship.Latitude, ship.Longitude) > t.distance
+}
+
+func sameNavigationalStatus(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,102 @@
+package collectorsrv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestThrottle(window time.Duration, distance float64) (*throttle, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2023, time.September, 11, 17, 0, 0, 0, time.UTC)}
+	t := newThrottle(window, distance)
+	t.now = clock.Now
+	return t, clock
+}
+
+func position(mmsi int32, latitude, longitude float64, navigationalStatus int32) domain.Ship {
+	ship := domain.NewShip(mmsi, "", latitude, longitude, time.Now())
+	ship.Kinematics = domain.NewKinematics(12.3, 308, 235, navigationalStatus, 0)
+	return *ship
+}
+
+func TestThrottle_Admit(t *testing.T) {
+	throttle, clock := newTestThrottle(10*time.Second, 50)
+
+	assert.True(t, throttle.admit(position(1, 50.8, -1.3, 0)), "first position for a ship is published")
+	assert.True(t, throttle.admit(position(2, 50.8, -1.3, 0)), "ships are throttled independently")
+
+	clock.now = clock.now.Add(time.Second)
+	assert.False(t, throttle.admit(position(1, 50.8001, -1.3, 0)), "small movement within the window is held back")
+	assert.True(t, throttle.admit(position(1, 50.801, -1.3, 0)), "movement over the threshold is published")
+	assert.True(t, throttle.admit(position(1, 50.801, -1.3, 5)), "change of navigational status is published")
+
+	clock.now = clock.now.Add(10 * time.Second)
+	assert.True(t, throttle.admit(position(1, 50.801, -1.3, 5)), "position after the window is published")
+}
+
+func TestThrottle_Flush(t *testing.T) {
+	throttle, clock := newTestThrottle(10*time.Second, 50)
+
+	require.True(t, throttle.admit(position(1, 50.8, -1.3, 0)))
+	require.True(t, throttle.admit(position(2, 50.8, -1.3, 0)))
+	clock.now = clock.now.Add(time.Second)
+	require.False(t, throttle.admit(position(1, 50.8001, -1.3, 0)))
+	require.False(t, throttle.admit(position(1, 50.8002, -1.3, 0)))
+	assert.Empty(t, throttle.flush(), "nothing is due within the window")
+
+	clock.now = clock.now.Add(10 * time.Second)
+	due := throttle.flush()
+	require.Len(t, due, 1, "only the latest held back position is published")
+	assert.Equal(t, 50.8002, due[0].Latitude)
+	assert.Len(t, throttle.states, 1, "ships with nothing held back are forgotten")
+
+	clock.now = clock.now.Add(time.Second)
+	assert.False(t, throttle.admit(position(1, 50.8002, -1.3, 0)), "flushed position starts a new window")
+}
+
+func TestService_ThrottlesPositions(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.CollectorThrottleWindow = 100 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockProducer := &MockProducer{}
+	metrics := &RecordingMetricsClient{}
+	s, err := New(ctx, *cfg, mockProducer, metrics)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Process(context.Background(), position(1, 50.8, -1.3, 0)))
+	require.NoError(t, s.Process(context.Background(), position(1, 50.8001, -1.3, 0)))
+	require.NoError(t, s.Process(context.Background(), position(1, 50.8002, -1.3, 0)))
+
+	require.Eventually(t, func() bool {
+		mockProducer.mu.Lock()
+		defer mockProducer.mu.Unlock()
+		return len(mockProducer.queue) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mockProducer.mu.Lock()
+	assert.Equal(t, 50.8002, mockProducer.queue[1].Latitude)
+	mockProducer.mu.Unlock()
+	metrics.mu.Lock()
+	assert.Equal(t, 2, metrics.throttled)
+	metrics.mu.Unlock()
+
+	cancel()
+	s.Shutdown()
+}
@@ -0,0 +1,119 @@
+package safetysrv
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/internal/core/ports"
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+)
+
+// subscriberBufferSize is the number of alerts that can wait to be read by a subscriber before alerts are dropped for it
+const subscriberBufferSize = 16
+
+// notifiedCacheSize is the number of recently notified alerts remembered so that redelivered alerts aren't passed to
+// the subscribers again
+const notifiedCacheSize = 1000
+
+// alertKey identifies an alert in the same way as the repository does when ignoring redelivered alerts
+type alertKey struct {
+	mmsi            int32
+	kind            domain.SafetyAlertKind
+	destinationMMSI int32
+	transmittedAt   int64
+}
+
+func newAlertKey(alert domain.SafetyAlert) alertKey {
+	return alertKey{
+		mmsi:            alert.MMSI,
+		kind:            alert.Kind,
+		destinationMMSI: alert.DestinationMMSI,
+		transmittedAt:   alert.TransmittedAt.UnixNano(),
+	}
+}
+
+type Service struct {
+	repo ports.SafetyAlertRepository
+
+	mu          sync.Mutex
+	subscribers map[chan domain.SafetyAlert]struct{}
+	// notified holds the keys of the most recently notified alerts, oldest first, with notifiedKeys indexing them
+	notified     []alertKey
+	notifiedKeys map[alertKey]struct{}
+}
+
+func New(repo ports.SafetyAlertRepository) *Service {
+	return &Service{
+		repo:         repo,
+		subscribers:  make(map[chan domain.SafetyAlert]struct{}),
+		notifiedKeys: make(map[alertKey]struct{}),
+	}
+}
+
+func (s *Service) ListSafetyAlerts(ctx context.Context, since time.Time, limit int) ([]domain.SafetyAlert, error) {
+	return s.repo.ListSafetyAlerts(ctx, since, limit)
+}
+
+// StoreSafetyAlerts stores the alerts, ignoring any that have already been stored (e.g. because Kafka has redelivered
+// them)
+func (s *Service) StoreSafetyAlerts(ctx context.Context, alerts []domain.SafetyAlert) error {
+	if _, err := s.repo.StoreSafetyAlerts(ctx, alerts); err != nil {
+		return fmt.Errorf("failed to store safety alerts: %w", err)
+	}
+	return nil
+}
+
+// NotifySafetyAlerts passes the alerts to this instance's subscribers, skipping any that have recently been passed on
+// (e.g. because Kafka has redelivered them). Subscribers that are not keeping up miss out on alerts rather than holding
+// up the others.
+func (s *Service) NotifySafetyAlerts(alerts []domain.SafetyAlert) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var notify []domain.SafetyAlert
+	for _, alert := range alerts {
+		key := newAlertKey(alert)
+		if _, found := s.notifiedKeys[key]; found {
+			continue
+		}
+		if len(s.notified) == notifiedCacheSize {
+			delete(s.notifiedKeys, s.notified[0])
+			s.notified = s.notified[1:]
+		}
+		s.notified = append(s.notified, key)
+		s.notifiedKeys[key] = struct{}{}
+		notify = append(notify, alert)
+	}
+
+	for subscriber := range s.subscribers {
+		for _, alert := range notify {
+			select {
+			case subscriber <- alert:
+			default:
+				clog.Warnw("dropping safety alert for slow subscriber",
+					"mmsi", alert.MMSI,
+					"kind", alert.Kind)
+			}
+		}
+	}
+}
+
+func (s *Service) SubscribeSafetyAlerts(ctx context.Context) <-chan domain.SafetyAlert {
+	subscriber := make(chan domain.SafetyAlert, subscriberBufferSize)
+	s.mu.Lock()
+	s.subscribers[subscriber] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		delete(s.subscribers, subscriber)
+		close(subscriber)
+		s.mu.Unlock()
+	}()
+
+	return subscriber
+}
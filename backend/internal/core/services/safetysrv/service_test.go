@@ -0,0 +1,83 @@
+package safetysrv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+)
+
+type MockSafetyAlertRepository struct {
+	alerts []domain.SafetyAlert
+}
+
+func (m *MockSafetyAlertRepository) ListSafetyAlerts(_ context.Context, _ time.Time, _ int) ([]domain.SafetyAlert, error) {
+	return m.alerts, nil
+}
+
+func (m *MockSafetyAlertRepository) StoreSafetyAlerts(_ context.Context, alerts []domain.SafetyAlert) ([]domain.SafetyAlert, error) {
+	var stored []domain.SafetyAlert
+	for _, alert := range alerts {
+		if !m.contains(alert) {
+			m.alerts = append(m.alerts, alert)
+			stored = append(stored, alert)
+		}
+	}
+	return stored, nil
+}
+
+func (m *MockSafetyAlertRepository) contains(alert domain.SafetyAlert) bool {
+	for _, a := range m.alerts {
+		if a == alert {
+			return true
+		}
+	}
+	return false
+}
+
+func TestService_SubscribeSafetyAlerts(t *testing.T) {
+	repo := &MockSafetyAlertRepository{}
+	s := New(repo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscriber := s.SubscribeSafetyAlerts(ctx)
+	// a subscriber that never reads must not hold up the others
+	_ = s.SubscribeSafetyAlerts(context.Background())
+
+	alerts := make([]domain.SafetyAlert, subscriberBufferSize+1)
+	for i := range alerts {
+		alerts[i] = domain.SafetyAlert{MMSI: int32(972000000 + i), Kind: domain.SafetyAlertKindBeacon}
+	}
+	require.NoError(t, s.StoreSafetyAlerts(context.Background(), alerts[:1]))
+	s.NotifySafetyAlerts(alerts[:1])
+	assert.Equal(t, alerts[0], <-subscriber)
+
+	// the redelivered first alert is stored once and not passed to the subscribers again
+	require.NoError(t, s.StoreSafetyAlerts(context.Background(), alerts))
+	s.NotifySafetyAlerts(alerts)
+	assert.Len(t, repo.alerts, len(alerts))
+
+	cancel()
+	var received []domain.SafetyAlert
+	for alert := range subscriber {
+		received = append(received, alert)
+	}
+	assert.Equal(t, alerts[1:], received)
+}
+
+func TestService_NotifySafetyAlerts_StoredByAnotherInstance(t *testing.T) {
+	alert := domain.SafetyAlert{MMSI: 972000000, Kind: domain.SafetyAlertKindBeacon}
+	// the alert has already been stored by the instance in the shared consumer group that received it
+	s := New(&MockSafetyAlertRepository{alerts: []domain.SafetyAlert{alert}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	subscriber := s.SubscribeSafetyAlerts(ctx)
+
+	s.NotifySafetyAlerts([]domain.SafetyAlert{alert})
+	assert.Equal(t, alert, <-subscriber)
+}
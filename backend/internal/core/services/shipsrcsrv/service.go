@@ -2,6 +2,8 @@ package shipsrcsrv
 
 import (
 	"context"
+	"strings"
+
 	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
 	"github.com/mikeewhite/ship-locator/backend/internal/core/ports"
 )
@@ -14,8 +16,9 @@ func New(repo ports.ShipSearchRepository) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) Search(ctx context.Context, searchTerm string) ([]domain.ShipSearchResult, error) {
-	return s.repo.Search(ctx, searchTerm)
+func (s *Service) Search(ctx context.Context, searchTerm string, filter domain.ShipSearchFilter) ([]domain.ShipSearchResult, error) {
+	filter.Flag = strings.ToUpper(strings.TrimSpace(filter.Flag))
+	return s.repo.Search(ctx, searchTerm, filter)
 }
 
 func (s *Service) Store(ctx context.Context, ships []domain.ShipSearchResult) error {
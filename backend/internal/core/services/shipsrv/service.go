@@ -36,3 +36,10 @@ func (s *Service) Store(ctx context.Context, ships []domain.Ship) error {
 
 	return s.shipEventProducer.PublishShipLocationsUpdatedEvent(ctx, ships)
 }
+
+func (s *Service) StoreStaticData(ctx context.Context, data []domain.ShipStaticData) error {
+	if err := s.repo.StoreStaticData(ctx, data); err != nil {
+		return fmt.Errorf("failed to store ship static data: %w", err)
+	}
+	return nil
+}
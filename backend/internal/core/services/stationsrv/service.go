@@ -0,0 +1,70 @@
+package stationsrv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/internal/core/ports"
+)
+
+type Service struct {
+	repo          ports.StationRepository
+	searchService ports.ShipSearchService
+}
+
+func New(repo ports.StationRepository, searchService ports.ShipSearchService) *Service {
+	return &Service{
+		repo:          repo,
+		searchService: searchService,
+	}
+}
+
+func (s *Service) ListAidsToNavigation(ctx context.Context, bbox domain.BoundingBox) ([]domain.AidToNavigation, error) {
+	if err := bbox.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bounding box: %w", err)
+	}
+	return s.repo.ListAidsToNavigation(ctx, bbox)
+}
+
+func (s *Service) ListBaseStations(ctx context.Context) ([]domain.BaseStation, error) {
+	return s.repo.ListBaseStations(ctx)
+}
+
+// StoreAidsToNavigation stores the aids and indexes them so that they can be found by name or MMSI when searching for
+// aids to navigation
+func (s *Service) StoreAidsToNavigation(ctx context.Context, aids []domain.AidToNavigation) error {
+	if err := s.repo.StoreAidsToNavigation(ctx, aids); err != nil {
+		return fmt.Errorf("failed to store aids to navigation: %w", err)
+	}
+
+	results := make([]domain.ShipSearchResult, len(aids))
+	for i, aid := range aids {
+		results[i] = domain.ShipSearchResult{
+			MMSI:        aid.MMSI,
+			Name:        aid.Name,
+			StationType: domain.StationTypeAidToNavigation,
+		}
+	}
+	if err := s.searchService.Store(ctx, results); err != nil {
+		return fmt.Errorf("failed to index aids to navigation: %w", err)
+	}
+	return nil
+}
+
+// StoreBaseStations stores the base stations and indexes them so that they can be found by MMSI when searching for
+// coast stations
+func (s *Service) StoreBaseStations(ctx context.Context, stations []domain.BaseStation) error {
+	if err := s.repo.StoreBaseStations(ctx, stations); err != nil {
+		return fmt.Errorf("failed to store base stations: %w", err)
+	}
+
+	results := make([]domain.ShipSearchResult, len(stations))
+	for i, station := range stations {
+		results[i] = domain.ShipSearchResult{MMSI: station.MMSI, StationType: domain.StationTypeCoastStation}
+	}
+	if err := s.searchService.Store(ctx, results); err != nil {
+		return fmt.Errorf("failed to index base stations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package stationsrv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+)
+
+type MockStationRepository struct{}
+
+func (m *MockStationRepository) ListAidsToNavigation(_ context.Context,
+	_ domain.BoundingBox) ([]domain.AidToNavigation, error) {
+	return nil, nil
+}
+
+func (m *MockStationRepository) ListBaseStations(_ context.Context) ([]domain.BaseStation, error) {
+	return nil, nil
+}
+
+func (m *MockStationRepository) StoreAidsToNavigation(_ context.Context, _ []domain.AidToNavigation) error {
+	return nil
+}
+
+func (m *MockStationRepository) StoreBaseStations(_ context.Context, _ []domain.BaseStation) error {
+	return nil
+}
+
+// MockShipSearchService holds the indexed results and, like the search index, leaves out the excluded station types
+type MockShipSearchService struct {
+	indexed []domain.ShipSearchResult
+}
+
+func (m *MockShipSearchService) Search(_ context.Context, _ string,
+	filter domain.ShipSearchFilter) ([]domain.ShipSearchResult, error) {
+	var results []domain.ShipSearchResult
+	for _, result := range m.indexed {
+		excluded := false
+		for _, stationType := range filter.ExcludedStationTypes() {
+			excluded = excluded || result.StationType == stationType
+		}
+		if !excluded && (filter.StationType == "" || filter.StationType == result.StationType) {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func (m *MockShipSearchService) Store(_ context.Context, ships []domain.ShipSearchResult) error {
+	m.indexed = append(m.indexed, ships...)
+	return nil
+}
+
+func TestService_StationsAreNotReturnedByShipSearch(t *testing.T) {
+	searchService := &MockShipSearchService{}
+	s := New(&MockStationRepository{}, searchService)
+
+	require.NoError(t, s.StoreAidsToNavigation(context.Background(),
+		[]domain.AidToNavigation{{MMSI: 992351000, Name: "BRAMBLE BANK"}}))
+	require.NoError(t, s.StoreBaseStations(context.Background(), []domain.BaseStation{{MMSI: 2320001}}))
+
+	ships, err := searchService.Search(context.Background(), "BRAMBLE", domain.ShipSearchFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, ships)
+
+	aids, err := searchService.Search(context.Background(), "BRAMBLE",
+		domain.ShipSearchFilter{StationType: domain.StationTypeAidToNavigation})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ShipSearchResult{
+		{MMSI: 992351000, Name: "BRAMBLE BANK", StationType: domain.StationTypeAidToNavigation},
+	}, aids)
+}
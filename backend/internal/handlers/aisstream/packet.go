@@ -0,0 +1,434 @@
+package aisstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/internal/core/ports"
+	"github.com/mikeewhite/ship-locator/backend/pkg/apperrors"
+)
+
+const (
+	MessageTypePositionReport               = "PositionReport"
+	MessageTypeStandardClassBPositionReport = "StandardClassBPositionReport"
+	MessageTypeExtendedClassBPositionReport = "ExtendedClassBPositionReport"
+	MessageTypeShipStaticData               = "ShipStaticData"
+	MessageTypeBaseStationReport            = "BaseStationReport"
+	MessageTypeAidsToNavigationReport       = "AidsToNavigationReport"
+	MessageTypeAddressedSafetyMessage       = "AddressedSafetyMessage"
+	MessageTypeSafetyBroadcastMessage       = "SafetyBroadcastMessage"
+
+	metaDataTimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+)
+
+// Reasons for rejecting a packet
+const (
+	RejectReasonUnmarshal          = "unmarshal_error"
+	RejectReasonMissingMessage     = "missing_message"
+	RejectReasonInvalidPosition    = "invalid_position_report"
+	RejectReasonInvalidStaticData  = "invalid_static_data"
+	RejectReasonInvalidAidToNav    = "invalid_aid_to_navigation"
+	RejectReasonInvalidBaseStation = "invalid_base_station"
+	RejectReasonInvalidSafetyAlert = "invalid_safety_alert"
+	// RejectReasonNotAccepted is used for valid reports that the collector service couldn't accept (e.g. because its
+	// queue was full or it was shutting down)
+	RejectReasonNotAccepted = "not_accepted"
+)
+
+// RejectError indicates that a packet was rejected and gives the reason why
+type RejectError struct {
+	Reason string
+	Err    error
+}
+
+func (e *RejectError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Err.Error())
+}
+
+func (e *RejectError) Unwrap() error {
+	return e.Err
+}
+
+type AISPacket struct {
+	MetaData    map[string]interface{} `json:"MetaData"`
+	MessageType string                 `json:"MessageType"`
+	Message     AISPacketMessage       `json:"Message"`
+}
+
+// ReceivedAt returns the time aisstream received the packet (falling back to the given time if it is not present)
+func (p *AISPacket) ReceivedAt(fallback time.Time) time.Time {
+	if timeUTC, ok := p.MetaData["time_utc"].(string); ok {
+		if t, err := time.Parse(metaDataTimeLayout, timeUTC); err == nil {
+			return t.UTC()
+		}
+	}
+	return fallback.UTC()
+}
+
+type AISPacketMessage struct {
+	PositionReport               *PositionReport               `json:"PositionReport,omitempty"`
+	StandardClassBPositionReport *StandardClassBPositionReport `json:"StandardClassBPositionReport,omitempty"`
+	ExtendedClassBPositionReport *ExtendedClassBPositionReport `json:"ExtendedClassBPositionReport,omitempty"`
+	ShipStaticData               *ShipStaticData               `json:"ShipStaticData,omitempty"`
+	BaseStationReport            *BaseStationReport            `json:"BaseStationReport,omitempty"`
+	AidsToNavigationReport       *AidsToNavigationReport       `json:"AidsToNavigationReport,omitempty"`
+	AddressedSafetyMessage       *AddressedSafetyMessage       `json:"AddressedSafetyMessage,omitempty"`
+	SafetyBroadcastMessage       *SafetyBroadcastMessage       `json:"SafetyBroadcastMessage,omitempty"`
+}
+
+type PositionReport struct {
+	MessageID                 int32   `json:"MessageID"`
+	RepeatIndicator           int32   `json:"RepeatIndicator"`
+	UserID                    int32   `json:"UserID"`
+	Valid                     bool    `json:"Valid"`
+	NavigationalStatus        int32   `json:"NavigationalStatus"`
+	RateOfTurn                int32   `json:"RateOfTurn"`
+	Sog                       float64 `json:"Sog"`
+	PositionAccuracy          bool    `json:"PositionAccuracy"`
+	Longitude                 float64 `json:"Longitude"`
+	Latitude                  float64 `json:"Latitude"`
+	Cog                       float64 `json:"Cog"`
+	TrueHeading               int32   `json:"TrueHeading"`
+	Timestamp                 int32   `json:"Timestamp"`
+	SpecialManoeuvreIndicator int32   `json:"SpecialManoeuvreIndicator"`
+	Spare                     int32   `json:"Spare"`
+	Raim                      bool    `json:"Raim"`
+	CommunicationState        int32   `json:"CommunicationState"`
+}
+
+// StandardClassBPositionReport is a Class B position report (AIS message type 18)
+type StandardClassBPositionReport struct {
+	MessageID                 int32   `json:"MessageID"`
+	RepeatIndicator           int32   `json:"RepeatIndicator"`
+	UserID                    int32   `json:"UserID"`
+	Valid                     bool    `json:"Valid"`
+	Spare1                    int32   `json:"Spare1"`
+	Sog                       float64 `json:"Sog"`
+	PositionAccuracy          bool    `json:"PositionAccuracy"`
+	Longitude                 float64 `json:"Longitude"`
+	Latitude                  float64 `json:"Latitude"`
+	Cog                       float64 `json:"Cog"`
+	TrueHeading               int32   `json:"TrueHeading"`
+	Timestamp                 int32   `json:"Timestamp"`
+	Spare2                    int32   `json:"Spare2"`
+	ClassBUnit                bool    `json:"ClassBUnit"`
+	ClassBDisplay             bool    `json:"ClassBDisplay"`
+	ClassBDsc                 bool    `json:"ClassBDsc"`
+	ClassBBand                bool    `json:"ClassBBand"`
+	ClassBMsg22               bool    `json:"ClassBMsg22"`
+	AssignedMode              bool    `json:"AssignedMode"`
+	Raim                      bool    `json:"Raim"`
+	CommunicationStateIsItdma bool    `json:"CommunicationStateIsItdma"`
+	CommunicationState        int32   `json:"CommunicationState"`
+}
+
+// ExtendedClassBPositionReport is a Class B position report that also carries static data (AIS message type 19)
+type ExtendedClassBPositionReport struct {
+	MessageID        int32     `json:"MessageID"`
+	RepeatIndicator  int32     `json:"RepeatIndicator"`
+	UserID           int32     `json:"UserID"`
+	Valid            bool      `json:"Valid"`
+	Spare1           int32     `json:"Spare1"`
+	Sog              float64   `json:"Sog"`
+	PositionAccuracy bool      `json:"PositionAccuracy"`
+	Longitude        float64   `json:"Longitude"`
+	Latitude         float64   `json:"Latitude"`
+	Cog              float64   `json:"Cog"`
+	TrueHeading      int32     `json:"TrueHeading"`
+	Timestamp        int32     `json:"Timestamp"`
+	Spare2           int32     `json:"Spare2"`
+	Name             string    `json:"Name"`
+	Type             int32     `json:"Type"`
+	Dimension        Dimension `json:"Dimension"`
+	FixType          int32     `json:"FixType"`
+	Raim             bool      `json:"Raim"`
+	Dte              bool      `json:"Dte"`
+	AssignedMode     bool      `json:"AssignedMode"`
+	Spare3           int32     `json:"Spare3"`
+}
+
+type ShipStaticData struct {
+	MessageID            int32     `json:"MessageID"`
+	RepeatIndicator      int32     `json:"RepeatIndicator"`
+	UserID               int32     `json:"UserID"`
+	Valid                bool      `json:"Valid"`
+	AisVersion           int32     `json:"AisVersion"`
+	ImoNumber            int32     `json:"ImoNumber"`
+	CallSign             string    `json:"CallSign"`
+	Name                 string    `json:"Name"`
+	Type                 int32     `json:"Type"`
+	Dimension            Dimension `json:"Dimension"`
+	FixType              int32     `json:"FixType"`
+	Eta                  Eta       `json:"Eta"`
+	MaximumStaticDraught float64   `json:"MaximumStaticDraught"`
+	Destination          string    `json:"Destination"`
+	Dte                  bool      `json:"Dte"`
+	Spare                bool      `json:"Spare"`
+}
+
+// BaseStationReport is a report from a fixed shore station (AIS message type 4)
+type BaseStationReport struct {
+	MessageID          int32   `json:"MessageID"`
+	RepeatIndicator    int32   `json:"RepeatIndicator"`
+	UserID             int32   `json:"UserID"`
+	Valid              bool    `json:"Valid"`
+	UtcYear            int32   `json:"UtcYear"`
+	UtcMonth           int32   `json:"UtcMonth"`
+	UtcDay             int32   `json:"UtcDay"`
+	UtcHour            int32   `json:"UtcHour"`
+	UtcMinute          int32   `json:"UtcMinute"`
+	UtcSecond          int32   `json:"UtcSecond"`
+	PositionAccuracy   bool    `json:"PositionAccuracy"`
+	Longitude          float64 `json:"Longitude"`
+	Latitude           float64 `json:"Latitude"`
+	FixType            int32   `json:"FixType"`
+	LongRangeEnable    bool    `json:"LongRangeEnable"`
+	Spare              int32   `json:"Spare"`
+	Raim               bool    `json:"Raim"`
+	CommunicationState int32   `json:"CommunicationState"`
+}
+
+// AidsToNavigationReport is a report from an aid to navigation such as a buoy or lighthouse (AIS message type 21)
+type AidsToNavigationReport struct {
+	MessageID        int32     `json:"MessageID"`
+	RepeatIndicator  int32     `json:"RepeatIndicator"`
+	UserID           int32     `json:"UserID"`
+	Valid            bool      `json:"Valid"`
+	Type             int32     `json:"Type"`
+	Name             string    `json:"Name"`
+	PositionAccuracy bool      `json:"PositionAccuracy"`
+	Longitude        float64   `json:"Longitude"`
+	Latitude         float64   `json:"Latitude"`
+	Dimension        Dimension `json:"Dimension"`
+	Fixtype          int32     `json:"Fixtype"`
+	Timestamp        int32     `json:"Timestamp"`
+	OffPosition      bool      `json:"OffPosition"`
+	AtoN             int32     `json:"AtoN"`
+	Raim             bool      `json:"Raim"`
+	VirtualAtoN      bool      `json:"VirtualAtoN"`
+	AssignedMode     bool      `json:"AssignedMode"`
+	Spare            bool      `json:"Spare"`
+	NameExtension    string    `json:"NameExtension"`
+}
+
+// AddressedSafetyMessage is a safety related text message addressed to a single station (AIS message type 12)
+type AddressedSafetyMessage struct {
+	MessageID       int32  `json:"MessageID"`
+	RepeatIndicator int32  `json:"RepeatIndicator"`
+	UserID          int32  `json:"UserID"`
+	Valid           bool   `json:"Valid"`
+	Sequenceinteger int32  `json:"Sequenceinteger"`
+	DestinationID   int32  `json:"DestinationID"`
+	Retransmission  bool   `json:"Retransmission"`
+	Spare           bool   `json:"Spare"`
+	Text            string `json:"Text"`
+}
+
+// SafetyBroadcastMessage is a safety related text message broadcast to all stations (AIS message type 14)
+type SafetyBroadcastMessage struct {
+	MessageID       int32  `json:"MessageID"`
+	RepeatIndicator int32  `json:"RepeatIndicator"`
+	UserID          int32  `json:"UserID"`
+	Valid           bool   `json:"Valid"`
+	Spare           int32  `json:"Spare"`
+	Text            string `json:"Text"`
+}
+
+// Dimension holds the distances in metres from the ship's position reference point to the bow (A), stern (B),
+// port (C) and starboard (D)
+type Dimension struct {
+	A int32 `json:"A"`
+	B int32 `json:"B"`
+	C int32 `json:"C"`
+	D int32 `json:"D"`
+}
+
+type Eta struct {
+	Month  int32 `json:"Month"`
+	Day    int32 `json:"Day"`
+	Hour   int32 `json:"Hour"`
+	Minute int32 `json:"Minute"`
+}
+
+// ParsePacket unmarshals a raw packet
+func ParsePacket(frame []byte) (AISPacket, error) {
+	var packet AISPacket
+	if err := json.Unmarshal(frame, &packet); err != nil {
+		return AISPacket{}, &RejectError{Reason: RejectReasonUnmarshal, Err: fmt.Errorf("error on unmarshalling packet: %w", err)}
+	}
+	return packet, nil
+}
+
+// ProcessPacket converts a packet into domain entities and passes them to the collector service. Packets of any other
+// message type are ignored. A RejectError is returned if the packet is rejected.
+func ProcessPacket(ctx context.Context, collectorService ports.CollectorService, packet AISPacket, now time.Time) error {
+	var shipName string
+	if packetShipName, ok := packet.MetaData["ShipName"].(string); ok {
+		shipName = packetShipName
+	}
+
+	packetReceivedAt := packet.ReceivedAt(now)
+
+	switch packet.MessageType {
+	case MessageTypePositionReport:
+		if packet.Message.PositionReport == nil {
+			return &RejectError{Reason: RejectReasonMissingMessage, Err: errors.New("packet is missing its position report")}
+		}
+		report := *packet.Message.PositionReport
+		ship := newShip(report.UserID, shipName, report.Latitude, report.Longitude, report.Timestamp, packetReceivedAt, now)
+		ship.Kinematics = domain.NewKinematics(report.Sog, report.Cog, report.TrueHeading, report.NavigationalStatus,
+			report.RateOfTurn)
+		ship.TransponderClass = domain.TransponderClassA
+		return processShip(ctx, collectorService, ship)
+	case MessageTypeStandardClassBPositionReport:
+		if packet.Message.StandardClassBPositionReport == nil {
+			return &RejectError{Reason: RejectReasonMissingMessage, Err: errors.New("packet is missing its Class B position report")}
+		}
+		report := *packet.Message.StandardClassBPositionReport
+		ship := newShip(report.UserID, shipName, report.Latitude, report.Longitude, report.Timestamp, packetReceivedAt, now)
+		ship.Kinematics = domain.NewClassBKinematics(report.Sog, report.Cog, report.TrueHeading)
+		ship.TransponderClass = domain.TransponderClassB
+		return processShip(ctx, collectorService, ship)
+	case MessageTypeExtendedClassBPositionReport:
+		if packet.Message.ExtendedClassBPositionReport == nil {
+			return &RejectError{Reason: RejectReasonMissingMessage, Err: errors.New("packet is missing its extended Class B position report")}
+		}
+		report := *packet.Message.ExtendedClassBPositionReport
+		// the extended report carries the ship's name so it is preferred over the name in the metadata
+		name := shipName
+		if trimmed := strings.TrimSpace(strings.TrimRight(report.Name, "@")); trimmed != "" {
+			name = trimmed
+		}
+		ship := newShip(report.UserID, name, report.Latitude, report.Longitude, report.Timestamp, packetReceivedAt, now)
+		ship.Kinematics = domain.NewClassBKinematics(report.Sog, report.Cog, report.TrueHeading)
+		ship.TransponderClass = domain.TransponderClassB
+		return processShip(ctx, collectorService, ship)
+	case MessageTypeShipStaticData:
+		if packet.Message.ShipStaticData == nil {
+			return &RejectError{Reason: RejectReasonMissingMessage, Err: errors.New("packet is missing its ship static data")}
+		}
+		staticData := *packet.Message.ShipStaticData
+		err := collectorService.ProcessStaticData(ctx, domain.ShipStaticData{
+			MMSI:                 staticData.UserID,
+			Name:                 staticData.Name,
+			IMONumber:            staticData.ImoNumber,
+			CallSign:             staticData.CallSign,
+			ShipType:             staticData.Type,
+			DimensionToBow:       staticData.Dimension.A,
+			DimensionToStern:     staticData.Dimension.B,
+			DimensionToPort:      staticData.Dimension.C,
+			DimensionToStarboard: staticData.Dimension.D,
+			Draught:              staticData.MaximumStaticDraught,
+			Destination:          staticData.Destination,
+			ETA:                  domain.NewETA(staticData.Eta.Month, staticData.Eta.Day, staticData.Eta.Hour, staticData.Eta.Minute, packetReceivedAt),
+			LastUpdated:          packetReceivedAt,
+		})
+		if err != nil {
+			return &RejectError{Reason: rejectReason(err, RejectReasonInvalidStaticData), Err: fmt.Errorf("error on processing static data: %w", err)}
+		}
+	case MessageTypeAidsToNavigationReport:
+		if packet.Message.AidsToNavigationReport == nil {
+			return &RejectError{Reason: RejectReasonMissingMessage, Err: errors.New("packet is missing its aid to navigation report")}
+		}
+		report := *packet.Message.AidsToNavigationReport
+		err := collectorService.ProcessAidToNavigation(ctx, domain.AidToNavigation{
+			MMSI: report.UserID,
+			// names longer than 20 characters are continued in the name extension
+			Name:                 strings.TrimRight(report.Name+report.NameExtension, "@ "),
+			AidType:              report.Type,
+			Latitude:             report.Latitude,
+			Longitude:            report.Longitude,
+			DimensionToBow:       report.Dimension.A,
+			DimensionToStern:     report.Dimension.B,
+			DimensionToPort:      report.Dimension.C,
+			DimensionToStarboard: report.Dimension.D,
+			VirtualAid:           report.VirtualAtoN,
+			OffPosition:          report.OffPosition,
+			LastUpdated:          domain.ObservationTime(packetReceivedAt, report.Timestamp),
+			ReceivedAt:           now,
+		})
+		if err != nil {
+			return &RejectError{Reason: rejectReason(err, RejectReasonInvalidAidToNav), Err: fmt.Errorf("error on processing aid to navigation report: %w", err)}
+		}
+	case MessageTypeBaseStationReport:
+		if packet.Message.BaseStationReport == nil {
+			return &RejectError{Reason: RejectReasonMissingMessage, Err: errors.New("packet is missing its base station report")}
+		}
+		report := *packet.Message.BaseStationReport
+		err := collectorService.ProcessBaseStation(ctx, domain.BaseStation{
+			MMSI:      report.UserID,
+			Latitude:  report.Latitude,
+			Longitude: report.Longitude,
+			LastUpdated: domain.BaseStationTime(report.UtcYear, report.UtcMonth, report.UtcDay, report.UtcHour,
+				report.UtcMinute, report.UtcSecond, packetReceivedAt),
+			ReceivedAt: now.UTC(),
+		})
+		if err != nil {
+			return &RejectError{Reason: rejectReason(err, RejectReasonInvalidBaseStation), Err: fmt.Errorf("error on processing base station report: %w", err)}
+		}
+	case MessageTypeAddressedSafetyMessage:
+		if packet.Message.AddressedSafetyMessage == nil {
+			return &RejectError{Reason: RejectReasonMissingMessage, Err: errors.New("packet is missing its addressed safety message")}
+		}
+		msg := *packet.Message.AddressedSafetyMessage
+		return processSafetyAlert(ctx, collectorService, domain.SafetyAlert{
+			MMSI:            msg.UserID,
+			Kind:            domain.SafetyAlertKindAddressed,
+			DestinationMMSI: msg.DestinationID,
+			Text:            msg.Text,
+			TransmittedAt:   packetReceivedAt,
+			ReceivedAt:      now,
+		})
+	case MessageTypeSafetyBroadcastMessage:
+		if packet.Message.SafetyBroadcastMessage == nil {
+			return &RejectError{Reason: RejectReasonMissingMessage, Err: errors.New("packet is missing its safety broadcast message")}
+		}
+		msg := *packet.Message.SafetyBroadcastMessage
+		return processSafetyAlert(ctx, collectorService, domain.SafetyAlert{
+			MMSI:          msg.UserID,
+			Kind:          domain.SafetyAlertKindBroadcast,
+			Text:          msg.Text,
+			TransmittedAt: packetReceivedAt,
+			ReceivedAt:    now,
+		})
+	}
+
+	return nil
+}
+
+// newShip creates a ship from a position report. The observation time is derived from the time aisstream received the
+// packet and the UTC second reported by the transponder.
+func newShip(mmsi int32, name string, latitude, longitude float64, utcSecond int32, packetReceivedAt, now time.Time) *domain.Ship {
+	observedAt := domain.ObservationTime(packetReceivedAt, utcSecond)
+	ship := domain.NewShip(mmsi, name, latitude, longitude, observedAt)
+	ship.ReceivedAt = now.UTC()
+	return ship
+}
+
+func processSafetyAlert(ctx context.Context, collectorService ports.CollectorService, alert domain.SafetyAlert) error {
+	if err := collectorService.ProcessSafetyAlert(ctx, alert); err != nil {
+		return &RejectError{Reason: rejectReason(err, RejectReasonInvalidSafetyAlert), Err: fmt.Errorf("error on processing safety message: %w", err)}
+	}
+	return nil
+}
+
+// rejectReason returns the reason for rejecting a report that the collector service returned an error for, which is
+// invalidReason only if the report failed validation
+func rejectReason(err error, invalidReason string) string {
+	if errors.Is(err, apperrors.ErrInvalidReport) {
+		return invalidReason
+	}
+	return RejectReasonNotAccepted
+}
+
+func processShip(ctx context.Context, collectorService ports.CollectorService, ship *domain.Ship) error {
+	if err := collectorService.Process(ctx, *ship); err != nil {
+		return &RejectError{Reason: rejectReason(err, RejectReasonInvalidPosition), Err: fmt.Errorf("error on processing position report: %w", err)}
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package aisstream
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/apperrors"
+)
+
+type MockCollectorService struct {
+	// err is returned for every valid report, e.g. as if the collector service's queue was full
+	err              error
+	ships            []domain.Ship
+	staticData       []domain.ShipStaticData
+	aidsToNavigation []domain.AidToNavigation
+	baseStations     []domain.BaseStation
+	safetyAlerts     []domain.SafetyAlert
+}
+
+func (m *MockCollectorService) Process(_ context.Context, ship domain.Ship) error {
+	if err := ship.Validate(); err != nil {
+		return apperrors.NewInvalidReportErr(err)
+	}
+	if m.err != nil {
+		return m.err
+	}
+	m.ships = append(m.ships, ship)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessStaticData(_ context.Context, data domain.ShipStaticData) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.staticData = append(m.staticData, data)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessAidToNavigation(_ context.Context, aid domain.AidToNavigation) error {
+	if err := aid.Validate(); err != nil {
+		return apperrors.NewInvalidReportErr(err)
+	}
+	if m.err != nil {
+		return m.err
+	}
+	m.aidsToNavigation = append(m.aidsToNavigation, aid)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessBaseStation(_ context.Context, station domain.BaseStation) error {
+	if err := station.Validate(); err != nil {
+		return apperrors.NewInvalidReportErr(err)
+	}
+	if m.err != nil {
+		return m.err
+	}
+	m.baseStations = append(m.baseStations, station)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessSafetyAlert(_ context.Context, alert domain.SafetyAlert) error {
+	alert.Normalise()
+	if err := alert.Validate(); err != nil {
+		return apperrors.NewInvalidReportErr(err)
+	}
+	if m.err != nil {
+		return m.err
+	}
+	m.safetyAlerts = append(m.safetyAlerts, alert)
+	return nil
+}
+
+func TestProcessPacket_StandardClassBPositionReport(t *testing.T) {
+	frame, err := os.ReadFile("../../../testdata/ais_class_b_position_report.json")
+	require.NoError(t, err)
+	packet, err := ParsePacket(frame)
+	require.NoError(t, err)
+
+	collectorService := &MockCollectorService{}
+	require.NoError(t, ProcessPacket(context.Background(), collectorService, packet, time.Now()))
+
+	require.Len(t, collectorService.ships, 1)
+	ship := collectorService.ships[0]
+	assert.Equal(t, int32(235000000), ship.MMSI)
+	assert.Equal(t, "SEA BREEZE", ship.Name)
+	assert.Equal(t, 50.80245, ship.Latitude)
+	assert.Equal(t, -1.298105, ship.Longitude)
+	assert.Equal(t, domain.TransponderClassB, ship.TransponderClass)
+	assert.Equal(t, domain.NewClassBKinematics(5.5, 90.4, 511), ship.Kinematics)
+	assert.Equal(t, time.Date(2022, time.December, 29, 18, 22, 12, 0, time.UTC), ship.LastUpdated)
+}
+
+func TestProcessPacket_ExtendedClassBPositionReport(t *testing.T) {
+	packet, err := ParsePacket([]byte(`{
+		"Message": {
+			"ExtendedClassBPositionReport": {
+				"UserID": 235000001,
+				"Sog": 3.2,
+				"Latitude": 50.9,
+				"Longitude": -1.4,
+				"Cog": 180,
+				"TrueHeading": 179,
+				"Timestamp": 40,
+				"Name": "LITTLE DIPPER@@@@@@@",
+				"Type": 37
+			}
+		},
+		"MessageType": "ExtendedClassBPositionReport",
+		"MetaData": {"MMSI": 235000001, "ShipName": "", "time_utc": "2022-12-29 18:22:45.5 +0000 UTC"}
+	}`))
+	require.NoError(t, err)
+
+	collectorService := &MockCollectorService{}
+	require.NoError(t, ProcessPacket(context.Background(), collectorService, packet, time.Now()))
+
+	require.Len(t, collectorService.ships, 1)
+	ship := collectorService.ships[0]
+	assert.Equal(t, int32(235000001), ship.MMSI)
+	assert.Equal(t, "LITTLE DIPPER", ship.Name)
+	assert.Equal(t, domain.TransponderClassB, ship.TransponderClass)
+	require.NotNil(t, ship.TrueHeading)
+	assert.Equal(t, int32(179), *ship.TrueHeading)
+	assert.Nil(t, ship.NavigationalStatus)
+}
+
+func TestProcessPacket_ClassAPositionReport(t *testing.T) {
+	frame, err := os.ReadFile("../../../testdata/ais_data.json")
+	require.NoError(t, err)
+	packet, err := ParsePacket(frame)
+	require.NoError(t, err)
+
+	collectorService := &MockCollectorService{}
+	require.NoError(t, ProcessPacket(context.Background(), collectorService, packet, time.Now()))
+
+	require.Len(t, collectorService.ships, 1)
+	assert.Equal(t, domain.TransponderClassA, collectorService.ships[0].TransponderClass)
+}
+
+func TestProcessPacket_AidsToNavigationReport(t *testing.T) {
+	packet, err := ParsePacket([]byte(`{
+		"Message": {
+			"AidsToNavigationReport": {
+				"UserID": 992351000,
+				"Type": 20,
+				"Name": "BRAMBLE BANK NORTH C",
+				"NameExtension": "ARDINAL",
+				"Latitude": 50.79,
+				"Longitude": -1.29,
+				"Dimension": {"A": 1, "B": 1, "C": 1, "D": 1},
+				"Timestamp": 30,
+				"VirtualAtoN": true
+			}
+		},
+		"MessageType": "AidsToNavigationReport",
+		"MetaData": {"MMSI": 992351000, "ShipName": "BRAMBLE BANK NORTH C", "time_utc": "2023-09-11 17:04:45.5 +0000 UTC"}
+	}`))
+	require.NoError(t, err)
+
+	collectorService := &MockCollectorService{}
+	now := time.Date(2023, time.September, 11, 17, 4, 46, 0, time.UTC)
+	require.NoError(t, ProcessPacket(context.Background(), collectorService, packet, now))
+
+	require.Len(t, collectorService.aidsToNavigation, 1)
+	assert.Equal(t, domain.AidToNavigation{
+		MMSI:                 992351000,
+		Name:                 "BRAMBLE BANK NORTH CARDINAL",
+		AidType:              20,
+		Latitude:             50.79,
+		Longitude:            -1.29,
+		DimensionToBow:       1,
+		DimensionToStern:     1,
+		DimensionToPort:      1,
+		DimensionToStarboard: 1,
+		VirtualAid:           true,
+		LastUpdated:          time.Date(2023, time.September, 11, 17, 4, 30, 0, time.UTC),
+		ReceivedAt:           now,
+	}, collectorService.aidsToNavigation[0])
+}
+
+func TestProcessPacket_AidsToNavigationReport_TrimsPadding(t *testing.T) {
+	tt := map[string]struct {
+		name          string
+		nameExtension string
+		expected      string
+	}{
+		"padded name":                 {name: "BRAMBLE BANK@@@@@@@@", expected: "BRAMBLE BANK"},
+		"padded name extension":       {name: "BRAMBLE BANK NORTH C", nameExtension: "ARDINAL@@@  ", expected: "BRAMBLE BANK NORTH CARDINAL"},
+		"space padded name":           {name: "BRAMBLE BANK        ", expected: "BRAMBLE BANK"},
+		"name filling the name field": {name: "BRAMBLE BANK NORTH C", expected: "BRAMBLE BANK NORTH C"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			packet, err := ParsePacket([]byte(fmt.Sprintf(`{
+				"Message": {
+					"AidsToNavigationReport": {
+						"UserID": 992351000,
+						"Type": 20,
+						"Name": %q,
+						"NameExtension": %q,
+						"Latitude": 50.79,
+						"Longitude": -1.29
+					}
+				},
+				"MessageType": "AidsToNavigationReport",
+				"MetaData": {"MMSI": 992351000, "time_utc": "2023-09-11 17:04:45.5 +0000 UTC"}
+			}`, tc.name, tc.nameExtension)))
+			require.NoError(t, err)
+
+			collectorService := &MockCollectorService{}
+			now := time.Date(2023, time.September, 11, 17, 4, 46, 0, time.UTC)
+			require.NoError(t, ProcessPacket(context.Background(), collectorService, packet, now))
+
+			require.Len(t, collectorService.aidsToNavigation, 1)
+			assert.Equal(t, tc.expected, collectorService.aidsToNavigation[0].Name)
+		})
+	}
+}
+
+func TestProcessPacket_BaseStationReport(t *testing.T) {
+	packet, err := ParsePacket([]byte(`{
+		"Message": {
+			"BaseStationReport": {
+				"UserID": 2320001,
+				"UtcYear": 2023,
+				"UtcMonth": 9,
+				"UtcDay": 11,
+				"UtcHour": 17,
+				"UtcMinute": 4,
+				"UtcSecond": 5,
+				"Latitude": 50.8,
+				"Longitude": -1.3
+			}
+		},
+		"MessageType": "BaseStationReport",
+		"MetaData": {"MMSI": 2320001, "ShipName": "", "time_utc": "2023-09-11 17:04:06.1 +0000 UTC"}
+	}`))
+	require.NoError(t, err)
+
+	collectorService := &MockCollectorService{}
+	now := time.Date(2023, time.September, 11, 17, 4, 7, 0, time.UTC)
+	require.NoError(t, ProcessPacket(context.Background(), collectorService, packet, now))
+
+	require.Len(t, collectorService.baseStations, 1)
+	assert.Equal(t, domain.BaseStation{
+		MMSI:        2320001,
+		Latitude:    50.8,
+		Longitude:   -1.3,
+		LastUpdated: time.Date(2023, time.September, 11, 17, 4, 5, 0, time.UTC),
+		ReceivedAt:  now,
+	}, collectorService.baseStations[0])
+}
+
+func TestProcessPacket_SafetyMessages(t *testing.T) {
+	addressed, err := ParsePacket([]byte(`{
+		"Message": {
+			"AddressedSafetyMessage": {
+				"UserID": 235000000,
+				"DestinationID": 259000420,
+				"Text": "MAYDAY RELAY@@@@"
+			}
+		},
+		"MessageType": "AddressedSafetyMessage",
+		"MetaData": {"MMSI": 235000000, "ShipName": "", "time_utc": "2023-09-11 17:04:06 +0000 UTC"}
+	}`))
+	require.NoError(t, err)
+	broadcast, err := ParsePacket([]byte(`{
+		"Message": {
+			"SafetyBroadcastMessage": {
+				"UserID": 2320001,
+				"Text": "GALE WARNING"
+			}
+		},
+		"MessageType": "SafetyBroadcastMessage",
+		"MetaData": {"MMSI": 2320001, "ShipName": "", "time_utc": "2023-09-11 17:04:06 +0000 UTC"}
+	}`))
+	require.NoError(t, err)
+
+	collectorService := &MockCollectorService{}
+	now := time.Date(2023, time.September, 11, 17, 4, 7, 0, time.UTC)
+	require.NoError(t, ProcessPacket(context.Background(), collectorService, addressed, now))
+	require.NoError(t, ProcessPacket(context.Background(), collectorService, broadcast, now))
+
+	transmittedAt := time.Date(2023, time.September, 11, 17, 4, 6, 0, time.UTC)
+	assert.Equal(t, []domain.SafetyAlert{
+		{
+			MMSI:            235000000,
+			Kind:            domain.SafetyAlertKindAddressed,
+			DestinationMMSI: 259000420,
+			Text:            "MAYDAY RELAY",
+			TransmittedAt:   transmittedAt,
+			ReceivedAt:      now,
+		},
+		{
+			MMSI:          2320001,
+			Kind:          domain.SafetyAlertKindBroadcast,
+			Text:          "GALE WARNING",
+			TransmittedAt: transmittedAt,
+			ReceivedAt:    now,
+		},
+	}, collectorService.safetyAlerts)
+}
+
+func TestProcessPacket_Rejects(t *testing.T) {
+	tt := map[string]struct {
+		packet string
+		reason string
+	}{
+		"missing position report": {
+			packet: `{"MessageType": "PositionReport", "Message": {}}`,
+			reason: RejectReasonMissingMessage,
+		},
+		"missing class B position report": {
+			packet: `{"MessageType": "StandardClassBPositionReport", "Message": {}}`,
+			reason: RejectReasonMissingMessage,
+		},
+		"invalid base station position": {
+			packet: `{"MessageType": "BaseStationReport", "Message": {"BaseStationReport": {"UserID": 2320001, "Latitude": 91, "Longitude": 181}}}`,
+			reason: RejectReasonInvalidBaseStation,
+		},
+		"invalid aid to navigation": {
+			packet: `{"MessageType": "AidsToNavigationReport", "Message": {"AidsToNavigationReport": {"Latitude": 50.79, "Longitude": -1.29}}}`,
+			reason: RejectReasonInvalidAidToNav,
+		},
+		"invalid position": {
+			packet: `{"MessageType": "StandardClassBPositionReport", "Message": {"StandardClassBPositionReport": {"UserID": 235000000, "Latitude": 91}}}`,
+			reason: RejectReasonInvalidPosition,
+		},
+		"addressed safety message without destination": {
+			packet: `{"MessageType": "AddressedSafetyMessage", "Message": {"AddressedSafetyMessage": {"UserID": 235000000, "Text": "MAYDAY"}}}`,
+			reason: RejectReasonInvalidSafetyAlert,
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			packet, err := ParsePacket([]byte(tc.packet))
+			require.NoError(t, err)
+
+			err = ProcessPacket(context.Background(), &MockCollectorService{}, packet, time.Now())
+			var rejectErr *RejectError
+			require.ErrorAs(t, err, &rejectErr)
+			assert.Equal(t, tc.reason, rejectErr.Reason)
+		})
+	}
+}
+
+func TestProcessPacket_RejectsValidReportsNotAcceptedByTheCollector(t *testing.T) {
+	frame, err := os.ReadFile("../../../testdata/ais_class_b_position_report.json")
+	require.NoError(t, err)
+	packet, err := ParsePacket(frame)
+	require.NoError(t, err)
+
+	err = ProcessPacket(context.Background(), &MockCollectorService{err: context.Canceled}, packet, time.Now())
+	var rejectErr *RejectError
+	require.ErrorAs(t, err, &rejectErr)
+	assert.Equal(t, RejectReasonNotAccepted, rejectErr.Reason)
+	assert.ErrorIs(t, err, context.Canceled)
+}
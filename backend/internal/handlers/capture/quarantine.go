@@ -0,0 +1,85 @@
+package capture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+// QuarantineRecord is a single line within the quarantine file
+type QuarantineRecord struct {
+	RejectedAt time.Time `json:"rejectedAt"`
+	Reason     string    `json:"reason"`
+	Error      string    `json:"error"`
+	// Frame is the raw frame exactly as it was received
+	Frame string `json:"frame"`
+}
+
+// Quarantine samples rejected frames to a JSONL file so that they can be inspected later. At most one frame is
+// written per reject reason per sample interval to stop a flood of bad frames from filling the disk. Quarantine is
+// safe for concurrent use.
+type Quarantine struct {
+	interval time.Duration
+
+	mu          sync.Mutex
+	file        *os.File
+	lastSampled map[string]time.Time
+}
+
+func NewQuarantine(cfg config.Config) (*Quarantine, error) {
+	if strings.TrimSpace(cfg.QuarantineFile) == "" {
+		return nil, errors.New("quarantine file must be set")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.QuarantineFile), 0o755); err != nil {
+		return nil, fmt.Errorf("error on creating quarantine directory: %w", err)
+	}
+	file, err := os.OpenFile(cfg.QuarantineFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error on opening quarantine file: %w", err)
+	}
+
+	return &Quarantine{
+		interval:    cfg.QuarantineSampleInterval,
+		file:        file,
+		lastSampled: make(map[string]time.Time),
+	}, nil
+}
+
+// Sample writes the rejected frame to the quarantine file unless a frame with the same reason was written within the
+// sample interval
+func (q *Quarantine) Sample(reason string, frame []byte, rejectErr error, rejectedAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if last, ok := q.lastSampled[reason]; ok && rejectedAt.Sub(last) < q.interval {
+		return nil
+	}
+
+	line, err := json.Marshal(QuarantineRecord{
+		RejectedAt: rejectedAt.UTC(),
+		Reason:     reason,
+		Error:      rejectErr.Error(),
+		Frame:      string(frame),
+	})
+	if err != nil {
+		return fmt.Errorf("error on marshalling quarantine record: %w", err)
+	}
+	if _, err := q.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error on writing to quarantine file: %w", err)
+	}
+	q.lastSampled[reason] = rejectedAt
+	return nil
+}
+
+func (q *Quarantine) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.file.Close()
+}
@@ -0,0 +1,48 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+func TestQuarantine_SamplesRejectedFramesPerReason(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.QuarantineFile = filepath.Join(t.TempDir(), "quarantine", "rejected.jsonl")
+	cfg.QuarantineSampleInterval = time.Minute
+
+	quarantine, err := NewQuarantine(*cfg)
+	require.NoError(t, err)
+
+	rejectedAt := time.Date(2022, time.December, 29, 18, 22, 32, 0, time.UTC)
+	rejectErr := errors.New("invalid latitude")
+	require.NoError(t, quarantine.Sample("invalid_position_report", []byte("frame-1"), rejectErr, rejectedAt))
+	// dropped as a frame with the same reason was sampled within the interval
+	require.NoError(t, quarantine.Sample("invalid_position_report", []byte("frame-2"), rejectErr, rejectedAt.Add(time.Second)))
+	require.NoError(t, quarantine.Sample("unmarshal_error", []byte("frame-3"), rejectErr, rejectedAt.Add(time.Second)))
+	require.NoError(t, quarantine.Sample("invalid_position_report", []byte("frame-4"), rejectErr, rejectedAt.Add(time.Minute)))
+	require.NoError(t, quarantine.Close())
+
+	f, err := os.Open(cfg.QuarantineFile)
+	require.NoError(t, err)
+	defer f.Close()
+	var frames []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record QuarantineRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		assert.Equal(t, "invalid latitude", record.Error)
+		frames = append(frames, record.Frame)
+	}
+	assert.Equal(t, []string{"frame-1", "frame-3", "frame-4"}, frames)
+}
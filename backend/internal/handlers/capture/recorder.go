@@ -0,0 +1,138 @@
+package capture
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+const fileTimeLayout = "20060102T150405.000Z"
+
+// Record is a single line within a capture file
+type Record struct {
+	// ReceivedAt is the time the frame was read by the collector
+	ReceivedAt time.Time `json:"receivedAt"`
+	// Frame is the raw frame exactly as it was received, whether or not it could be parsed
+	Frame string `json:"frame"`
+}
+
+// Recorder writes raw frames to gzipped JSONL capture files, starting a new file once the current one reaches the
+// maximum size or age. Recorder is safe for concurrent use.
+type Recorder struct {
+	dir     string
+	prefix  string
+	maxSize int64
+	maxAge  time.Duration
+
+	mu       sync.Mutex
+	file     *os.File
+	counter  *countingWriter
+	gz       *gzip.Writer
+	openedAt time.Time
+}
+
+// NewRecorder creates a recorder that writes capture files named after the given prefix into the configured
+// capture directory
+func NewRecorder(cfg config.Config, prefix string) (*Recorder, error) {
+	if strings.TrimSpace(cfg.CaptureDirectory) == "" {
+		return nil, errors.New("capture directory must be set")
+	}
+	if cfg.CaptureMaxFileSize <= 0 || cfg.CaptureMaxFileAge <= 0 {
+		return nil, errors.New("capture max file size and age must be positive")
+	}
+	if err := os.MkdirAll(cfg.CaptureDirectory, 0o755); err != nil {
+		return nil, fmt.Errorf("error on creating capture directory: %w", err)
+	}
+
+	return &Recorder{
+		dir:     cfg.CaptureDirectory,
+		prefix:  prefix,
+		maxSize: cfg.CaptureMaxFileSize,
+		maxAge:  cfg.CaptureMaxFileAge,
+	}, nil
+}
+
+// Record appends the frame to the current capture file
+func (r *Recorder) Record(frame []byte, receivedAt time.Time) error {
+	line, err := json.Marshal(Record{ReceivedAt: receivedAt.UTC(), Frame: string(frame)})
+	if err != nil {
+		return fmt.Errorf("error on marshalling capture record: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil && (r.counter.n >= r.maxSize || receivedAt.Sub(r.openedAt) >= r.maxAge) {
+		if err := r.closeFile(); err != nil {
+			clog.Errorf("failed to close capture file: %s", err.Error())
+		}
+	}
+	if r.file == nil {
+		if err := r.openFile(receivedAt); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.gz.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error on writing to capture file: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the current capture file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.closeFile()
+}
+
+func (r *Recorder) openFile(now time.Time) error {
+	name := filepath.Join(r.dir, fmt.Sprintf("%s-%s.jsonl.gz", r.prefix, now.UTC().Format(fileTimeLayout)))
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("error on creating capture file: %w", err)
+	}
+
+	r.file = file
+	r.counter = &countingWriter{w: file}
+	r.gz = gzip.NewWriter(r.counter)
+	r.openedAt = now
+	clog.Infow("opened capture file", "file", name)
+	return nil
+}
+
+// closeFile completes the gzip stream and closes the current file. The caller must hold mu.
+func (r *Recorder) closeFile() error {
+	gzErr := r.gz.Close()
+	fileErr := r.file.Close()
+	r.file, r.counter, r.gz = nil, nil, nil
+	if gzErr != nil {
+		return gzErr
+	}
+	return fileErr
+}
+
+// countingWriter tracks the number of (compressed) bytes written to a capture file
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
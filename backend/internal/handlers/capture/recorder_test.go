@@ -0,0 +1,106 @@
+package capture
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+func newTestRecorder(t *testing.T, maxSize int64, maxAge time.Duration) (*Recorder, string) {
+	t.Helper()
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.CaptureDirectory = filepath.Join(t.TempDir(), "captures")
+	cfg.CaptureMaxFileSize = maxSize
+	cfg.CaptureMaxFileAge = maxAge
+
+	recorder, err := NewRecorder(*cfg, "aisstream")
+	require.NoError(t, err)
+	return recorder, cfg.CaptureDirectory
+}
+
+// readCaptures returns the records in each capture file within the directory, ordered by file name
+func readCaptures(t *testing.T, dir string) [][]Record {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "aisstream-*.jsonl.gz"))
+	require.NoError(t, err)
+	sort.Strings(files)
+
+	var captures [][]Record
+	for _, file := range files {
+		f, err := os.Open(file)
+		require.NoError(t, err)
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+
+		var records []Record
+		scanner := bufio.NewScanner(gz)
+		for scanner.Scan() {
+			var record Record
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			records = append(records, record)
+		}
+		require.NoError(t, scanner.Err())
+		require.NoError(t, f.Close())
+		captures = append(captures, records)
+	}
+	return captures
+}
+
+func TestRecorder_RecordsRawFramesWithReceiveTime(t *testing.T) {
+	recorder, dir := newTestRecorder(t, 1024*1024, time.Hour)
+
+	receivedAt := time.Date(2022, time.December, 29, 18, 22, 32, 0, time.UTC)
+	require.NoError(t, recorder.Record([]byte(`{"MessageType":"PositionReport"}`), receivedAt))
+	// frames that cannot be parsed are captured as well
+	require.NoError(t, recorder.Record([]byte(`{"MessageType":`), receivedAt.Add(time.Second)))
+	require.NoError(t, recorder.Close())
+
+	captures := readCaptures(t, dir)
+	require.Len(t, captures, 1)
+	assert.Equal(t, []Record{
+		{ReceivedAt: receivedAt, Frame: `{"MessageType":"PositionReport"}`},
+		{ReceivedAt: receivedAt.Add(time.Second), Frame: `{"MessageType":`},
+	}, captures[0])
+}
+
+func TestRecorder_RotatesOnAge(t *testing.T) {
+	recorder, dir := newTestRecorder(t, 1024*1024, time.Minute)
+
+	receivedAt := time.Date(2022, time.December, 29, 18, 22, 32, 0, time.UTC)
+	require.NoError(t, recorder.Record([]byte("1"), receivedAt))
+	require.NoError(t, recorder.Record([]byte("2"), receivedAt.Add(30*time.Second)))
+	require.NoError(t, recorder.Record([]byte("3"), receivedAt.Add(61*time.Second)))
+	require.NoError(t, recorder.Close())
+
+	captures := readCaptures(t, dir)
+	require.Len(t, captures, 2)
+	assert.Len(t, captures[0], 2)
+	assert.Len(t, captures[1], 1)
+}
+
+func TestRecorder_RotatesOnSize(t *testing.T) {
+	// the gzip header alone exceeds the max size so every frame is written to a new file
+	recorder, dir := newTestRecorder(t, 1, time.Hour)
+
+	receivedAt := time.Date(2022, time.December, 29, 18, 22, 32, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, recorder.Record([]byte("frame"), receivedAt.Add(time.Duration(i)*time.Second)))
+	}
+	require.NoError(t, recorder.Close())
+
+	captures := readCaptures(t, dir)
+	require.Len(t, captures, 3)
+}
@@ -3,8 +3,10 @@ package searchgraph
 import "github.com/mikeewhite/ship-locator/backend/internal/core/domain"
 
 type ShipSearchResult struct {
-	MMSI int32  `json:"mmsi"`
-	Name string `json:"name"`
+	MMSI        int32   `json:"mmsi"`
+	Name        string  `json:"name"`
+	Flag        *string `json:"flag"`
+	StationType *string `json:"stationType"`
 }
 
 func toShipResultDTOs(searchResults []domain.ShipSearchResult) []ShipSearchResult {
@@ -14,6 +16,13 @@ func toShipResultDTOs(searchResults []domain.ShipSearchResult) []ShipSearchResul
 			MMSI: searchResults[i].MMSI,
 			Name: searchResults[i].Name,
 		}
+		if info, err := domain.ClassifyMMSI(searchResults[i].MMSI); err == nil {
+			stationType := string(info.StationType)
+			dtos[i].StationType = &stationType
+			if info.Flag != "" {
+				dtos[i].Flag = &info.Flag
+			}
+		}
 	}
 
 	return dtos
@@ -6,6 +6,8 @@ import (
 	"github.com/graphql-go/graphql"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
 )
 
 const tracerName = "github.com/mikeewhite/ship-locator/graphql/searchgraph"
@@ -21,8 +23,20 @@ func (s *Server) lookupShipByNameOrMMSI(p graphql.ResolveParams) (interface{}, e
 		return nil, fmt.Errorf("invalid value for searchTerm field: '%v'", p.Args["searchTerm"])
 	}
 
+	var filter domain.ShipSearchFilter
+	if flag, ok := p.Args["flag"].(string); ok {
+		filter.Flag = flag
+	}
+	if name, ok := p.Args["stationType"].(string); ok {
+		stationType, err := domain.ParseStationType(name)
+		if err != nil {
+			return nil, err
+		}
+		filter.StationType = stationType
+	}
+
 	span.SetAttributes(attribute.Key("searchTerm").String(searchTerm))
-	ships, err := s.shipServiceService.Search(ctx, searchTerm)
+	ships, err := s.shipServiceService.Search(ctx, searchTerm, filter)
 	if err != nil {
 		return nil, fmt.Errorf("error on searching for ships with searchTerm '%s': %w", searchTerm, err)
 	}
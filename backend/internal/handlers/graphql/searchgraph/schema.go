@@ -46,6 +46,12 @@ func (s *Server) getSchemaConfig() graphql.SchemaConfig {
 				"name": &graphql.Field{
 					Type: graphql.String,
 				},
+				"flag": &graphql.Field{
+					Type: graphql.String,
+				},
+				"stationType": &graphql.Field{
+					Type: graphql.String,
+				},
 			},
 		},
 	)
@@ -70,6 +76,12 @@ func (s *Server) getSchemaConfig() graphql.SchemaConfig {
 						"searchTerm": &graphql.ArgumentConfig{
 							Type: graphql.String,
 						},
+						"flag": &graphql.ArgumentConfig{
+							Type: graphql.String,
+						},
+						"stationType": &graphql.ArgumentConfig{
+							Type: graphql.String,
+						},
 					},
 					Resolve: s.lookupShipByNameOrMMSI,
 				},
@@ -15,9 +15,11 @@ import (
 )
 
 type MockShipSearchService struct {
+	filter domain.ShipSearchFilter
 }
 
-func (msss *MockShipSearchService) Search(_ context.Context, searchTerm string) ([]domain.ShipSearchResult, error) {
+func (msss *MockShipSearchService) Search(_ context.Context, searchTerm string, filter domain.ShipSearchFilter) ([]domain.ShipSearchResult, error) {
+	msss.filter = filter
 	if searchTerm == "AUGUSTSON" {
 		return []domain.ShipSearchResult{
 			{
@@ -66,7 +68,7 @@ func TestHandleQuery_ShipSearch_FullyPopulatedResponse(t *testing.T) {
 
 	url := `http://localhost:8085/graphql`
 	body := `{
-			"query": "{ shipSearch(searchTerm: \"AUGUSTSON\") { mmsi name } }"
+			"query": "{ shipSearch(searchTerm: \"AUGUSTSON\") { mmsi name flag stationType } }"
 		}`
 	req, err := http.NewRequest("POST", url, strings.NewReader(body))
 	req.Header.Add("Content-Type", "application/json")
@@ -80,7 +82,9 @@ func TestHandleQuery_ShipSearch_FullyPopulatedResponse(t *testing.T) {
 		"data": {
 			"shipSearch": [{
 				"mmsi": 259000420,
-				"name": "AUGUSTSON"
+				"name": "AUGUSTSON",
+				"flag": "NO",
+				"stationType": "ship"
 			}]
 		}
 	}`
@@ -115,3 +119,38 @@ func TestHandleQuery_ShipSearch_PartiallyPopulatedResponse(t *testing.T) {
 	}`
 	assert.JSONEq(t, expResp, rec.Body.String())
 }
+
+func TestHandleQuery_ShipSearch_Filters(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	searchService := &MockShipSearchService{}
+	srv, err := New(*cfg, searchService)
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	url := `http://localhost:8085/graphql`
+	body := `{
+			"query": "{ shipSearch(searchTerm: \"AUGUSTSON\", flag: \"NO\", stationType: \"ship\") { mmsi } }"
+		}`
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	require.Equal(t, 200, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "errors")
+	assert.Equal(t, domain.ShipSearchFilter{Flag: "NO", StationType: domain.StationTypeShip}, searchService.filter)
+
+	body = `{
+			"query": "{ shipSearch(searchTerm: \"AUGUSTSON\", stationType: \"lighthouse\") { mmsi } }"
+		}`
+	req, err = http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Contains(t, rec.Body.String(), "unknown station type 'lighthouse'")
+}
@@ -7,19 +7,168 @@ import (
 )
 
 type Ship struct {
-	MMSI        int32     `json:"mmsi"`
-	Name        string    `json:"name"`
-	Latitude    float64   `json:"latitude"`
-	Longitude   float64   `json:"longitude"`
-	LastUpdated time.Time `json:"lastUpdated"`
+	MMSI                      int32      `json:"mmsi"`
+	Name                      string     `json:"name"`
+	Latitude                  float64    `json:"latitude"`
+	Longitude                 float64    `json:"longitude"`
+	SpeedOverGround           *float64   `json:"speedOverGround"`
+	CourseOverGround          *float64   `json:"courseOverGround"`
+	TrueHeading               *int32     `json:"trueHeading"`
+	NavigationalStatus        *int32     `json:"navigationalStatus"`
+	RateOfTurn                *float64   `json:"rateOfTurn"`
+	RateOfTurnNoTurnIndicator bool       `json:"rateOfTurnNoTurnIndicator"`
+	TransponderClass          *string    `json:"transponderClass"`
+	Flag                      *string    `json:"flag"`
+	StationType               *string    `json:"stationType"`
+	ImplausibleReason         *string    `json:"implausibleReason"`
+	LastUpdated               time.Time  `json:"lastUpdated"`
+	ReceivedAt                *time.Time `json:"receivedAt"`
+	IMONumber                 *int32     `json:"imoNumber"`
+	CallSign                  *string    `json:"callSign"`
+	ShipType                  *int32     `json:"shipType"`
+	Length                    *int32     `json:"length"`
+	Beam                      *int32     `json:"beam"`
+	Draught                   *float64   `json:"draught"`
+	Destination               *string    `json:"destination"`
+	ETA                       *time.Time `json:"eta"`
 }
 
 func toDTO(s domain.Ship) Ship {
-	return Ship{
-		MMSI:        s.MMSI,
-		Name:        s.Name,
-		Latitude:    s.Latitude,
-		Longitude:   s.Longitude,
-		LastUpdated: s.LastUpdated,
+	dto := Ship{
+		MMSI:                      s.MMSI,
+		Name:                      s.Name,
+		Latitude:                  s.Latitude,
+		Longitude:                 s.Longitude,
+		SpeedOverGround:           s.SpeedOverGround,
+		CourseOverGround:          s.CourseOverGround,
+		TrueHeading:               s.TrueHeading,
+		NavigationalStatus:        s.NavigationalStatus,
+		RateOfTurn:                s.RateOfTurn,
+		RateOfTurnNoTurnIndicator: s.RateOfTurnNoTurnIndicator,
+		LastUpdated:               s.LastUpdated,
 	}
+	if s.TransponderClass != "" {
+		class := string(s.TransponderClass)
+		dto.TransponderClass = &class
+	}
+	if info, err := domain.ClassifyMMSI(s.MMSI); err == nil {
+		stationType := string(info.StationType)
+		dto.StationType = &stationType
+		dto.Flag = nonZero(info.Flag)
+	}
+	if s.ImplausibleReason != "" {
+		reason := string(s.ImplausibleReason)
+		dto.ImplausibleReason = &reason
+	}
+	if !s.ReceivedAt.IsZero() {
+		dto.ReceivedAt = &s.ReceivedAt
+	}
+	if s.StaticData != nil {
+		// AIS uses zero values to indicate that static data is not available
+		dto.IMONumber = nonZero(s.StaticData.IMONumber)
+		dto.CallSign = nonZero(s.StaticData.CallSign)
+		dto.ShipType = nonZero(s.StaticData.ShipType)
+		dto.Length = nonZero(s.StaticData.Length())
+		dto.Beam = nonZero(s.StaticData.Beam())
+		dto.Draught = nonZero(s.StaticData.Draught)
+		dto.Destination = nonZero(s.StaticData.Destination)
+		dto.ETA = s.StaticData.ETA
+	}
+	return dto
+}
+
+type AidToNavigation struct {
+	MMSI        int32      `json:"mmsi"`
+	Name        string     `json:"name"`
+	AidType     *int32     `json:"aidType"`
+	Latitude    float64    `json:"latitude"`
+	Longitude   float64    `json:"longitude"`
+	Length      *int32     `json:"length"`
+	Beam        *int32     `json:"beam"`
+	VirtualAid  bool       `json:"virtualAid"`
+	OffPosition bool       `json:"offPosition"`
+	LastUpdated time.Time  `json:"lastUpdated"`
+	ReceivedAt  *time.Time `json:"receivedAt"`
+}
+
+func toAidToNavigationDTO(a domain.AidToNavigation) AidToNavigation {
+	dto := AidToNavigation{
+		MMSI:        a.MMSI,
+		Name:        a.Name,
+		AidType:     nonZero(a.AidType),
+		Latitude:    a.Latitude,
+		Longitude:   a.Longitude,
+		Length:      nonZero(a.DimensionToBow + a.DimensionToStern),
+		Beam:        nonZero(a.DimensionToPort + a.DimensionToStarboard),
+		VirtualAid:  a.VirtualAid,
+		OffPosition: a.OffPosition,
+		LastUpdated: a.LastUpdated,
+	}
+	if !a.ReceivedAt.IsZero() {
+		dto.ReceivedAt = &a.ReceivedAt
+	}
+	return dto
+}
+
+type BaseStation struct {
+	MMSI        int32      `json:"mmsi"`
+	Latitude    float64    `json:"latitude"`
+	Longitude   float64    `json:"longitude"`
+	LastUpdated time.Time  `json:"lastUpdated"`
+	ReceivedAt  *time.Time `json:"receivedAt"`
+}
+
+func toBaseStationDTO(b domain.BaseStation) BaseStation {
+	dto := BaseStation{
+		MMSI:        b.MMSI,
+		Latitude:    b.Latitude,
+		Longitude:   b.Longitude,
+		LastUpdated: b.LastUpdated,
+	}
+	if !b.ReceivedAt.IsZero() {
+		dto.ReceivedAt = &b.ReceivedAt
+	}
+	return dto
+}
+
+type SafetyAlert struct {
+	MMSI            int32      `json:"mmsi"`
+	Kind            string     `json:"kind"`
+	StationType     *string    `json:"stationType"`
+	Flag            *string    `json:"flag"`
+	DestinationMMSI *int32     `json:"destinationMmsi"`
+	Text            *string    `json:"text"`
+	Latitude        *float64   `json:"latitude"`
+	Longitude       *float64   `json:"longitude"`
+	TransmittedAt   time.Time  `json:"transmittedAt"`
+	ReceivedAt      *time.Time `json:"receivedAt"`
+}
+
+func toSafetyAlertDTO(a domain.SafetyAlert) SafetyAlert {
+	dto := SafetyAlert{
+		MMSI:            a.MMSI,
+		Kind:            string(a.Kind),
+		DestinationMMSI: nonZero(a.DestinationMMSI),
+		Text:            nonZero(a.Text),
+		Latitude:        a.Latitude,
+		Longitude:       a.Longitude,
+		TransmittedAt:   a.TransmittedAt,
+	}
+	if info, err := domain.ClassifyMMSI(a.MMSI); err == nil {
+		stationType := string(info.StationType)
+		dto.StationType = &stationType
+		dto.Flag = nonZero(info.Flag)
+	}
+	if !a.ReceivedAt.IsZero() {
+		dto.ReceivedAt = &a.ReceivedAt
+	}
+	return dto
+}
+
+func nonZero[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return &v
 }
@@ -2,14 +2,23 @@ package shipgraph
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/graphql-go/graphql"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
 )
 
 const tracerName = "github.com/mikeewhite/ship-locator/graphql/shipgraph"
 
+const (
+	defaultSafetyAlertsWindow = 24 * time.Hour
+	defaultSafetyAlertsLimit  = 100
+	maxSafetyAlertsLimit      = 1000
+)
+
 func (s *Server) getShipByMMSI(p graphql.ResolveParams) (interface{}, error) {
 	tr := otel.Tracer(tracerName)
 	// See https://opentelemetry.io/docs/specs/otel/trace/semantic_conventions/instrumentation/graphql/
@@ -27,3 +36,117 @@ func (s *Server) getShipByMMSI(p graphql.ResolveParams) (interface{}, error) {
 	}
 	return toDTO(ship), nil
 }
+
+func (s *Server) getAidsToNavigation(p graphql.ResolveParams) (interface{}, error) {
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(p.Context, fmt.Sprintf("%s %s", p.Info.Operation.GetOperation(), p.Info.FieldName))
+	defer span.End()
+
+	bbox, err := toBoundingBox(p.Args["bbox"])
+	if err != nil {
+		return nil, err
+	}
+	aids, err := s.stationService.ListAidsToNavigation(ctx, bbox)
+	if err != nil {
+		return nil, fmt.Errorf("error on listing aids to navigation: %w", err)
+	}
+	span.SetAttributes(attribute.Key("results").Int(len(aids)))
+
+	dtos := make([]AidToNavigation, len(aids))
+	for i, aid := range aids {
+		dtos[i] = toAidToNavigationDTO(aid)
+	}
+	return dtos, nil
+}
+
+func (s *Server) getBaseStations(p graphql.ResolveParams) (interface{}, error) {
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(p.Context, fmt.Sprintf("%s %s", p.Info.Operation.GetOperation(), p.Info.FieldName))
+	defer span.End()
+
+	stations, err := s.stationService.ListBaseStations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error on listing base stations: %w", err)
+	}
+	span.SetAttributes(attribute.Key("results").Int(len(stations)))
+
+	dtos := make([]BaseStation, len(stations))
+	for i, station := range stations {
+		dtos[i] = toBaseStationDTO(station)
+	}
+	return dtos, nil
+}
+
+func (s *Server) getSafetyAlerts(p graphql.ResolveParams) (interface{}, error) {
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(p.Context, fmt.Sprintf("%s %s", p.Info.Operation.GetOperation(), p.Info.FieldName))
+	defer span.End()
+
+	since := time.Now().Add(-defaultSafetyAlertsWindow)
+	if arg, isSet := p.Args["since"]; isSet {
+		t, isOK := arg.(time.Time)
+		if !isOK {
+			return nil, fmt.Errorf("invalid value for since field: '%v'", arg)
+		}
+		since = t
+	}
+	limit := defaultSafetyAlertsLimit
+	if arg, isSet := p.Args["limit"]; isSet {
+		l, isOK := arg.(int)
+		if !isOK || l < 1 || l > maxSafetyAlertsLimit {
+			return nil, fmt.Errorf("invalid value for limit field: '%v' (must be between 1 and %d)", arg,
+				maxSafetyAlertsLimit)
+		}
+		limit = l
+	}
+
+	alerts, err := s.safetyService.ListSafetyAlerts(ctx, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error on listing safety alerts: %w", err)
+	}
+	span.SetAttributes(attribute.Key("results").Int(len(alerts)))
+
+	dtos := make([]SafetyAlert, len(alerts))
+	for i, alert := range alerts {
+		dtos[i] = toSafetyAlertDTO(alert)
+	}
+	return dtos, nil
+}
+
+// subscribeSafetyAlerts passes alerts to the subscription until its context is done
+func (s *Server) subscribeSafetyAlerts(p graphql.ResolveParams) (interface{}, error) {
+	alerts := s.safetyService.SubscribeSafetyAlerts(p.Context)
+	c := make(chan interface{})
+	go func() {
+		defer close(c)
+		for alert := range alerts {
+			select {
+			case c <- toSafetyAlertDTO(alert):
+			case <-p.Context.Done():
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+func toBoundingBox(arg interface{}) (domain.BoundingBox, error) {
+	fields, isOK := arg.(map[string]interface{})
+	if !isOK {
+		return domain.BoundingBox{}, fmt.Errorf("invalid value for bbox field: '%v'", arg)
+	}
+	var coords [4]float64
+	for i, name := range []string{"minLatitude", "minLongitude", "maxLatitude", "maxLongitude"} {
+		v, isOK := fields[name].(float64)
+		if !isOK {
+			return domain.BoundingBox{}, fmt.Errorf("invalid value for bbox %s field: '%v'", name, fields[name])
+		}
+		coords[i] = v
+	}
+	return domain.BoundingBox{
+		MinLatitude:  coords[0],
+		MinLongitude: coords[1],
+		MaxLatitude:  coords[2],
+		MaxLongitude: coords[3],
+	}, nil
+}
@@ -52,9 +52,188 @@ func (s *Server) getSchemaConfig() graphql.SchemaConfig {
 				"longitude": &graphql.Field{
 					Type: graphql.Float,
 				},
+				"speedOverGround": &graphql.Field{
+					Type: graphql.Float,
+				},
+				"courseOverGround": &graphql.Field{
+					Type: graphql.Float,
+				},
+				"trueHeading": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"navigationalStatus": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"rateOfTurn": &graphql.Field{
+					Type: graphql.Float,
+				},
+				"rateOfTurnNoTurnIndicator": &graphql.Field{
+					Type: graphql.Boolean,
+				},
+				"transponderClass": &graphql.Field{
+					Type: graphql.String,
+				},
+				"implausibleReason": &graphql.Field{
+					Type: graphql.String,
+				},
+				"flag": &graphql.Field{
+					Type: graphql.String,
+				},
+				"stationType": &graphql.Field{
+					Type: graphql.String,
+				},
+				"lastUpdated": &graphql.Field{
+					Type: graphql.DateTime,
+				},
+				"receivedAt": &graphql.Field{
+					Type: graphql.DateTime,
+				},
+				"imoNumber": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"callSign": &graphql.Field{
+					Type: graphql.String,
+				},
+				"shipType": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"length": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"beam": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"draught": &graphql.Field{
+					Type: graphql.Float,
+				},
+				"destination": &graphql.Field{
+					Type: graphql.String,
+				},
+				"eta": &graphql.Field{
+					Type: graphql.DateTime,
+				},
+			},
+		},
+	)
+
+	aidToNavigationType := graphql.NewObject(
+		graphql.ObjectConfig{
+			Name: "AidToNavigation",
+			Fields: graphql.Fields{
+				"mmsi": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"name": &graphql.Field{
+					Type: graphql.String,
+				},
+				"aidType": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"latitude": &graphql.Field{
+					Type: graphql.Float,
+				},
+				"longitude": &graphql.Field{
+					Type: graphql.Float,
+				},
+				"length": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"beam": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"virtualAid": &graphql.Field{
+					Type: graphql.Boolean,
+				},
+				"offPosition": &graphql.Field{
+					Type: graphql.Boolean,
+				},
+				"lastUpdated": &graphql.Field{
+					Type: graphql.DateTime,
+				},
+				"receivedAt": &graphql.Field{
+					Type: graphql.DateTime,
+				},
+			},
+		},
+	)
+
+	baseStationType := graphql.NewObject(
+		graphql.ObjectConfig{
+			Name: "BaseStation",
+			Fields: graphql.Fields{
+				"mmsi": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"latitude": &graphql.Field{
+					Type: graphql.Float,
+				},
+				"longitude": &graphql.Field{
+					Type: graphql.Float,
+				},
 				"lastUpdated": &graphql.Field{
 					Type: graphql.DateTime,
 				},
+				"receivedAt": &graphql.Field{
+					Type: graphql.DateTime,
+				},
+			},
+		},
+	)
+
+	safetyAlertType := graphql.NewObject(
+		graphql.ObjectConfig{
+			Name: "SafetyAlert",
+			Fields: graphql.Fields{
+				"mmsi": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"kind": &graphql.Field{
+					Type: graphql.String,
+				},
+				"stationType": &graphql.Field{
+					Type: graphql.String,
+				},
+				"flag": &graphql.Field{
+					Type: graphql.String,
+				},
+				"destinationMmsi": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"text": &graphql.Field{
+					Type: graphql.String,
+				},
+				"latitude": &graphql.Field{
+					Type: graphql.Float,
+				},
+				"longitude": &graphql.Field{
+					Type: graphql.Float,
+				},
+				"transmittedAt": &graphql.Field{
+					Type: graphql.DateTime,
+				},
+				"receivedAt": &graphql.Field{
+					Type: graphql.DateTime,
+				},
+			},
+		},
+	)
+
+	boundingBoxInput := graphql.NewInputObject(
+		graphql.InputObjectConfig{
+			Name: "BoundingBox",
+			Fields: graphql.InputObjectConfigFieldMap{
+				"minLatitude": &graphql.InputObjectFieldConfig{
+					Type: graphql.NewNonNull(graphql.Float),
+				},
+				"minLongitude": &graphql.InputObjectFieldConfig{
+					Type: graphql.NewNonNull(graphql.Float),
+				},
+				"maxLatitude": &graphql.InputObjectFieldConfig{
+					Type: graphql.NewNonNull(graphql.Float),
+				},
+				"maxLongitude": &graphql.InputObjectFieldConfig{
+					Type: graphql.NewNonNull(graphql.Float),
+				},
 			},
 		},
 	)
@@ -82,10 +261,50 @@ func (s *Server) getSchemaConfig() graphql.SchemaConfig {
 					},
 					Resolve: s.getShipByMMSI,
 				},
+				"aidsToNavigation": &graphql.Field{
+					Type: graphql.NewList(aidToNavigationType),
+					Args: graphql.FieldConfigArgument{
+						"bbox": &graphql.ArgumentConfig{
+							Type: graphql.NewNonNull(boundingBoxInput),
+						},
+					},
+					Resolve: s.getAidsToNavigation,
+				},
+				"baseStations": &graphql.Field{
+					Type:    graphql.NewList(baseStationType),
+					Resolve: s.getBaseStations,
+				},
+				"safetyAlerts": &graphql.Field{
+					Type: graphql.NewList(safetyAlertType),
+					Args: graphql.FieldConfigArgument{
+						"since": &graphql.ArgumentConfig{
+							Type: graphql.DateTime,
+						},
+						"limit": &graphql.ArgumentConfig{
+							Type: graphql.Int,
+						},
+					},
+					Resolve: s.getSafetyAlerts,
+				},
+			},
+		})
+
+	rootSubscription := graphql.NewObject(
+		graphql.ObjectConfig{
+			Name: "RootSubscription",
+			Fields: graphql.Fields{
+				"safetyAlerts": &graphql.Field{
+					Type:      safetyAlertType,
+					Subscribe: s.subscribeSafetyAlerts,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source, nil
+					},
+				},
 			},
 		})
 
 	return graphql.SchemaConfig{
-		Query: rootQuery,
+		Query:        rootQuery,
+		Subscription: rootSubscription,
 	}
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/graphql-go/graphql"
 	"github.com/rs/cors"
@@ -15,9 +17,11 @@ import (
 )
 
 type Server struct {
-	httpServer http.Server
-	service    ports.ShipService
-	schema     *graphql.Schema
+	httpServer     http.Server
+	service        ports.ShipService
+	stationService ports.StationService
+	safetyService  ports.SafetyAlertService
+	schema         *graphql.Schema
 }
 
 type postData struct {
@@ -28,9 +32,12 @@ type postData struct {
 
 const endpoint = "/graphql"
 
-func New(cfg config.Config, service ports.ShipService) (*Server, error) {
+func New(cfg config.Config, service ports.ShipService, stationService ports.StationService,
+	safetyService ports.SafetyAlertService) (*Server, error) {
 	s := &Server{
-		service: service,
+		service:        service,
+		stationService: stationService,
+		safetyService:  safetyService,
 	}
 
 	schema, err := graphql.NewSchema(s.getSchemaConfig())
@@ -51,6 +58,10 @@ func New(cfg config.Config, service ports.ShipService) (*Server, error) {
 
 func (s *Server) Serve(ctx context.Context) error {
 	clog.Infof("Starting GraphQL server at %s", s.httpServer.Addr)
+	// requests are cancelled on shutdown so that open subscriptions do not hold it up
+	s.httpServer.BaseContext = func(net.Listener) context.Context {
+		return ctx
+	}
 	go func() {
 		<-ctx.Done()
 		clog.Info("Stopping GraphQL server")
@@ -73,12 +84,63 @@ func (s *Server) HandleQuery(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(400)
 		return
 	}
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		s.handleSubscription(w, r, p)
+		return
+	}
 	result := s.executeQuery(p)
 	if err := json.NewEncoder(w).Encode(result); err != nil {
 		clog.Errorf("error on encoding graphql response: %s", err.Error())
 	}
 }
 
+// handleSubscription streams the results of a subscription as server-sent events (following the distinct connections
+// mode of the GraphQL over SSE protocol) until the client disconnects. The gateway does not proxy subscriptions so
+// clients subscribe to the service directly.
+func (s *Server) handleSubscription(w http.ResponseWriter, r *http.Request, p postData) {
+	flusher, isOK := w.(http.Flusher)
+	if !isOK {
+		clog.Errorf("unable to stream graphql subscription as the response writer cannot be flushed")
+		w.WriteHeader(500)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(200)
+	flusher.Flush()
+
+	results := graphql.Subscribe(graphql.Params{
+		Schema:         *s.schema,
+		RequestString:  p.Query,
+		VariableValues: p.Variables,
+		OperationName:  p.Operation,
+		Context:        r.Context(),
+	})
+	// the results must be drained for the subscription to stop once the client has disconnected
+	defer func() {
+		for range results {
+		}
+	}()
+
+	for result := range results {
+		if len(result.Errors) > 0 {
+			clog.Errorf("error returned from graphQL API: %v", result.Errors)
+		}
+		b, err := json.Marshal(result)
+		if err != nil {
+			clog.Errorf("error on encoding graphql subscription result: %s", err.Error())
+			return
+		}
+		if _, err := fmt.Fprintf(w, "event: next\ndata: %s\n\n", b); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+	_, _ = fmt.Fprint(w, "event: complete\ndata:\n\n")
+	flusher.Flush()
+}
+
 func (s *Server) executeQuery(postData postData) *graphql.Result {
 	result := graphql.Do(graphql.Params{
 		Schema:         *s.schema,
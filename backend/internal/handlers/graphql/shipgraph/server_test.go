@@ -1,6 +1,7 @@
 package shipgraph
 
 import (
+	"bufio"
 	"context"
 	"net/http"
 	"net/http/httptest"
@@ -30,6 +31,33 @@ func (mr *MockShipService) Get(_ context.Context, mmsi int32) (domain.Ship, erro
 			LastUpdated: lastUpdated,
 		}, nil
 	}
+	if mmsi == 257000000 {
+		eta, _ := time.Parse(time.RFC3339, "2023-09-12T08:30:00Z")
+		return domain.Ship{
+			MMSI:             257000000,
+			Name:             "NORDSTJERNEN",
+			Latitude:         68.2,
+			Longitude:        14.6,
+			Kinematics:       domain.NewKinematics(14.5, 182.3, 511, 0, 0),
+			TransponderClass: domain.TransponderClassA,
+			LastUpdated:      lastUpdated,
+			ReceivedAt:       lastUpdated.Add(3 * time.Second),
+			StaticData: &domain.ShipStaticData{
+				MMSI:                 257000000,
+				Name:                 "NORDSTJERNEN",
+				IMONumber:            9000000,
+				CallSign:             "LAGV",
+				ShipType:             60,
+				DimensionToBow:       100,
+				DimensionToStern:     23,
+				DimensionToPort:      10,
+				DimensionToStarboard: 9,
+				Destination:          "BODO",
+				ETA:                  &eta,
+				LastUpdated:          lastUpdated,
+			},
+		}, nil
+	}
 	return domain.Ship{}, apperrors.NewNoShipFoundErr(mmsi)
 }
 
@@ -38,11 +66,124 @@ func (mr *MockShipService) Store(_ context.Context, _ []domain.Ship) error {
 	return nil
 }
 
+func (mr *MockShipService) StoreStaticData(_ context.Context, _ []domain.ShipStaticData) error {
+	// noop
+	return nil
+}
+
+type MockStationService struct {
+}
+
+func (ms *MockStationService) ListAidsToNavigation(_ context.Context, bbox domain.BoundingBox) ([]domain.AidToNavigation, error) {
+	if err := bbox.Validate(); err != nil {
+		return nil, err
+	}
+	lastUpdated, _ := time.Parse(time.RFC3339, "2023-09-11T17:04:05Z")
+	aids := []domain.AidToNavigation{
+		{
+			MMSI:                 992351000,
+			Name:                 "BRAMBLE BANK",
+			AidType:              20,
+			Latitude:             50.79,
+			Longitude:            -1.29,
+			DimensionToBow:       1,
+			DimensionToStern:     1,
+			DimensionToPort:      1,
+			DimensionToStarboard: 1,
+			LastUpdated:          lastUpdated,
+			ReceivedAt:           lastUpdated.Add(2 * time.Second),
+		},
+		{
+			MMSI:        992351001,
+			Name:        "VIRTUAL WRECK MARK",
+			Latitude:    50.7,
+			Longitude:   -1.1,
+			VirtualAid:  true,
+			LastUpdated: lastUpdated,
+		},
+	}
+	var matches []domain.AidToNavigation
+	for _, aid := range aids {
+		if aid.Latitude >= bbox.MinLatitude && aid.Latitude <= bbox.MaxLatitude &&
+			aid.Longitude >= bbox.MinLongitude && aid.Longitude <= bbox.MaxLongitude {
+			matches = append(matches, aid)
+		}
+	}
+	return matches, nil
+}
+
+func (ms *MockStationService) ListBaseStations(_ context.Context) ([]domain.BaseStation, error) {
+	lastUpdated, _ := time.Parse(time.RFC3339, "2023-09-11T17:04:05Z")
+	return []domain.BaseStation{
+		{MMSI: 2320001, Latitude: 50.8, Longitude: -1.3, LastUpdated: lastUpdated},
+	}, nil
+}
+
+func (ms *MockStationService) StoreAidsToNavigation(_ context.Context, _ []domain.AidToNavigation) error {
+	// noop
+	return nil
+}
+
+func (ms *MockStationService) StoreBaseStations(_ context.Context, _ []domain.BaseStation) error {
+	// noop
+	return nil
+}
+
+type MockSafetyAlertService struct {
+	since       time.Time
+	limit       int
+	subscribers chan chan domain.SafetyAlert
+}
+
+func (ms *MockSafetyAlertService) ListSafetyAlerts(_ context.Context, since time.Time, limit int) ([]domain.SafetyAlert, error) {
+	ms.since = since
+	ms.limit = limit
+	transmittedAt, _ := time.Parse(time.RFC3339, "2023-09-11T17:04:05Z")
+	latitude, longitude := 50.8, -1.3
+	return []domain.SafetyAlert{
+		{
+			MMSI:            235000000,
+			Kind:            domain.SafetyAlertKindAddressed,
+			DestinationMMSI: 2320001,
+			Text:            "ENGINE FAILURE",
+			TransmittedAt:   transmittedAt.Add(time.Minute),
+		},
+		{
+			MMSI:          972123456,
+			Kind:          domain.SafetyAlertKindBeacon,
+			Latitude:      &latitude,
+			Longitude:     &longitude,
+			TransmittedAt: transmittedAt,
+			ReceivedAt:    transmittedAt.Add(2 * time.Second),
+		},
+	}, nil
+}
+
+func (ms *MockSafetyAlertService) StoreSafetyAlerts(_ context.Context, _ []domain.SafetyAlert) error {
+	// noop
+	return nil
+}
+
+func (ms *MockSafetyAlertService) NotifySafetyAlerts(_ []domain.SafetyAlert) {
+	// noop
+}
+
+// SubscribeSafetyAlerts hands the subscription's channel to the test so that it can send alerts to the subscriber
+func (ms *MockSafetyAlertService) SubscribeSafetyAlerts(ctx context.Context) <-chan domain.SafetyAlert {
+	c := make(chan domain.SafetyAlert, 1)
+	ms.subscribers <- c
+	go func() {
+		<-ctx.Done()
+		close(c)
+	}()
+	return c
+}
+
 func TestHandleQuery_Ship_NoMatch(t *testing.T) {
 	cfg, err := config.Load()
 	require.NoError(t, err)
 
-	srv, err := New(*cfg, &MockShipService{})
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
 	require.NoError(t, err)
 	defer srv.Shutdown()
 
@@ -64,7 +205,7 @@ func TestHandleQuery_Ship_FullyPopulatedResponse(t *testing.T) {
 	cfg, err := config.Load()
 	require.NoError(t, err)
 
-	srv, err := New(*cfg, &MockShipService{})
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
 	require.NoError(t, err)
 	defer srv.Shutdown()
 
@@ -98,7 +239,7 @@ func TestHandleQuery_Ship_PartiallyPopulatedResponse(t *testing.T) {
 	cfg, err := config.Load()
 	require.NoError(t, err)
 
-	srv, err := New(*cfg, &MockShipService{})
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
 	require.NoError(t, err)
 	defer srv.Shutdown()
 
@@ -122,3 +263,380 @@ func TestHandleQuery_Ship_PartiallyPopulatedResponse(t *testing.T) {
 	}`
 	assert.JSONEq(t, expResp, rec.Body.String())
 }
+
+func TestHandleQuery_Ship_StaticData(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	url := `http://localhost:8085/graphql`
+	body := `{
+			"query": "{ ship(mmsi: 257000000) { name imoNumber callSign shipType length beam draught destination eta } }"
+		}`
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+	expResp := `{
+		"data": {
+			"ship": {
+				"name": "NORDSTJERNEN",
+				"imoNumber": 9000000,
+				"callSign": "LAGV",
+				"shipType": 60,
+				"length": 123,
+				"beam": 19,
+				"draught": null,
+				"destination": "BODO",
+				"eta": "2023-09-12T08:30:00Z"
+			}
+		}
+	}`
+	assert.JSONEq(t, expResp, rec.Body.String())
+}
+
+func TestHandleQuery_Ship_StaticDataNotAvailable(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	url := `http://localhost:8085/graphql`
+	body := `{
+			"query": "{ ship(mmsi: 259000420) { name imoNumber destination eta } }"
+		}`
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+	expResp := `{
+		"data": {
+			"ship": {
+				"name": "AUGUSTSON",
+				"imoNumber": null,
+				"destination": null,
+				"eta": null
+			}
+		}
+	}`
+	assert.JSONEq(t, expResp, rec.Body.String())
+}
+
+func TestHandleQuery_Ship_Kinematics(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	url := `http://localhost:8085/graphql`
+	body := `{
+			"query": "{ ship(mmsi: 257000000) { speedOverGround courseOverGround trueHeading navigationalStatus rateOfTurn rateOfTurnNoTurnIndicator transponderClass } }"
+		}`
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+	expResp := `{
+		"data": {
+			"ship": {
+				"speedOverGround": 14.5,
+				"courseOverGround": 182.3,
+				"trueHeading": null,
+				"navigationalStatus": 0,
+				"rateOfTurn": 0,
+				"rateOfTurnNoTurnIndicator": false,
+				"transponderClass": "A"
+			}
+		}
+	}`
+	assert.JSONEq(t, expResp, rec.Body.String())
+}
+
+func TestHandleQuery_Ship_FlagAndStationType(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	url := `http://localhost:8085/graphql`
+	body := `{
+			"query": "{ ship(mmsi: 257000000) { flag stationType } }"
+		}`
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+	expResp := `{
+		"data": {
+			"ship": {
+				"flag": "NO",
+				"stationType": "ship"
+			}
+		}
+	}`
+	assert.JSONEq(t, expResp, rec.Body.String())
+}
+
+func TestHandleQuery_Ship_ReceivedAt(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	url := `http://localhost:8085/graphql`
+	body := `{
+			"query": "{ a: ship(mmsi: 257000000) { lastUpdated receivedAt } b: ship(mmsi: 259000420) { lastUpdated receivedAt } }"
+		}`
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+	expResp := `{
+		"data": {
+			"a": {
+				"lastUpdated": "2023-09-11T17:04:05Z",
+				"receivedAt": "2023-09-11T17:04:08Z"
+			},
+			"b": {
+				"lastUpdated": "2023-09-11T17:04:05Z",
+				"receivedAt": null
+			}
+		}
+	}`
+	assert.JSONEq(t, expResp, rec.Body.String())
+}
+
+func TestHandleQuery_AidsToNavigation(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	url := `http://localhost:8085/graphql`
+	body := `{
+			"query": "{ aidsToNavigation(bbox: {minLatitude: 50.75, minLongitude: -1.5, maxLatitude: 50.85, maxLongitude: -1.2}) { mmsi name aidType latitude longitude length beam virtualAid offPosition lastUpdated receivedAt } }"
+		}`
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+	expResp := `{
+		"data": {
+			"aidsToNavigation": [
+				{
+					"mmsi": 992351000,
+					"name": "BRAMBLE BANK",
+					"aidType": 20,
+					"latitude": 50.79,
+					"longitude": -1.29,
+					"length": 2,
+					"beam": 2,
+					"virtualAid": false,
+					"offPosition": false,
+					"lastUpdated": "2023-09-11T17:04:05Z",
+					"receivedAt": "2023-09-11T17:04:07Z"
+				}
+			]
+		}
+	}`
+	assert.JSONEq(t, expResp, rec.Body.String())
+}
+
+func TestHandleQuery_AidsToNavigation_InvalidBoundingBox(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	url := `http://localhost:8085/graphql`
+	body := `{
+			"query": "{ aidsToNavigation(bbox: {minLatitude: 51, minLongitude: -1.5, maxLatitude: 50, maxLongitude: -1.2}) { mmsi } }"
+		}`
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Body.String(), "minimum coordinates must not exceed maximum coordinates")
+}
+
+func TestHandleQuery_BaseStations(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, &MockSafetyAlertService{})
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	url := `http://localhost:8085/graphql`
+	body := `{
+			"query": "{ baseStations { mmsi latitude longitude lastUpdated receivedAt } }"
+		}`
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+	expResp := `{
+		"data": {
+			"baseStations": [
+				{
+					"mmsi": 2320001,
+					"latitude": 50.8,
+					"longitude": -1.3,
+					"lastUpdated": "2023-09-11T17:04:05Z",
+					"receivedAt": null
+				}
+			]
+		}
+	}`
+	assert.JSONEq(t, expResp, rec.Body.String())
+}
+
+func TestHandleQuery_SafetyAlerts(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	safetyService := &MockSafetyAlertService{}
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, safetyService)
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	url := `http://localhost:8085/graphql`
+	body := `{
+			"query": "{ safetyAlerts(since: \"2023-09-11T00:00:00Z\", limit: 10) { mmsi kind stationType flag destinationMmsi text latitude longitude transmittedAt receivedAt } }"
+		}`
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+	expResp := `{
+		"data": {
+			"safetyAlerts": [
+				{
+					"mmsi": 235000000,
+					"kind": "addressed",
+					"stationType": "ship",
+					"flag": "GB",
+					"destinationMmsi": 2320001,
+					"text": "ENGINE FAILURE",
+					"latitude": null,
+					"longitude": null,
+					"transmittedAt": "2023-09-11T17:05:05Z",
+					"receivedAt": null
+				},
+				{
+					"mmsi": 972123456,
+					"kind": "beacon",
+					"stationType": "mob",
+					"flag": null,
+					"destinationMmsi": null,
+					"text": null,
+					"latitude": 50.8,
+					"longitude": -1.3,
+					"transmittedAt": "2023-09-11T17:04:05Z",
+					"receivedAt": "2023-09-11T17:04:07Z"
+				}
+			]
+		}
+	}`
+	assert.JSONEq(t, expResp, rec.Body.String())
+	assert.Equal(t, time.Date(2023, time.September, 11, 0, 0, 0, 0, time.UTC), safetyService.since)
+	assert.Equal(t, 10, safetyService.limit)
+
+	body = `{
+			"query": "{ safetyAlerts(limit: 5000) { mmsi } }"
+		}`
+	req, err = http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	srv.HandleQuery(rec, req)
+
+	assert.Contains(t, rec.Body.String(), "invalid value for limit field")
+}
+
+func TestHandleQuery_SafetyAlertsSubscription(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	safetyService := &MockSafetyAlertService{subscribers: make(chan chan domain.SafetyAlert, 1)}
+	srv, err := New(*cfg, &MockShipService{}, &MockStationService{}, safetyService)
+	require.NoError(t, err)
+	defer srv.Shutdown()
+
+	ts := httptest.NewServer(http.HandlerFunc(srv.HandleQuery))
+	defer ts.Close()
+
+	body := `{
+			"query": "subscription { safetyAlerts { mmsi kind text } }"
+		}`
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", ts.URL, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	select {
+	case subscriber := <-safetyService.subscribers:
+		subscriber <- domain.SafetyAlert{MMSI: 972123456, Kind: domain.SafetyAlertKindBroadcast, Text: "MOB ACTIVE"}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for subscription")
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	event, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: next\n", event)
+	data, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(data, "data: "))
+	assert.JSONEq(t, `{"data": {"safetyAlerts": {"mmsi": 972123456, "kind": "broadcast", "text": "MOB ACTIVE"}}}`,
+		strings.TrimPrefix(data, "data: "))
+}
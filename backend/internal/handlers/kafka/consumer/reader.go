@@ -0,0 +1,229 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	kafka2 "github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka"
+	"github.com/mikeewhite/ship-locator/backend/pkg/apperrors"
+	"github.com/mikeewhite/ship-locator/backend/pkg/backoff"
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+	"github.com/mikeewhite/ship-locator/backend/pkg/schemaregistry"
+)
+
+// messageReader is the part of the Kafka reader used by the consumers
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// DeadLetterWriter publishes messages that could not be consumed to the dead-letter topic
+type DeadLetterWriter interface {
+	WriteDeadLetter(ctx context.Context, m kafka.Message, cause error, attempts int) error
+}
+
+// messageHandler processes a single message. As messages are redelivered if the consumer stops before their offset is
+// committed, handlers must be idempotent. Errors that retrying won't fix should be wrapped with permanent.
+type messageHandler func(ctx context.Context, m *kafka.Message) error
+
+// permanentError marks a message as one that can never be processed (e.g. because it can't be decoded)
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+func permanent(err error) error {
+	return &permanentError{err}
+}
+
+// unavailable reports whether the error was caused by a dependency (the data stores or the schema registry) being
+// unavailable. These errors say nothing about the message itself so are retried until the dependency recovers.
+func unavailable(err error) bool {
+	return errors.Is(err, apperrors.ErrStoreUnavailable) || errors.Is(err, schemaregistry.ErrUnavailable)
+}
+
+// decodeError marks an error decoding a message as permanent unless it was caused by the schema registry being
+// unavailable, in which case decoding may succeed when retried
+func decodeError(err error) error {
+	if errors.Is(err, schemaregistry.ErrUnavailable) {
+		return err
+	}
+	return permanent(err)
+}
+
+// consumeLoop reads messages from a topic, retrying those that fail with a backoff and routing those that still fail
+// (or fail permanently) to the dead-letter topic so that a bad message can't stop the consumer. Messages that fail
+// because a dependency is unavailable are retried until it recovers, pausing the consumer rather than dead-lettering
+// everything consumed during an outage.
+type consumeLoop struct {
+	reader        messageReader
+	topic         string
+	consumerGroup string
+	metrics       kafka2.Metrics
+	deadLetters   DeadLetterWriter
+	maxAttempts   int
+	backoff       backoff.Backoff
+}
+
+func newConsumeLoop(cfg config.Config, topic string, deadLetters DeadLetterWriter, metrics kafka2.Metrics) *consumeLoop {
+	return newGroupConsumeLoop(cfg, topic, cfg.KafkaConsumerGroup, kafka.FirstOffset, deadLetters, metrics)
+}
+
+// newGroupConsumeLoop creates a loop reading the topic as a member of the given consumer group. The start offset is
+// used if the group has no committed offset for a partition.
+func newGroupConsumeLoop(cfg config.Config, topic, consumerGroup string, startOffset int64,
+	deadLetters DeadLetterWriter, metrics kafka2.Metrics) *consumeLoop {
+	return &consumeLoop{
+		reader:        newReader(cfg, topic, consumerGroup, startOffset),
+		topic:         topic,
+		consumerGroup: consumerGroup,
+		metrics:       metrics,
+		deadLetters:   deadLetters,
+		maxAttempts:   cfg.KafkaRetryMaxAttempts,
+		backoff:       backoff.New(cfg.KafkaRetryMinBackoff, cfg.KafkaRetryMaxBackoff),
+	}
+}
+
+func newReader(cfg config.Config, topic, consumerGroup string, startOffset int64) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     []string{cfg.KafkaAddress},
+		GroupID:     consumerGroup,
+		Topic:       topic,
+		StartOffset: startOffset,
+		MaxBytes:    10e6, // 10MB
+		// offsets are committed in batches at this interval (or straight away if it's zero) and any pending commits
+		// are flushed when the reader is closed
+		CommitInterval: cfg.KafkaCommitInterval,
+		ErrorLogger: kafka.LoggerFunc(func(msg string, a ...interface{}) {
+			clog.Errorf(msg, a...)
+			fmt.Println()
+		}),
+	})
+}
+
+// run fetches messages from the topic and passes them to the handler until the context is cancelled, only committing
+// a message's offset once it has been handled or dead-lettered. A message that is being handled when the consumer
+// stops is therefore redelivered rather than lost.
+func (l *consumeLoop) run(ctx context.Context, handle messageHandler) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			start := time.Now()
+			m, err := l.reader.FetchMessage(ctx)
+			if err != nil {
+				return fmt.Errorf("error on fetching message: %w", err)
+			}
+			l.metrics.KafkaConsumeTime(l.topic, start)
+
+			// the message is handled within a span continuing the trace of the producer that published it
+			spanCtx, span := kafka2.StartProcessSpan(ctx, l.consumerGroup, &m)
+			err = l.handle(spanCtx, &m, handle)
+			kafka2.EndSpan(span, err)
+			if err != nil {
+				return err
+			}
+			if err := l.reader.CommitMessages(ctx, m); err != nil {
+				return fmt.Errorf("error on committing offset %d of partition %d: %w", m.Offset, m.Partition, err)
+			}
+		}
+	}
+}
+
+// handle passes the message to the handler, retrying transient errors up to the maximum number of attempts before
+// dead-lettering the message. Errors caused by an unavailable dependency are retried without a limit. An error is only
+// returned if the context is cancelled.
+func (l *consumeLoop) handle(ctx context.Context, m *kafka.Message, handle messageHandler) error {
+	attempts := 0
+	for {
+		attempts++
+		err := handle(ctx, m)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		trace.SpanFromContext(ctx).RecordError(err)
+		var permErr *permanentError
+		if errors.As(err, &permErr) || (attempts >= l.maxAttempts && !unavailable(err)) {
+			trace.SpanFromContext(ctx).SetStatus(codes.Error, "message dead-lettered")
+			return l.deadLetter(ctx, m, err, attempts)
+		}
+
+		delay := l.backoff.Duration(attempts - 1)
+		clog.Warnw("failed to handle Kafka message, retrying",
+			"topic", l.topic,
+			"partition", m.Partition,
+			"offset", m.Offset,
+			"attempt", attempts,
+			"delay", delay.String(),
+			"error", err.Error())
+		l.metrics.KafkaMessageRetried(l.topic)
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// deadLetter publishes the message to the dead-letter topic. Publishing is retried until it succeeds (or the context
+// is cancelled) as the message would otherwise be lost once its offset is committed.
+func (l *consumeLoop) deadLetter(ctx context.Context, m *kafka.Message, cause error, attempts int) error {
+	clog.Errorw("routing Kafka message to dead-letter topic",
+		"topic", l.topic,
+		"partition", m.Partition,
+		"offset", m.Offset,
+		"attempts", attempts,
+		"error", cause.Error())
+	for attempt := 0; ; attempt++ {
+		err := l.deadLetters.WriteDeadLetter(ctx, *m, cause, attempts)
+		if err == nil {
+			l.metrics.KafkaMessageDeadLettered(l.topic)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		delay := l.backoff.Duration(attempt)
+		clog.Errorw("failed to write message to dead-letter topic, retrying",
+			"topic", l.topic,
+			"offset", m.Offset,
+			"delay", delay.String(),
+			"error", err.Error())
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *consumeLoop) close() {
+	if err := l.reader.Close(); err != nil {
+		clog.Errorf("failed to close Kafka reader: %s", err.Error())
+	}
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
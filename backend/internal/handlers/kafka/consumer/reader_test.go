@@ -0,0 +1,204 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/pkg/apperrors"
+	"github.com/mikeewhite/ship-locator/backend/pkg/backoff"
+)
+
+// MockReader serves the given messages in order and records the offsets that are committed
+type MockReader struct {
+	messages  []kafka.Message
+	committed []int64
+}
+
+func (m *MockReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if len(m.messages) == 0 {
+		<-ctx.Done()
+		return kafka.Message{}, ctx.Err()
+	}
+	msg := m.messages[0]
+	m.messages = m.messages[1:]
+	return msg, nil
+}
+
+func (m *MockReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		m.committed = append(m.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (m *MockReader) Close() error {
+	return nil
+}
+
+type deadLetter struct {
+	offset   int64
+	cause    error
+	attempts int
+}
+
+type MockDeadLetterWriter struct {
+	deadLetters []deadLetter
+}
+
+func (m *MockDeadLetterWriter) WriteDeadLetter(_ context.Context, msg kafka.Message, cause error, attempts int) error {
+	m.deadLetters = append(m.deadLetters, deadLetter{offset: msg.Offset, cause: cause, attempts: attempts})
+	return nil
+}
+
+// RecordingMetricsClient counts the retried and dead-lettered messages
+type RecordingMetricsClient struct {
+	retried      int
+	deadLettered int
+}
+
+func (mc *RecordingMetricsClient) KafkaConsumeTime(_ string, _ time.Time) {}
+
+func (mc *RecordingMetricsClient) KafkaMessageRetried(_ string) {
+	mc.retried++
+}
+
+func (mc *RecordingMetricsClient) KafkaMessageDeadLettered(_ string) {
+	mc.deadLettered++
+}
+
+func newTestLoop(offsets ...int64) (*consumeLoop, *MockReader, *MockDeadLetterWriter, *RecordingMetricsClient) {
+	reader := &MockReader{}
+	for _, offset := range offsets {
+		reader.messages = append(reader.messages, kafka.Message{Offset: offset})
+	}
+	deadLetters := &MockDeadLetterWriter{}
+	metrics := &RecordingMetricsClient{}
+	loop := &consumeLoop{
+		reader:      reader,
+		topic:       "ship-data-topic",
+		metrics:     metrics,
+		deadLetters: deadLetters,
+		maxAttempts: 3,
+		backoff:     backoff.New(time.Millisecond, 5*time.Millisecond),
+	}
+	return loop, reader, deadLetters, metrics
+}
+
+// runUntilIdle runs the loop until it has had time to consume every message
+func runUntilIdle(t *testing.T, loop *consumeLoop, handle messageHandler) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, loop.run(ctx, handle), context.DeadlineExceeded)
+}
+
+func TestConsumeLoop_CommitsHandledMessages(t *testing.T) {
+	loop, reader, deadLetters, _ := newTestLoop(1, 2, 3)
+
+	var handled []int64
+	runUntilIdle(t, loop, func(_ context.Context, m *kafka.Message) error {
+		handled = append(handled, m.Offset)
+		return nil
+	})
+
+	assert.Equal(t, []int64{1, 2, 3}, handled)
+	assert.Equal(t, []int64{1, 2, 3}, reader.committed)
+	assert.Empty(t, deadLetters.deadLetters)
+}
+
+func TestConsumeLoop_RetriesTransientErrors(t *testing.T) {
+	loop, reader, deadLetters, metrics := newTestLoop(1)
+
+	attempts := 0
+	runUntilIdle(t, loop, func(_ context.Context, _ *kafka.Message) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("constraint violated")
+		}
+		return nil
+	})
+
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 2, metrics.retried)
+	assert.Equal(t, []int64{1}, reader.committed)
+	assert.Empty(t, deadLetters.deadLetters)
+}
+
+func TestConsumeLoop_DeadLettersFailingMessages(t *testing.T) {
+	loop, reader, deadLetters, metrics := newTestLoop(1, 2, 3)
+	decodeErr := errors.New("invalid key")
+	storeErr := errors.New("constraint violated")
+
+	runUntilIdle(t, loop, func(_ context.Context, m *kafka.Message) error {
+		switch m.Offset {
+		case 1:
+			return permanent(decodeErr)
+		case 2:
+			return storeErr
+		default:
+			return nil
+		}
+	})
+
+	// permanent errors aren't retried, transient errors are retried up to the maximum number of attempts and the
+	// consumer carries on with the next message either way
+	require.Len(t, deadLetters.deadLetters, 2)
+	assert.Equal(t, int64(1), deadLetters.deadLetters[0].offset)
+	assert.ErrorIs(t, deadLetters.deadLetters[0].cause, decodeErr)
+	assert.Equal(t, 1, deadLetters.deadLetters[0].attempts)
+	assert.Equal(t, int64(2), deadLetters.deadLetters[1].offset)
+	assert.ErrorIs(t, deadLetters.deadLetters[1].cause, storeErr)
+	assert.Equal(t, 3, deadLetters.deadLetters[1].attempts)
+	assert.Equal(t, 2, metrics.retried)
+	assert.Equal(t, 2, metrics.deadLettered)
+	assert.Equal(t, []int64{1, 2, 3}, reader.committed)
+}
+
+func TestConsumeLoop_RetriesUnavailableStoreWithoutDeadLettering(t *testing.T) {
+	loop, reader, deadLetters, metrics := newTestLoop(1, 2)
+
+	// the store is unavailable for more than the maximum number of attempts
+	attempts := 0
+	runUntilIdle(t, loop, func(_ context.Context, m *kafka.Message) error {
+		if m.Offset != 1 {
+			return nil
+		}
+		attempts++
+		if attempts <= 2*loop.maxAttempts {
+			return fmt.Errorf("error on inserting ship: %w",
+				apperrors.NewStoreUnavailableErr(errors.New("connection refused")))
+		}
+		return nil
+	})
+
+	assert.Equal(t, 2*loop.maxAttempts+1, attempts)
+	assert.Equal(t, 2*loop.maxAttempts, metrics.retried)
+	assert.Empty(t, deadLetters.deadLetters)
+	assert.Zero(t, metrics.deadLettered)
+	assert.Equal(t, []int64{1, 2}, reader.committed)
+}
+
+func TestConsumeLoop_LeavesMessageUncommittedOnCancellation(t *testing.T) {
+	loop, reader, deadLetters, _ := newTestLoop(1, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := loop.run(ctx, func(ctx context.Context, m *kafka.Message) error {
+		if m.Offset == 2 {
+			cancel()
+			return ctx.Err()
+		}
+		return nil
+	})
+
+	// the message being handled is redelivered when the consumer restarts as its offset was never committed
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int64{1}, reader.committed)
+	assert.Empty(t, deadLetters.deadLetters)
+}
@@ -0,0 +1,104 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/internal/core/ports"
+	kafka2 "github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka"
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+// SafetyAlertConsumer reads the safety alert topic twice: once as part of the shared consumer group, so that each
+// alert is stored by one instance, and once as part of a consumer group unique to this instance, so that every
+// instance notifies its own subscribers of every alert
+type SafetyAlertConsumer struct {
+	storeLoop  *consumeLoop
+	notifyLoop *consumeLoop
+	service    ports.SafetyAlertService
+}
+
+func NewSafetyAlertConsumer(cfg config.Config, service ports.SafetyAlertService, deadLetters DeadLetterWriter,
+	metrics kafka2.Metrics) (*SafetyAlertConsumer, error) {
+	notifyGroup := cfg.KafkaSafetyAlertConsumerGroup
+	if notifyGroup == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname for safety alert consumer group: %w", err)
+		}
+		notifyGroup = fmt.Sprintf("%s-safety-alerts-%s", cfg.KafkaConsumerGroup, hostname)
+	}
+	return &SafetyAlertConsumer{
+		storeLoop: newConsumeLoop(cfg, cfg.KafkaSafetyAlertTopic, deadLetters, metrics),
+		// subscribers are only interested in new alerts, so an instance starting for the first time doesn't notify
+		// them of the alerts already on the topic
+		notifyLoop: newGroupConsumeLoop(cfg, cfg.KafkaSafetyAlertTopic, notifyGroup, kafka.LastOffset, deadLetters,
+			metrics),
+		service: service,
+	}, nil
+}
+
+// Read consumes safety alerts until the context is cancelled, committing the offset of each alert once it has been
+// stored (or notified) or dead-lettered. Redelivered alerts are ignored by the safety alert service.
+func (c *SafetyAlertConsumer) Read(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	notifyErr := make(chan error, 1)
+	go func() {
+		notifyErr <- c.notifyLoop.run(ctx, c.notify)
+	}()
+	err := c.storeLoop.run(ctx, c.store)
+	// stop notifying if storing stops (and vice versa) so that the consumer is restarted as a whole
+	cancel()
+	if nErr := <-notifyErr; err == nil || errors.Is(err, context.Canceled) {
+		err = nErr
+	}
+	return err
+}
+
+func (c *SafetyAlertConsumer) store(ctx context.Context, m *kafka.Message) error {
+	alert, err := safetyAlertFromKafkaMsg(m)
+	if err != nil {
+		return err
+	}
+	clog.Infof("🆘: %v", alert)
+
+	err = c.service.StoreSafetyAlerts(ctx, []domain.SafetyAlert{*alert})
+	if err != nil {
+		return fmt.Errorf("error on storing safety alert: %w", err)
+	}
+	return nil
+}
+
+func (c *SafetyAlertConsumer) notify(_ context.Context, m *kafka.Message) error {
+	alert, err := safetyAlertFromKafkaMsg(m)
+	if err != nil {
+		return err
+	}
+	c.service.NotifySafetyAlerts([]domain.SafetyAlert{*alert})
+	return nil
+}
+
+func safetyAlertFromKafkaMsg(m *kafka.Message) (*domain.SafetyAlert, error) {
+	dto, err := kafka2.NewSafetyAlertDTOFromKafkaMsg(m)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("error on generating safety alert DTO from Kafka message: %w", err))
+	}
+	alert, err := dto.ToDomainEntity()
+	if err != nil {
+		return nil, permanent(fmt.Errorf("error on converting safety alert DTO to domain entity: %w", err))
+	}
+	return alert, nil
+}
+
+func (c *SafetyAlertConsumer) Shutdown() {
+	c.storeLoop.close()
+	c.notifyLoop.close()
+}
@@ -3,7 +3,6 @@ package consumer
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/segmentio/kafka-go"
 
@@ -15,64 +14,125 @@ import (
 )
 
 type ShipDataConsumer struct {
-	reader        *kafka.Reader
-	service       ports.ShipService
-	searchService ports.ShipSearchService
-	metrics       kafka2.Metrics
+	loop           *consumeLoop
+	service        ports.ShipService
+	stationService ports.StationService
+	searchService  ports.ShipSearchService
+	serializer     *kafka2.Serializer
 }
 
-func NewShipDataConsumer(cfg config.Config, service ports.ShipService, searchService ports.ShipSearchService, metrics kafka2.Metrics) (*ShipDataConsumer, error) {
+func NewShipDataConsumer(cfg config.Config, service ports.ShipService, stationService ports.StationService,
+	searchService ports.ShipSearchService, deadLetters DeadLetterWriter, metrics kafka2.Metrics) (*ShipDataConsumer, error) {
+	serializer, err := kafka2.NewSerializer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise serializer: %w", err)
+	}
 	return &ShipDataConsumer{
-		reader: kafka.NewReader(kafka.ReaderConfig{
-			Brokers:  []string{cfg.KafkaAddress},
-			GroupID:  cfg.KafkaConsumerGroup,
-			Topic:    cfg.KafkaShipDataTopic,
-			MaxBytes: 10e6, // 10MB
-			ErrorLogger: kafka.LoggerFunc(func(msg string, a ...interface{}) {
-				clog.Errorf(msg, a...)
-				fmt.Println()
-			}),
-		}),
-		service:       service,
-		searchService: searchService,
-		metrics:       metrics,
+		loop:           newConsumeLoop(cfg, cfg.KafkaShipDataTopic, deadLetters, metrics),
+		service:        service,
+		stationService: stationService,
+		searchService:  searchService,
+		serializer:     serializer,
 	}, nil
 }
 
+// Read consumes ship data until the context is cancelled, committing the offset of each message once it has been stored
+// or dead-lettered. Every store is an upsert keyed on the MMSI so redelivered messages are harmless.
 func (c *ShipDataConsumer) Read(ctx context.Context) error {
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			start := time.Now()
-			m, err := c.reader.ReadMessage(ctx)
-			if err != nil {
-				return fmt.Errorf("error on reading message: %w", err)
-			}
-			c.metrics.KafkaConsumeTime(c.reader.Config().Topic, start)
-
-			dto, err := kafka2.NewShipDTOFromKafkaMsg(&m)
-			if err != nil {
-				return fmt.Errorf("error on generating DTO from Kafka message: %w", err)
-			}
-			clog.Infof("🚢: %v", dto)
-
-			ship, err := dto.ToDomainEntity()
-			if err != nil {
-				return fmt.Errorf("error on converting ship DTO to domain entity: %w", err)
-			}
-			ships := []domain.Ship{*ship}
-			err = c.service.Store(ctx, ships)
-			if err != nil {
-				return fmt.Errorf("error on storing ship data: %w", err)
-			}
-		}
+	return c.loop.run(ctx, c.handle)
+}
+
+func (c *ShipDataConsumer) handle(ctx context.Context, m *kafka.Message) error {
+	switch messageType := kafka2.MessageType(m); messageType {
+	case kafka2.MessageTypeShipPosition:
+		return c.storeShipPosition(ctx, m)
+	case kafka2.MessageTypeShipStaticData:
+		return c.storeShipStaticData(ctx, m)
+	case kafka2.MessageTypeAidToNavigation:
+		return c.storeAidToNavigation(ctx, m)
+	case kafka2.MessageTypeBaseStation:
+		return c.storeBaseStation(ctx, m)
+	default:
+		clog.Warnw("skipping message of unknown type",
+			"type", messageType,
+			"key", string(m.Key))
+		return nil
 	}
 }
 
-func (c *ShipDataConsumer) Shutdown() {
-	if err := c.reader.Close(); err != nil {
-		clog.Errorf("failed to close Kafka reader: %s", err.Error())
+func (c *ShipDataConsumer) storeShipPosition(ctx context.Context, m *kafka.Message) error {
+	dto, err := kafka2.NewShipDTOFromKafkaMsg(ctx, c.serializer, m)
+	if err != nil {
+		return decodeError(fmt.Errorf("error on generating DTO from Kafka message: %w", err))
+	}
+	clog.Infof("🚢: %v", dto)
+
+	ship, err := dto.ToDomainEntity()
+	if err != nil {
+		return permanent(fmt.Errorf("error on converting ship DTO to domain entity: %w", err))
+	}
+	ships := []domain.Ship{*ship}
+	err = c.service.Store(ctx, ships)
+	if err != nil {
+		return fmt.Errorf("error on storing ship data: %w", err)
+	}
+	return nil
+}
+
+func (c *ShipDataConsumer) storeShipStaticData(ctx context.Context, m *kafka.Message) error {
+	dto, err := kafka2.NewShipStaticDataDTOFromKafkaMsg(m)
+	if err != nil {
+		return permanent(fmt.Errorf("error on generating static data DTO from Kafka message: %w", err))
+	}
+	clog.Infof("📋: %v", dto)
+
+	data, err := dto.ToDomainEntity()
+	if err != nil {
+		return permanent(fmt.Errorf("error on converting ship static data DTO to domain entity: %w", err))
+	}
+	err = c.service.StoreStaticData(ctx, []domain.ShipStaticData{*data})
+	if err != nil {
+		return fmt.Errorf("error on storing ship static data: %w", err)
+	}
+	return nil
+}
+
+func (c *ShipDataConsumer) storeAidToNavigation(ctx context.Context, m *kafka.Message) error {
+	dto, err := kafka2.NewAidToNavigationDTOFromKafkaMsg(m)
+	if err != nil {
+		return permanent(fmt.Errorf("error on generating aid to navigation DTO from Kafka message: %w", err))
+	}
+	clog.Infof("🛟: %v", dto)
+
+	aid, err := dto.ToDomainEntity()
+	if err != nil {
+		return permanent(fmt.Errorf("error on converting aid to navigation DTO to domain entity: %w", err))
+	}
+	err = c.stationService.StoreAidsToNavigation(ctx, []domain.AidToNavigation{*aid})
+	if err != nil {
+		return fmt.Errorf("error on storing aid to navigation: %w", err)
+	}
+	return nil
+}
+
+func (c *ShipDataConsumer) storeBaseStation(ctx context.Context, m *kafka.Message) error {
+	dto, err := kafka2.NewBaseStationDTOFromKafkaMsg(m)
+	if err != nil {
+		return permanent(fmt.Errorf("error on generating base station DTO from Kafka message: %w", err))
+	}
+	clog.Infof("📡: %v", dto)
+
+	station, err := dto.ToDomainEntity()
+	if err != nil {
+		return permanent(fmt.Errorf("error on converting base station DTO to domain entity: %w", err))
+	}
+	err = c.stationService.StoreBaseStations(ctx, []domain.BaseStation{*station})
+	if err != nil {
+		return fmt.Errorf("error on storing base station: %w", err)
 	}
+	return nil
+}
+
+func (c *ShipDataConsumer) Shutdown() {
+	c.loop.close()
 }
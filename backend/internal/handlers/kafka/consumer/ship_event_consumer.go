@@ -3,7 +3,6 @@ package consumer
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/segmentio/kafka-go"
 
@@ -15,62 +14,72 @@ import (
 )
 
 type ShipEventConsumer struct {
-	reader  *kafka.Reader
-	service ports.ShipSearchService
-	metrics kafka2.Metrics
+	loop       *consumeLoop
+	service    ports.ShipSearchService
+	serializer *kafka2.Serializer
 }
 
-func NewShipEventConsumer(cfg config.Config, service ports.ShipSearchService, metrics kafka2.Metrics) (*ShipEventConsumer, error) {
+func NewShipEventConsumer(cfg config.Config, service ports.ShipSearchService, deadLetters DeadLetterWriter,
+	metrics kafka2.Metrics) (*ShipEventConsumer, error) {
+	serializer, err := kafka2.NewSerializer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise serializer: %w", err)
+	}
 	return &ShipEventConsumer{
-		reader: kafka.NewReader(kafka.ReaderConfig{
-			Brokers:  []string{cfg.KafkaAddress},
-			GroupID:  cfg.KafkaConsumerGroup,
-			Topic:    cfg.KafkaShipEventTopic,
-			MaxBytes: 10e6, // 10MB
-			ErrorLogger: kafka.LoggerFunc(func(msg string, a ...interface{}) {
-				clog.Errorf(msg, a...)
-				fmt.Println()
-			}),
-		}),
-		service: service,
-		metrics: metrics,
+		loop:       newConsumeLoop(cfg, cfg.KafkaShipEventTopic, deadLetters, metrics),
+		service:    service,
+		serializer: serializer,
 	}, nil
 }
 
+// Read consumes ship events until the context is cancelled, committing the offset of each event once it has been
+// indexed or dead-lettered. Indexing is an upsert keyed on the MMSI so redelivered events are harmless.
 func (c *ShipEventConsumer) Read(ctx context.Context) error {
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			start := time.Now()
-			m, err := c.reader.ReadMessage(ctx)
-			if err != nil {
-				return fmt.Errorf("error on reading message: %w", err)
-			}
-			c.metrics.KafkaConsumeTime(c.reader.Config().Topic, start)
+	return c.loop.run(ctx, c.handle)
+}
+
+// handle dispatches the event on its type, skipping events of types (or schema versions) that the consumer doesn't
+// understand so that new kinds of event can be published before the consumer knows about them
+func (c *ShipEventConsumer) handle(ctx context.Context, m *kafka.Message) error {
+	envelope, err := kafka2.NewEventEnvelopeFromKafkaMsg(m)
+	if err != nil {
+		return permanent(fmt.Errorf("error on reading event envelope: %w", err))
+	}
+
+	switch {
+	case envelope.Type == kafka2.EventTypeShipLocationUpdated &&
+		envelope.SchemaVersion <= kafka2.ShipLocationUpdatedEventSchemaVersion:
+		return c.indexShipLocation(ctx, m)
+	default:
+		clog.Warnw("skipping event of unknown type",
+			"type", envelope.Type,
+			"schemaVersion", envelope.SchemaVersion,
+			"id", envelope.ID,
+			"source", envelope.Source,
+			"key", string(m.Key))
+		return nil
+	}
+}
 
-			dto, err := kafka2.NewShipLocationUpdatedEventDTOFromKafkaMsg(&m)
-			if err != nil {
-				return fmt.Errorf("error on generating DTO from Kafka message: %w", err)
-			}
-			clog.Infof("🚢: %v", dto)
+func (c *ShipEventConsumer) indexShipLocation(ctx context.Context, m *kafka.Message) error {
+	dto, err := kafka2.NewShipLocationUpdatedEventDTOFromKafkaMsg(ctx, c.serializer, m)
+	if err != nil {
+		return decodeError(fmt.Errorf("error on generating DTO from Kafka message: %w", err))
+	}
+	clog.Infof("🚢: %v", dto)
 
-			shipSearchResult, err := dto.ToDomainEntity()
-			if err != nil {
-				return fmt.Errorf("error on converting ship DTO to domain entity: %w", err)
-			}
+	shipSearchResult, err := dto.ToDomainEntity()
+	if err != nil {
+		return permanent(fmt.Errorf("error on converting ship DTO to domain entity: %w", err))
+	}
 
-			err = c.service.Store(ctx, []domain.ShipSearchResult{*shipSearchResult})
-			if err != nil {
-				return fmt.Errorf("error on storing ship search result: %w", err)
-			}
-		}
+	err = c.service.Store(ctx, []domain.ShipSearchResult{*shipSearchResult})
+	if err != nil {
+		return fmt.Errorf("error on storing ship search result: %w", err)
 	}
+	return nil
 }
 
 func (c *ShipEventConsumer) Shutdown() {
-	if err := c.reader.Close(); err != nil {
-		clog.Errorf("failed to close Kafka reader: %s", err.Error())
-	}
+	c.loop.close()
 }
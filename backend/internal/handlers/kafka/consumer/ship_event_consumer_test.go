@@ -0,0 +1,79 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	kafka2 "github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+// MockShipSearchService records the search results stored
+type MockShipSearchService struct {
+	stored []domain.ShipSearchResult
+}
+
+func (m *MockShipSearchService) Search(_ context.Context, _ string,
+	_ domain.ShipSearchFilter) ([]domain.ShipSearchResult, error) {
+	return nil, nil
+}
+
+func (m *MockShipSearchService) Store(_ context.Context, ships []domain.ShipSearchResult) error {
+	m.stored = append(m.stored, ships...)
+	return nil
+}
+
+func newTestShipEventConsumer(t *testing.T) (*ShipEventConsumer, *MockShipSearchService) {
+	serializer, err := kafka2.NewSerializer(config.Config{KafkaSerializationFormat: kafka2.FormatJSON})
+	require.NoError(t, err)
+	service := &MockShipSearchService{}
+	return &ShipEventConsumer{service: service, serializer: serializer}, service
+}
+
+func TestShipEventConsumer_DispatchesOnEventType(t *testing.T) {
+	consumer, service := newTestShipEventConsumer(t)
+	value := []byte(`{"name":"AUGUSTSON","latitude":66.02695,"longitude":12.253821666666665}`)
+
+	located := kafka2.NewEventEnvelope(kafka2.EventTypeShipLocationUpdated,
+		kafka2.ShipLocationUpdatedEventSchemaVersion, "ship-data-service", "application/json")
+	require.NoError(t, consumer.handle(context.Background(),
+		&kafka.Message{Key: []byte("259000420"), Value: value, Headers: located.Headers()}))
+	// events published before the envelope was introduced
+	require.NoError(t, consumer.handle(context.Background(),
+		&kafka.Message{Key: []byte("259000421"), Value: value}))
+
+	require.Len(t, service.stored, 2)
+	assert.Equal(t, int32(259000420), service.stored[0].MMSI)
+	assert.Equal(t, int32(259000421), service.stored[1].MMSI)
+}
+
+func TestShipEventConsumer_SkipsUnknownEvents(t *testing.T) {
+	consumer, service := newTestShipEventConsumer(t)
+
+	for _, envelope := range []kafka2.EventEnvelope{
+		kafka2.NewEventEnvelope("shiplocator.ship.renamed", 1, "ship-data-service", "application/json"),
+		kafka2.NewEventEnvelope(kafka2.EventTypeShipLocationUpdated,
+			kafka2.ShipLocationUpdatedEventSchemaVersion+1, "ship-data-service", "application/json"),
+	} {
+		err := consumer.handle(context.Background(),
+			&kafka.Message{Key: []byte("259000420"), Value: []byte(`not JSON`), Headers: envelope.Headers()})
+		assert.NoError(t, err)
+	}
+	assert.Empty(t, service.stored)
+}
+
+func TestShipEventConsumer_InvalidEnvelopeIsPermanent(t *testing.T) {
+	consumer, _ := newTestShipEventConsumer(t)
+
+	err := consumer.handle(context.Background(), &kafka.Message{
+		Key:     []byte("259000420"),
+		Headers: []kafka.Header{{Key: kafka2.HeaderEventSpecVersion, Value: []byte("1.0")}},
+	})
+	var permanentErr *permanentError
+	assert.ErrorAs(t, err, &permanentErr)
+}
@@ -0,0 +1,106 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers added to messages routed to the dead-letter topic, describing where the message came from and why it
+// could not be consumed
+const (
+	HeaderDeadLetterError           = "dead-letter-error"
+	HeaderDeadLetterSourceTopic     = "dead-letter-source-topic"
+	HeaderDeadLetterSourcePartition = "dead-letter-source-partition"
+	HeaderDeadLetterSourceOffset    = "dead-letter-source-offset"
+	HeaderDeadLetterAttempts        = "dead-letter-attempts"
+	HeaderDeadLetterFailedAt        = "dead-letter-failed-at"
+)
+
+const deadLetterHeaderPrefix = "dead-letter-"
+
+// DeadLetter describes a message that was routed to the dead-letter topic
+type DeadLetter struct {
+	SourceTopic     string
+	SourcePartition int
+	SourceOffset    int64
+	Error           string
+	// Attempts is the number of times consuming the message was attempted
+	Attempts int
+	FailedAt time.Time
+}
+
+// NewDeadLetterMsg creates the message to publish to the dead-letter topic for a message that could not be consumed.
+// The key, value and headers of the original message are kept so that it can be re-driven to its source topic.
+func NewDeadLetterMsg(m kafka.Message, cause error, attempts int, failedAt time.Time) kafka.Message {
+	headers := make([]kafka.Header, 0, len(m.Headers)+6)
+	headers = append(headers, m.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDeadLetterError, Value: []byte(cause.Error())},
+		kafka.Header{Key: HeaderDeadLetterSourceTopic, Value: []byte(m.Topic)},
+		kafka.Header{Key: HeaderDeadLetterSourcePartition, Value: []byte(strconv.Itoa(m.Partition))},
+		kafka.Header{Key: HeaderDeadLetterSourceOffset, Value: []byte(strconv.FormatInt(m.Offset, 10))},
+		kafka.Header{Key: HeaderDeadLetterAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderDeadLetterFailedAt, Value: []byte(failedAt.UTC().Format(time.RFC3339Nano))},
+	)
+	return kafka.Message{
+		Key:     m.Key,
+		Value:   m.Value,
+		Headers: headers,
+	}
+}
+
+// NewDeadLetterFromKafkaMsg reads the dead-letter headers of a message from the dead-letter topic
+func NewDeadLetterFromKafkaMsg(m *kafka.Message) (*DeadLetter, error) {
+	var dl DeadLetter
+	var err error
+	for _, header := range m.Headers {
+		value := string(header.Value)
+		switch header.Key {
+		case HeaderDeadLetterError:
+			dl.Error = value
+		case HeaderDeadLetterSourceTopic:
+			dl.SourceTopic = value
+		case HeaderDeadLetterSourcePartition:
+			dl.SourcePartition, err = strconv.Atoi(value)
+		case HeaderDeadLetterSourceOffset:
+			dl.SourceOffset, err = strconv.ParseInt(value, 10, 64)
+		case HeaderDeadLetterAttempts:
+			dl.Attempts, err = strconv.Atoi(value)
+		case HeaderDeadLetterFailedAt:
+			dl.FailedAt, err = time.Parse(time.RFC3339Nano, value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid '%s' header: %w", header.Key, err)
+		}
+	}
+	if dl.SourceTopic == "" {
+		return nil, errors.New("message has no source topic header")
+	}
+	return &dl, nil
+}
+
+// NewRedriveMsg recreates the original message from a message on the dead-letter topic, addressed to its source topic
+func NewRedriveMsg(m *kafka.Message) (kafka.Message, error) {
+	dl, err := NewDeadLetterFromKafkaMsg(m)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	var headers []kafka.Header
+	for _, header := range m.Headers {
+		if !strings.HasPrefix(header.Key, deadLetterHeaderPrefix) {
+			headers = append(headers, header)
+		}
+	}
+	return kafka.Message{
+		Topic:   dl.SourceTopic,
+		Key:     m.Key,
+		Value:   m.Value,
+		Headers: headers,
+	}, nil
+}
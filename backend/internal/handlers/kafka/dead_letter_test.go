@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterMsg_RoundTrip(t *testing.T) {
+	failedAt := time.Date(2023, time.September, 11, 17, 4, 5, 0, time.UTC)
+	original := kafka.Message{
+		Topic:     "ship-data-topic",
+		Partition: 2,
+		Offset:    1234,
+		Key:       []byte("259000420"),
+		Value:     []byte(`{"name":"AUGUSTSON"}`),
+		Headers:   []kafka.Header{MessageTypeHeader(MessageTypeShipStaticData)},
+	}
+
+	msg := NewDeadLetterMsg(original, errors.New("invalid key"), 3, failedAt)
+	// the dead-letter topic is set by the writer
+	assert.Empty(t, msg.Topic)
+
+	dl, err := NewDeadLetterFromKafkaMsg(&msg)
+	require.NoError(t, err)
+	assert.Equal(t, DeadLetter{
+		SourceTopic:     "ship-data-topic",
+		SourcePartition: 2,
+		SourceOffset:    1234,
+		Error:           "invalid key",
+		Attempts:        3,
+		FailedAt:        failedAt,
+	}, *dl)
+
+	redrive, err := NewRedriveMsg(&msg)
+	require.NoError(t, err)
+	assert.Equal(t, kafka.Message{
+		Topic:   "ship-data-topic",
+		Key:     original.Key,
+		Value:   original.Value,
+		Headers: original.Headers,
+	}, redrive)
+}
+
+func TestNewDeadLetterFromKafkaMsg_RequiresSourceTopic(t *testing.T) {
+	_, err := NewDeadLetterFromKafkaMsg(&kafka.Message{Key: []byte("259000420")})
+	assert.Error(t, err)
+}
@@ -1,35 +1,60 @@
 package kafka
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"strconv"
 	"time"
 
+	"github.com/hamba/avro/v2"
 	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka/pb"
 )
 
 type shipDTO struct {
-	Key       string  `json:"-"`
-	Name      string  `json:"name"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
+	Key                string   `json:"-"`
+	Name               string   `json:"name"`
+	Latitude           float64  `json:"latitude"`
+	Longitude          float64  `json:"longitude"`
+	SpeedOverGround    *float64 `json:"speedOverGround,omitempty"`
+	CourseOverGround   *float64 `json:"courseOverGround,omitempty"`
+	TrueHeading        *int32   `json:"trueHeading,omitempty"`
+	NavigationalStatus *int32   `json:"navigationalStatus,omitempty"`
+	RateOfTurn         *float64 `json:"rateOfTurn,omitempty"`
+	// RateOfTurnNoTurnIndicator is set when RateOfTurn only holds the rate that a ship without a turn indicator is
+	// known to exceed
+	RateOfTurnNoTurnIndicator bool      `json:"rateOfTurnNoTurnIndicator,omitempty"`
+	TransponderClass          string    `json:"transponderClass,omitempty"`
+	ImplausibleReason         string    `json:"implausibleReason,omitempty"`
+	LastUpdated               time.Time `json:"lastUpdated"`
+	ReceivedAt                time.Time `json:"receivedAt"`
 }
 
 func NewShipDTOFromDomainEntity(s domain.Ship) *shipDTO {
 	return &shipDTO{
-		Key:       strconv.FormatInt(int64(s.MMSI), 10),
-		Name:      s.Name,
-		Latitude:  s.Latitude,
-		Longitude: s.Longitude,
+		Key:                       strconv.FormatInt(int64(s.MMSI), 10),
+		Name:                      s.Name,
+		Latitude:                  s.Latitude,
+		Longitude:                 s.Longitude,
+		SpeedOverGround:           s.SpeedOverGround,
+		CourseOverGround:          s.CourseOverGround,
+		TrueHeading:               s.TrueHeading,
+		NavigationalStatus:        s.NavigationalStatus,
+		RateOfTurn:                s.RateOfTurn,
+		RateOfTurnNoTurnIndicator: s.RateOfTurnNoTurnIndicator,
+		TransponderClass:          string(s.TransponderClass),
+		ImplausibleReason:         string(s.ImplausibleReason),
+		LastUpdated:               s.LastUpdated,
+		ReceivedAt:                s.ReceivedAt,
 	}
 }
 
-func NewShipDTOFromKafkaMsg(msg *kafka.Message) (*shipDTO, error) {
+func NewShipDTOFromKafkaMsg(ctx context.Context, serializer *Serializer, msg *kafka.Message) (*shipDTO, error) {
 	var dto shipDTO
-	err := json.Unmarshal(msg.Value, &dto)
+	err := serializer.Deserialize(ctx, msg.Value, &dto)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal ship data: %w", err)
 	}
@@ -37,12 +62,152 @@ func NewShipDTOFromKafkaMsg(msg *kafka.Message) (*shipDTO, error) {
 	return &dto, err
 }
 
+func (dto *shipDTO) schemaName() string {
+	return "ship_position"
+}
+
+func (dto *shipDTO) recordName() string {
+	return "shiplocator.v1.ShipPosition"
+}
+
+func (dto *shipDTO) marshalProtobuf() ([]byte, error) {
+	return proto.Marshal(&pb.ShipPosition{
+		Name:                      dto.Name,
+		Latitude:                  dto.Latitude,
+		Longitude:                 dto.Longitude,
+		SpeedOverGround:           dto.SpeedOverGround,
+		CourseOverGround:          dto.CourseOverGround,
+		TrueHeading:               dto.TrueHeading,
+		NavigationalStatus:        dto.NavigationalStatus,
+		RateOfTurn:                dto.RateOfTurn,
+		TransponderClass:          dto.TransponderClass,
+		ImplausibleReason:         dto.ImplausibleReason,
+		LastUpdated:               toMicros(dto.LastUpdated),
+		ReceivedAt:                toMicros(dto.ReceivedAt),
+		RateOfTurnNoTurnIndicator: dto.RateOfTurnNoTurnIndicator,
+	})
+}
+
+func (dto *shipDTO) unmarshalProtobuf(b []byte) error {
+	var msg pb.ShipPosition
+	if err := proto.Unmarshal(b, &msg); err != nil {
+		return err
+	}
+	dto.Name = msg.Name
+	dto.Latitude = msg.Latitude
+	dto.Longitude = msg.Longitude
+	dto.SpeedOverGround = msg.SpeedOverGround
+	dto.CourseOverGround = msg.CourseOverGround
+	dto.TrueHeading = msg.TrueHeading
+	dto.NavigationalStatus = msg.NavigationalStatus
+	dto.RateOfTurn = msg.RateOfTurn
+	dto.TransponderClass = msg.TransponderClass
+	dto.ImplausibleReason = msg.ImplausibleReason
+	dto.LastUpdated = fromMicros(msg.LastUpdated)
+	dto.ReceivedAt = fromMicros(msg.ReceivedAt)
+	dto.RateOfTurnNoTurnIndicator = msg.RateOfTurnNoTurnIndicator
+	return nil
+}
+
+// shipPositionAvro is the ShipPosition record in schemas/ship_position.avsc
+type shipPositionAvro struct {
+	Name                      string   `avro:"name"`
+	Latitude                  float64  `avro:"latitude"`
+	Longitude                 float64  `avro:"longitude"`
+	SpeedOverGround           *float64 `avro:"speedOverGround"`
+	CourseOverGround          *float64 `avro:"courseOverGround"`
+	TrueHeading               *int32   `avro:"trueHeading"`
+	NavigationalStatus        *int32   `avro:"navigationalStatus"`
+	RateOfTurn                *float64 `avro:"rateOfTurn"`
+	TransponderClass          string   `avro:"transponderClass"`
+	ImplausibleReason         string   `avro:"implausibleReason"`
+	LastUpdated               int64    `avro:"lastUpdated"`
+	ReceivedAt                int64    `avro:"receivedAt"`
+	RateOfTurnNoTurnIndicator bool     `avro:"rateOfTurnNoTurnIndicator"`
+}
+
+func (dto *shipDTO) marshalAvro(schema avro.Schema) ([]byte, error) {
+	return avro.Marshal(schema, shipPositionAvro{
+		Name:                      dto.Name,
+		Latitude:                  dto.Latitude,
+		Longitude:                 dto.Longitude,
+		SpeedOverGround:           dto.SpeedOverGround,
+		CourseOverGround:          dto.CourseOverGround,
+		TrueHeading:               dto.TrueHeading,
+		NavigationalStatus:        dto.NavigationalStatus,
+		RateOfTurn:                dto.RateOfTurn,
+		TransponderClass:          dto.TransponderClass,
+		ImplausibleReason:         dto.ImplausibleReason,
+		LastUpdated:               toMicros(dto.LastUpdated),
+		ReceivedAt:                toMicros(dto.ReceivedAt),
+		RateOfTurnNoTurnIndicator: dto.RateOfTurnNoTurnIndicator,
+	})
+}
+
+func (dto *shipDTO) unmarshalAvro(schema avro.Schema, b []byte) error {
+	var record shipPositionAvro
+	if err := avro.Unmarshal(schema, b, &record); err != nil {
+		return err
+	}
+	dto.Name = record.Name
+	dto.Latitude = record.Latitude
+	dto.Longitude = record.Longitude
+	dto.SpeedOverGround = record.SpeedOverGround
+	dto.CourseOverGround = record.CourseOverGround
+	dto.TrueHeading = record.TrueHeading
+	dto.NavigationalStatus = record.NavigationalStatus
+	dto.RateOfTurn = record.RateOfTurn
+	dto.TransponderClass = record.TransponderClass
+	dto.ImplausibleReason = record.ImplausibleReason
+	dto.LastUpdated = fromMicros(record.LastUpdated)
+	dto.ReceivedAt = fromMicros(record.ReceivedAt)
+	dto.RateOfTurnNoTurnIndicator = record.RateOfTurnNoTurnIndicator
+	return nil
+}
+
+// toMicros converts a time to microseconds since the Unix epoch for the Protobuf and Avro encodings, using 0 for the
+// zero time
+func toMicros(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMicro()
+}
+
+func fromMicros(micros int64) time.Time {
+	if micros == 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(micros).UTC()
+}
+
 func (dto *shipDTO) ToDomainEntity() (*domain.Ship, error) {
 	mmsi, err := strconv.ParseInt(dto.Key, 10, 32)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert key '%s' to integer: %w", dto.Key, err)
 	}
-	return domain.NewShip(int32(mmsi), dto.Name, dto.Latitude, dto.Longitude, time.Now()), nil
+	// messages published before observation times were added to the DTO are treated as being observed now
+	lastUpdated := dto.LastUpdated
+	if lastUpdated.IsZero() {
+		lastUpdated = time.Now()
+	}
+	receivedAt := dto.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = lastUpdated
+	}
+	ship := domain.NewShip(int32(mmsi), dto.Name, dto.Latitude, dto.Longitude, lastUpdated)
+	ship.ReceivedAt = receivedAt.UTC()
+	ship.Kinematics = domain.Kinematics{
+		SpeedOverGround:           dto.SpeedOverGround,
+		CourseOverGround:          dto.CourseOverGround,
+		TrueHeading:               dto.TrueHeading,
+		NavigationalStatus:        dto.NavigationalStatus,
+		RateOfTurn:                dto.RateOfTurn,
+		RateOfTurnNoTurnIndicator: dto.RateOfTurnNoTurnIndicator,
+	}
+	ship.TransponderClass = domain.TransponderClass(dto.TransponderClass)
+	ship.ImplausibleReason = domain.ImplausibleReason(dto.ImplausibleReason)
+	return ship, nil
 }
 
 func (dto *shipDTO) ToDomainSearchResult() (*domain.ShipSearchResult, error) {
@@ -1,7 +1,10 @@
 package kafka
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +34,7 @@ func TestNewShipDTOFromKafkaMsg(t *testing.T) {
 		Value: []byte(`{"name":"AUGUSTSON", "latitude":66.02695, "longitude":12.253821666666665}`),
 	}
 
-	dto, err := NewShipDTOFromKafkaMsg(msg)
+	dto, err := NewShipDTOFromKafkaMsg(context.Background(), jsonSerializer, msg)
 	require.NoError(t, err)
 	assert.Equal(t, "259000420", dto.Key)
 	assert.Equal(t, "AUGUSTSON", dto.Name)
@@ -54,3 +57,224 @@ func TestToDomainEntity(t *testing.T) {
 	assert.Equal(t, 66.02695, entity.Latitude)
 	assert.Equal(t, 12.253821666666665, entity.Longitude)
 }
+
+func TestShipStaticDataDTO_RoundTrip(t *testing.T) {
+	eta := time.Date(2023, time.December, 24, 18, 30, 0, 0, time.UTC)
+	lastUpdated := time.Date(2023, time.December, 20, 12, 0, 0, 0, time.UTC)
+	data := domain.ShipStaticData{
+		MMSI:                 259000420,
+		Name:                 "AUGUSTSON",
+		IMONumber:            9000000,
+		CallSign:             "LAGV",
+		ShipType:             70,
+		DimensionToBow:       40,
+		DimensionToStern:     10,
+		DimensionToPort:      5,
+		DimensionToStarboard: 6,
+		Draught:              5.2,
+		Destination:          "BODO",
+		ETA:                  &eta,
+		LastUpdated:          lastUpdated,
+	}
+
+	dto := NewShipStaticDataDTOFromDomainEntity(data)
+	assert.Equal(t, "259000420", dto.Key)
+	b, err := json.Marshal(dto)
+	require.NoError(t, err)
+
+	msg := &kafka.Message{
+		Key:     []byte(dto.Key),
+		Value:   b,
+		Headers: []kafka.Header{MessageTypeHeader(MessageTypeShipStaticData)},
+	}
+	assert.Equal(t, MessageTypeShipStaticData, MessageType(msg))
+
+	decoded, err := NewShipStaticDataDTOFromKafkaMsg(msg)
+	require.NoError(t, err)
+	entity, err := decoded.ToDomainEntity()
+	require.NoError(t, err)
+	assert.Equal(t, data, *entity)
+}
+
+func TestMessageType_DefaultsToShipPosition(t *testing.T) {
+	msg := &kafka.Message{Key: []byte("259000420")}
+	assert.Equal(t, MessageTypeShipPosition, MessageType(msg))
+}
+
+func TestShipDTO_Kinematics(t *testing.T) {
+	s := domain.Ship{
+		MMSI:       259000420,
+		Name:       "AUGUSTSON",
+		Latitude:   66.02695,
+		Longitude:  12.253821666666665,
+		Kinematics: domain.NewKinematics(12.3, 308, 235, 0, 511),
+	}
+
+	b, err := json.Marshal(NewShipDTOFromDomainEntity(s))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "AUGUSTSON",
+		"latitude": 66.02695,
+		"longitude": 12.253821666666665,
+		"speedOverGround": 12.3,
+		"courseOverGround": 308,
+		"trueHeading": 235,
+		"navigationalStatus": 0,
+		"lastUpdated": "0001-01-01T00:00:00Z",
+		"receivedAt": "0001-01-01T00:00:00Z"
+	}`, string(b))
+
+	dto, err := NewShipDTOFromKafkaMsg(context.Background(), jsonSerializer, &kafka.Message{Key: []byte("259000420"), Value: b})
+	require.NoError(t, err)
+	entity, err := dto.ToDomainEntity()
+	require.NoError(t, err)
+	assert.Equal(t, s.Kinematics, entity.Kinematics)
+}
+
+func TestShipDTO_ObservationAndReceiveTimes(t *testing.T) {
+	observedAt := time.Date(2022, time.December, 29, 18, 22, 31, 0, time.UTC)
+	receivedAt := time.Date(2022, time.December, 29, 18, 25, 0, 0, time.UTC)
+	s := domain.Ship{
+		MMSI:        259000420,
+		Name:        "AUGUSTSON",
+		LastUpdated: observedAt,
+		ReceivedAt:  receivedAt,
+	}
+
+	b, err := json.Marshal(NewShipDTOFromDomainEntity(s))
+	require.NoError(t, err)
+	dto, err := NewShipDTOFromKafkaMsg(context.Background(), jsonSerializer, &kafka.Message{Key: []byte("259000420"), Value: b})
+	require.NoError(t, err)
+	entity, err := dto.ToDomainEntity()
+	require.NoError(t, err)
+	assert.Equal(t, observedAt, entity.LastUpdated)
+	assert.Equal(t, receivedAt, entity.ReceivedAt)
+}
+
+func TestShipDTO_MissingObservationTimeDefaultsToNow(t *testing.T) {
+	dto := &shipDTO{Key: "259000420", Name: "AUGUSTSON"}
+
+	entity, err := dto.ToDomainEntity()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), entity.LastUpdated, 5*time.Second)
+	assert.Equal(t, entity.LastUpdated, entity.ReceivedAt)
+}
+
+func TestShipDTO_TransponderClass(t *testing.T) {
+	s := domain.Ship{
+		MMSI:             235000000,
+		Name:             "SEA BREEZE",
+		TransponderClass: domain.TransponderClassB,
+	}
+
+	b, err := json.Marshal(NewShipDTOFromDomainEntity(s))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"transponderClass":"B"`)
+	dto, err := NewShipDTOFromKafkaMsg(context.Background(), jsonSerializer, &kafka.Message{Key: []byte("235000000"), Value: b})
+	require.NoError(t, err)
+	entity, err := dto.ToDomainEntity()
+	require.NoError(t, err)
+	assert.Equal(t, domain.TransponderClassB, entity.TransponderClass)
+}
+
+func TestShipDTO_ImplausibleReason(t *testing.T) {
+	s := domain.Ship{
+		MMSI:              235000000,
+		Name:              "SEA BREEZE",
+		ImplausibleReason: domain.ImplausibleReasonNullIsland,
+	}
+
+	b, err := json.Marshal(NewShipDTOFromDomainEntity(s))
+	require.NoError(t, err)
+	dto, err := NewShipDTOFromKafkaMsg(context.Background(), jsonSerializer, &kafka.Message{Key: []byte("235000000"), Value: b})
+	require.NoError(t, err)
+	entity, err := dto.ToDomainEntity()
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImplausibleReasonNullIsland, entity.ImplausibleReason)
+}
+
+func TestAidToNavigationDTO_RoundTrip(t *testing.T) {
+	aid := domain.AidToNavigation{
+		MMSI:                 992351000,
+		Name:                 "BRAMBLE BANK",
+		AidType:              20,
+		Latitude:             50.79,
+		Longitude:            -1.29,
+		DimensionToBow:       1,
+		DimensionToStern:     1,
+		DimensionToPort:      1,
+		DimensionToStarboard: 1,
+		VirtualAid:           true,
+		LastUpdated:          time.Date(2023, time.September, 11, 17, 4, 5, 0, time.UTC),
+		ReceivedAt:           time.Date(2023, time.September, 11, 17, 4, 6, 0, time.UTC),
+	}
+
+	dto := NewAidToNavigationDTOFromDomainEntity(aid)
+	assert.Equal(t, "992351000", dto.Key)
+	b, err := json.Marshal(dto)
+	require.NoError(t, err)
+
+	msg := &kafka.Message{
+		Key:     []byte(dto.Key),
+		Value:   b,
+		Headers: []kafka.Header{MessageTypeHeader(MessageTypeAidToNavigation)},
+	}
+	assert.Equal(t, MessageTypeAidToNavigation, MessageType(msg))
+
+	decoded, err := NewAidToNavigationDTOFromKafkaMsg(msg)
+	require.NoError(t, err)
+	entity, err := decoded.ToDomainEntity()
+	require.NoError(t, err)
+	assert.Equal(t, aid, *entity)
+}
+
+func TestBaseStationDTO_RoundTrip(t *testing.T) {
+	station := domain.BaseStation{
+		MMSI:        2320001,
+		Latitude:    50.8,
+		Longitude:   -1.3,
+		LastUpdated: time.Date(2023, time.September, 11, 17, 4, 5, 0, time.UTC),
+		ReceivedAt:  time.Date(2023, time.September, 11, 17, 4, 6, 0, time.UTC),
+	}
+
+	dto := NewBaseStationDTOFromDomainEntity(station)
+	assert.Equal(t, "2320001", dto.Key)
+	b, err := json.Marshal(dto)
+	require.NoError(t, err)
+
+	msg := &kafka.Message{
+		Key:     []byte(dto.Key),
+		Value:   b,
+		Headers: []kafka.Header{MessageTypeHeader(MessageTypeBaseStation)},
+	}
+	assert.Equal(t, MessageTypeBaseStation, MessageType(msg))
+
+	decoded, err := NewBaseStationDTOFromKafkaMsg(msg)
+	require.NoError(t, err)
+	entity, err := decoded.ToDomainEntity()
+	require.NoError(t, err)
+	assert.Equal(t, station, *entity)
+}
+
+func TestSafetyAlertDTO_RoundTrip(t *testing.T) {
+	latitude, longitude := 50.8, -1.3
+	alert := domain.SafetyAlert{
+		MMSI:          972123456,
+		Kind:          domain.SafetyAlertKindBeacon,
+		Latitude:      &latitude,
+		Longitude:     &longitude,
+		TransmittedAt: time.Date(2023, time.September, 11, 17, 4, 5, 0, time.UTC),
+		ReceivedAt:    time.Date(2023, time.September, 11, 17, 4, 6, 0, time.UTC),
+	}
+
+	dto := NewSafetyAlertDTOFromDomainEntity(alert)
+	assert.Equal(t, "972123456", dto.Key)
+	b, err := json.Marshal(dto)
+	require.NoError(t, err)
+
+	decoded, err := NewSafetyAlertDTOFromKafkaMsg(&kafka.Message{Key: []byte(dto.Key), Value: b})
+	require.NoError(t, err)
+	entity, err := decoded.ToDomainEntity()
+	require.NoError(t, err)
+	assert.Equal(t, alert, *entity)
+}
@@ -0,0 +1,119 @@
+package kafka
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+// Kafka headers holding the attributes of the events published to the ship event topic. These follow the binary
+// content mode of the CloudEvents Kafka protocol binding, so the message value is just the event data.
+const (
+	HeaderEventSpecVersion   = "ce_specversion"
+	HeaderEventID            = "ce_id"
+	HeaderEventSource        = "ce_source"
+	HeaderEventType          = "ce_type"
+	HeaderEventTime          = "ce_time"
+	HeaderEventSchemaVersion = "ce_schemaversion"
+	HeaderContentType        = "content-type"
+)
+
+const eventSpecVersion = "1.0"
+
+const (
+	EventTypeShipLocationUpdated = "shiplocator.ship.location-updated"
+)
+
+// ShipLocationUpdatedEventSchemaVersion is the version of the ship location updated event data, which is only bumped
+// for changes that existing consumers can't read
+const ShipLocationUpdatedEventSchemaVersion = 1
+
+// EventEnvelope holds the attributes describing an event published to the ship event topic
+type EventEnvelope struct {
+	// ID uniquely identifies the event (together with the source) so that consumers can deduplicate redeliveries
+	ID            string
+	Source        string
+	Type          string
+	Time          time.Time
+	SchemaVersion int
+	ContentType   string
+}
+
+// NewEventEnvelope returns the envelope for a new event of the given type, published now by the given source
+func NewEventEnvelope(eventType string, schemaVersion int, source, contentType string) EventEnvelope {
+	return EventEnvelope{
+		ID:            uuid.NewString(),
+		Source:        source,
+		Type:          eventType,
+		Time:          time.Now().UTC(),
+		SchemaVersion: schemaVersion,
+		ContentType:   contentType,
+	}
+}
+
+// NewEventEnvelopeFromKafkaMsg reads the envelope from the headers of the given message. Messages without a spec
+// version header pre-date the envelope being introduced and are assumed to be version 1 ship location updated events.
+func NewEventEnvelopeFromKafkaMsg(msg *kafka.Message) (EventEnvelope, error) {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+
+	specVersion, found := headers[HeaderEventSpecVersion]
+	if !found {
+		return EventEnvelope{
+			Type:          EventTypeShipLocationUpdated,
+			SchemaVersion: ShipLocationUpdatedEventSchemaVersion,
+		}, nil
+	}
+	if specVersion != eventSpecVersion {
+		return EventEnvelope{}, fmt.Errorf("unsupported event spec version '%s'", specVersion)
+	}
+
+	for _, header := range []string{HeaderEventID, HeaderEventSource, HeaderEventType} {
+		if headers[header] == "" {
+			return EventEnvelope{}, fmt.Errorf("missing '%s' header", header)
+		}
+	}
+	envelope := EventEnvelope{
+		ID:          headers[HeaderEventID],
+		Source:      headers[HeaderEventSource],
+		Type:        headers[HeaderEventType],
+		ContentType: headers[HeaderContentType],
+	}
+	if value, found := headers[HeaderEventTime]; found {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return EventEnvelope{}, fmt.Errorf("invalid '%s' header '%s': %w", HeaderEventTime, value, err)
+		}
+		envelope.Time = t
+	}
+	envelope.SchemaVersion = 1
+	if value, found := headers[HeaderEventSchemaVersion]; found {
+		version, err := strconv.Atoi(value)
+		if err != nil {
+			return EventEnvelope{}, fmt.Errorf("invalid '%s' header '%s': %w", HeaderEventSchemaVersion, value, err)
+		}
+		envelope.SchemaVersion = version
+	}
+	return envelope, nil
+}
+
+// Headers returns the Kafka headers holding the envelope's attributes
+func (e EventEnvelope) Headers() []kafka.Header {
+	headers := []kafka.Header{
+		{Key: HeaderEventSpecVersion, Value: []byte(eventSpecVersion)},
+		{Key: HeaderEventID, Value: []byte(e.ID)},
+		{Key: HeaderEventSource, Value: []byte(e.Source)},
+		{Key: HeaderEventType, Value: []byte(e.Type)},
+		{Key: HeaderEventTime, Value: []byte(e.Time.Format(time.RFC3339Nano))},
+		{Key: HeaderEventSchemaVersion, Value: []byte(strconv.Itoa(e.SchemaVersion))},
+	}
+	if e.ContentType != "" {
+		headers = append(headers, kafka.Header{Key: HeaderContentType, Value: []byte(e.ContentType)})
+	}
+	return headers
+}
@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventEnvelope_RoundTrip(t *testing.T) {
+	envelope := NewEventEnvelope(EventTypeShipLocationUpdated, ShipLocationUpdatedEventSchemaVersion,
+		"ship-data-service", "application/x-protobuf")
+	assert.NotEmpty(t, envelope.ID)
+	assert.NotEqual(t, envelope.ID, NewEventEnvelope(EventTypeShipLocationUpdated, 1, "ship-data-service", "").ID)
+
+	decoded, err := NewEventEnvelopeFromKafkaMsg(&kafka.Message{Headers: envelope.Headers()})
+	require.NoError(t, err)
+	assert.Equal(t, envelope, decoded)
+}
+
+func TestEventEnvelope_MissingEnvelopeDefaultsToShipLocationUpdated(t *testing.T) {
+	envelope, err := NewEventEnvelopeFromKafkaMsg(&kafka.Message{Key: []byte("259000420")})
+	require.NoError(t, err)
+	assert.Equal(t, EventTypeShipLocationUpdated, envelope.Type)
+	assert.Equal(t, ShipLocationUpdatedEventSchemaVersion, envelope.SchemaVersion)
+}
+
+func TestEventEnvelope_InvalidHeaders(t *testing.T) {
+	valid := NewEventEnvelope("shiplocator.ship.renamed", 2, "ship-data-service", "").Headers()
+	tests := map[string]kafka.Header{
+		"unsupported spec version": {Key: HeaderEventSpecVersion, Value: []byte("0.3")},
+		"missing ID":               {Key: HeaderEventID, Value: nil},
+		"invalid time":             {Key: HeaderEventTime, Value: []byte("yesterday")},
+		"invalid schema version":   {Key: HeaderEventSchemaVersion, Value: []byte("v2")},
+	}
+	for name, replacement := range tests {
+		t.Run(name, func(t *testing.T) {
+			headers := make([]kafka.Header, 0, len(valid))
+			for _, header := range valid {
+				if header.Key == replacement.Key {
+					header = replacement
+				}
+				headers = append(headers, header)
+			}
+			_, err := NewEventEnvelopeFromKafkaMsg(&kafka.Message{Headers: headers})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package kafka
+
+import "github.com/segmentio/kafka-go"
+
+// HeaderMessageType is the Kafka header used to distinguish between the kinds of message published to the
+// ship data topic
+const HeaderMessageType = "message-type"
+
+const (
+	MessageTypeShipPosition    = "ship-position"
+	MessageTypeShipStaticData  = "ship-static-data"
+	MessageTypeAidToNavigation = "aid-to-navigation"
+	MessageTypeBaseStation     = "base-station"
+)
+
+// MessageType returns the kind of message held in the given Kafka message. Messages without a type header
+// pre-date the header being introduced and are assumed to be ship positions.
+func MessageType(msg *kafka.Message) string {
+	for _, header := range msg.Headers {
+		if header.Key == HeaderMessageType {
+			return string(header.Value)
+		}
+	}
+	return MessageTypeShipPosition
+}
+
+// MessageTypeHeader returns the header used to mark a message as being of the given kind
+func MessageTypeHeader(messageType string) kafka.Header {
+	return kafka.Header{Key: HeaderMessageType, Value: []byte(messageType)}
+}
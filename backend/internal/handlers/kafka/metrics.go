@@ -4,4 +4,6 @@ import "time"
 
 type Metrics interface {
 	KafkaConsumeTime(topic string, startTime time.Time)
+	KafkaMessageRetried(topic string)
+	KafkaMessageDeadLettered(topic string)
 }
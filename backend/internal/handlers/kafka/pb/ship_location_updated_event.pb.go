@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: ship_location_updated_event.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ShipLocationUpdatedEvent is published to the ship event topic, keyed by the ship's MMSI, once a ship's position has
+// been stored
+type ShipLocationUpdatedEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name      string  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Latitude  float64 `protobuf:"fixed64,2,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,3,opt,name=longitude,proto3" json:"longitude,omitempty"`
+}
+
+func (x *ShipLocationUpdatedEvent) Reset() {
+	*x = ShipLocationUpdatedEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ship_location_updated_event_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShipLocationUpdatedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShipLocationUpdatedEvent) ProtoMessage() {}
+
+func (x *ShipLocationUpdatedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_ship_location_updated_event_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShipLocationUpdatedEvent.ProtoReflect.Descriptor instead.
+func (*ShipLocationUpdatedEvent) Descriptor() ([]byte, []int) {
+	return file_ship_location_updated_event_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ShipLocationUpdatedEvent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ShipLocationUpdatedEvent) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *ShipLocationUpdatedEvent) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+var File_ship_location_updated_event_proto protoreflect.FileDescriptor
+
+var file_ship_location_updated_event_proto_rawDesc = []byte{
+	0x0a, 0x21, 0x73, 0x68, 0x69, 0x70, 0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x73, 0x68, 0x69, 0x70, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72,
+	0x2e, 0x76, 0x31, 0x22, 0x68, 0x0a, 0x18, 0x53, 0x68, 0x69, 0x70, 0x4c, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12,
+	0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x42, 0x47, 0x5a,
+	0x45, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x65,
+	0x65, 0x77, 0x68, 0x69, 0x74, 0x65, 0x2f, 0x73, 0x68, 0x69, 0x70, 0x2d, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x6f, 0x72, 0x2f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x72, 0x73, 0x2f, 0x6b, 0x61,
+	0x66, 0x6b, 0x61, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_ship_location_updated_event_proto_rawDescOnce sync.Once
+	file_ship_location_updated_event_proto_rawDescData = file_ship_location_updated_event_proto_rawDesc
+)
+
+func file_ship_location_updated_event_proto_rawDescGZIP() []byte {
+	file_ship_location_updated_event_proto_rawDescOnce.Do(func() {
+		file_ship_location_updated_event_proto_rawDescData = protoimpl.X.CompressGZIP(file_ship_location_updated_event_proto_rawDescData)
+	})
+	return file_ship_location_updated_event_proto_rawDescData
+}
+
+var file_ship_location_updated_event_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_ship_location_updated_event_proto_goTypes = []interface{}{
+	(*ShipLocationUpdatedEvent)(nil), // 0: shiplocator.v1.ShipLocationUpdatedEvent
+}
+var file_ship_location_updated_event_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_ship_location_updated_event_proto_init() }
+func file_ship_location_updated_event_proto_init() {
+	if File_ship_location_updated_event_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_ship_location_updated_event_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShipLocationUpdatedEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_ship_location_updated_event_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_ship_location_updated_event_proto_goTypes,
+		DependencyIndexes: file_ship_location_updated_event_proto_depIdxs,
+		MessageInfos:      file_ship_location_updated_event_proto_msgTypes,
+	}.Build()
+	File_ship_location_updated_event_proto = out.File
+	file_ship_location_updated_event_proto_rawDesc = nil
+	file_ship_location_updated_event_proto_goTypes = nil
+	file_ship_location_updated_event_proto_depIdxs = nil
+}
@@ -0,0 +1,285 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: ship_position.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ShipPosition is published to the ship data topic, keyed by the ship's MMSI, whenever a position report is received
+type ShipPosition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name               string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Latitude           float64  `protobuf:"fixed64,2,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude          float64  `protobuf:"fixed64,3,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	SpeedOverGround    *float64 `protobuf:"fixed64,4,opt,name=speed_over_ground,json=speedOverGround,proto3,oneof" json:"speed_over_ground,omitempty"`
+	CourseOverGround   *float64 `protobuf:"fixed64,5,opt,name=course_over_ground,json=courseOverGround,proto3,oneof" json:"course_over_ground,omitempty"`
+	TrueHeading        *int32   `protobuf:"varint,6,opt,name=true_heading,json=trueHeading,proto3,oneof" json:"true_heading,omitempty"`
+	NavigationalStatus *int32   `protobuf:"varint,7,opt,name=navigational_status,json=navigationalStatus,proto3,oneof" json:"navigational_status,omitempty"`
+	RateOfTurn         *float64 `protobuf:"fixed64,8,opt,name=rate_of_turn,json=rateOfTurn,proto3,oneof" json:"rate_of_turn,omitempty"`
+	TransponderClass   string   `protobuf:"bytes,9,opt,name=transponder_class,json=transponderClass,proto3" json:"transponder_class,omitempty"`
+	ImplausibleReason  string   `protobuf:"bytes,10,opt,name=implausible_reason,json=implausibleReason,proto3" json:"implausible_reason,omitempty"`
+	// microseconds since the Unix epoch (0 if unknown)
+	LastUpdated int64 `protobuf:"varint,11,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+	ReceivedAt  int64 `protobuf:"varint,12,opt,name=received_at,json=receivedAt,proto3" json:"received_at,omitempty"`
+	// set when rate_of_turn only holds the rate that a ship without a turn indicator is known to exceed
+	RateOfTurnNoTurnIndicator bool `protobuf:"varint,13,opt,name=rate_of_turn_no_turn_indicator,json=rateOfTurnNoTurnIndicator,proto3" json:"rate_of_turn_no_turn_indicator,omitempty"`
+}
+
+func (x *ShipPosition) Reset() {
+	*x = ShipPosition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ship_position_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShipPosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShipPosition) ProtoMessage() {}
+
+func (x *ShipPosition) ProtoReflect() protoreflect.Message {
+	mi := &file_ship_position_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShipPosition.ProtoReflect.Descriptor instead.
+func (*ShipPosition) Descriptor() ([]byte, []int) {
+	return file_ship_position_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ShipPosition) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ShipPosition) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *ShipPosition) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *ShipPosition) GetSpeedOverGround() float64 {
+	if x != nil && x.SpeedOverGround != nil {
+		return *x.SpeedOverGround
+	}
+	return 0
+}
+
+func (x *ShipPosition) GetCourseOverGround() float64 {
+	if x != nil && x.CourseOverGround != nil {
+		return *x.CourseOverGround
+	}
+	return 0
+}
+
+func (x *ShipPosition) GetTrueHeading() int32 {
+	if x != nil && x.TrueHeading != nil {
+		return *x.TrueHeading
+	}
+	return 0
+}
+
+func (x *ShipPosition) GetNavigationalStatus() int32 {
+	if x != nil && x.NavigationalStatus != nil {
+		return *x.NavigationalStatus
+	}
+	return 0
+}
+
+func (x *ShipPosition) GetRateOfTurn() float64 {
+	if x != nil && x.RateOfTurn != nil {
+		return *x.RateOfTurn
+	}
+	return 0
+}
+
+func (x *ShipPosition) GetTransponderClass() string {
+	if x != nil {
+		return x.TransponderClass
+	}
+	return ""
+}
+
+func (x *ShipPosition) GetImplausibleReason() string {
+	if x != nil {
+		return x.ImplausibleReason
+	}
+	return ""
+}
+
+func (x *ShipPosition) GetLastUpdated() int64 {
+	if x != nil {
+		return x.LastUpdated
+	}
+	return 0
+}
+
+func (x *ShipPosition) GetReceivedAt() int64 {
+	if x != nil {
+		return x.ReceivedAt
+	}
+	return 0
+}
+
+func (x *ShipPosition) GetRateOfTurnNoTurnIndicator() bool {
+	if x != nil {
+		return x.RateOfTurnNoTurnIndicator
+	}
+	return false
+}
+
+var File_ship_position_proto protoreflect.FileDescriptor
+
+var file_ship_position_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x73, 0x68, 0x69, 0x70, 0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x73, 0x68, 0x69, 0x70, 0x6c, 0x6f, 0x63, 0x61, 0x74,
+	0x6f, 0x72, 0x2e, 0x76, 0x31, 0x22, 0x8f, 0x05, 0x0a, 0x0c, 0x53, 0x68, 0x69, 0x70, 0x50, 0x6f,
+	0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61,
+	0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61,
+	0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74,
+	0x75, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69,
+	0x74, 0x75, 0x64, 0x65, 0x12, 0x2f, 0x0a, 0x11, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x6f, 0x76,
+	0x65, 0x72, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x00, 0x52, 0x0f, 0x73, 0x70, 0x65, 0x65, 0x64, 0x4f, 0x76, 0x65, 0x72, 0x47, 0x72, 0x6f, 0x75,
+	0x6e, 0x64, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x12, 0x63, 0x6f, 0x75, 0x72, 0x73, 0x65, 0x5f,
+	0x6f, 0x76, 0x65, 0x72, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x01, 0x48, 0x01, 0x52, 0x10, 0x63, 0x6f, 0x75, 0x72, 0x73, 0x65, 0x4f, 0x76, 0x65, 0x72, 0x47,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0c, 0x74, 0x72, 0x75, 0x65,
+	0x5f, 0x68, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02,
+	0x52, 0x0b, 0x74, 0x72, 0x75, 0x65, 0x48, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x88, 0x01, 0x01,
+	0x12, 0x34, 0x0a, 0x13, 0x6e, 0x61, 0x76, 0x69, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c,
+	0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03, 0x52,
+	0x12, 0x6e, 0x61, 0x76, 0x69, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0c, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x6f,
+	0x66, 0x5f, 0x74, 0x75, 0x72, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x48, 0x04, 0x52, 0x0a,
+	0x72, 0x61, 0x74, 0x65, 0x4f, 0x66, 0x54, 0x75, 0x72, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a,
+	0x11, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x6e, 0x64, 0x65, 0x72, 0x5f, 0x63, 0x6c, 0x61,
+	0x73, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70,
+	0x6f, 0x6e, 0x64, 0x65, 0x72, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x69, 0x6d,
+	0x70, 0x6c, 0x61, 0x75, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x69, 0x6d, 0x70, 0x6c, 0x61, 0x75, 0x73, 0x69,
+	0x62, 0x6c, 0x65, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x61, 0x73,
+	0x74, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0b, 0x6c, 0x61, 0x73, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1f, 0x0a, 0x0b,
+	0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x41, 0x74, 0x12, 0x41, 0x0a,
+	0x1e, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x6f, 0x66, 0x5f, 0x74, 0x75, 0x72, 0x6e, 0x5f, 0x6e, 0x6f,
+	0x5f, 0x74, 0x75, 0x72, 0x6e, 0x5f, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x19, 0x72, 0x61, 0x74, 0x65, 0x4f, 0x66, 0x54, 0x75, 0x72,
+	0x6e, 0x4e, 0x6f, 0x54, 0x75, 0x72, 0x6e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72,
+	0x42, 0x14, 0x0a, 0x12, 0x5f, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x6f, 0x76, 0x65, 0x72, 0x5f,
+	0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x42, 0x15, 0x0a, 0x13, 0x5f, 0x63, 0x6f, 0x75, 0x72, 0x73,
+	0x65, 0x5f, 0x6f, 0x76, 0x65, 0x72, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x42, 0x0f, 0x0a,
+	0x0d, 0x5f, 0x74, 0x72, 0x75, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x16,
+	0x0a, 0x14, 0x5f, 0x6e, 0x61, 0x76, 0x69, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x5f,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x5f,
+	0x6f, 0x66, 0x5f, 0x74, 0x75, 0x72, 0x6e, 0x42, 0x47, 0x5a, 0x45, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x65, 0x65, 0x77, 0x68, 0x69, 0x74, 0x65,
+	0x2f, 0x73, 0x68, 0x69, 0x70, 0x2d, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x2f, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x68,
+	0x61, 0x6e, 0x64, 0x6c, 0x65, 0x72, 0x73, 0x2f, 0x6b, 0x61, 0x66, 0x6b, 0x61, 0x2f, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_ship_position_proto_rawDescOnce sync.Once
+	file_ship_position_proto_rawDescData = file_ship_position_proto_rawDesc
+)
+
+func file_ship_position_proto_rawDescGZIP() []byte {
+	file_ship_position_proto_rawDescOnce.Do(func() {
+		file_ship_position_proto_rawDescData = protoimpl.X.CompressGZIP(file_ship_position_proto_rawDescData)
+	})
+	return file_ship_position_proto_rawDescData
+}
+
+var file_ship_position_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_ship_position_proto_goTypes = []interface{}{
+	(*ShipPosition)(nil), // 0: shiplocator.v1.ShipPosition
+}
+var file_ship_position_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_ship_position_proto_init() }
+func file_ship_position_proto_init() {
+	if File_ship_position_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_ship_position_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShipPosition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_ship_position_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_ship_position_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_ship_position_proto_goTypes,
+		DependencyIndexes: file_ship_position_proto_depIdxs,
+		MessageInfos:      file_ship_position_proto_msgTypes,
+	}.Build()
+	File_ship_position_proto = out.File
+	file_ship_position_proto_rawDesc = nil
+	file_ship_position_proto_goTypes = nil
+	file_ship_position_proto_depIdxs = nil
+}
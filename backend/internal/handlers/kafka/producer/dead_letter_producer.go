@@ -0,0 +1,52 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	kafka2 "github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka"
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+// DeadLetterProducer publishes messages that could not be consumed to the dead-letter topic, and re-drives them back to
+// the topics they came from
+type DeadLetterProducer struct {
+	writer *kafka.Writer
+	// redriveWriter has no topic as each re-driven message is addressed to its own source topic
+	redriveWriter *kafka.Writer
+}
+
+func NewDeadLetterProducer(cfg config.Config) (*DeadLetterProducer, error) {
+	return &DeadLetterProducer{
+		writer:        newWriter(cfg, cfg.KafkaDeadLetterTopic),
+		redriveWriter: newWriter(cfg, ""),
+	}, nil
+}
+
+// WriteDeadLetter publishes the message along with headers describing its source and the error that stopped it from
+// being consumed
+func (p *DeadLetterProducer) WriteDeadLetter(ctx context.Context, m kafka.Message, cause error, attempts int) error {
+	return p.writer.WriteMessages(ctx, kafka2.NewDeadLetterMsg(m, cause, attempts, time.Now()))
+}
+
+// Redrive publishes a message read from the dead-letter topic back to its source topic, without the dead-letter
+// headers
+func (p *DeadLetterProducer) Redrive(ctx context.Context, m kafka.Message) error {
+	msg, err := kafka2.NewRedriveMsg(&m)
+	if err != nil {
+		return fmt.Errorf("invalid dead letter at offset %d of partition %d: %w", m.Offset, m.Partition, err)
+	}
+	return p.redriveWriter.WriteMessages(ctx, msg)
+}
+
+func (p *DeadLetterProducer) Shutdown() {
+	for _, writer := range []*kafka.Writer{p.writer, p.redriveWriter} {
+		if err := writer.Close(); err != nil {
+			clog.Errorf("failed to close Kafka writer: %s", err.Error())
+		}
+	}
+}
@@ -15,33 +15,115 @@ import (
 
 type ShipDataProducer struct {
 	writer *kafka.Writer
+	// safetyAlertWriter publishes safety alerts to their own topic
+	safetyAlertWriter *kafka.Writer
+	// serializer encodes ship positions in the configured format, while the other message types are always JSON
+	serializer *kafka2.Serializer
+	topic      string
 }
 
 func NewShipDataProducer(cfg config.Config) (*ShipDataProducer, error) {
+	serializer, err := kafka2.NewSerializer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise serializer: %w", err)
+	}
 	return &ShipDataProducer{
-		writer: &kafka.Writer{
-			Addr:     kafka.TCP(cfg.KafkaAddress),
-			Topic:    cfg.KafkaShipDataTopic,
-			Balancer: &kafka.LeastBytes{},
-			ErrorLogger: kafka.LoggerFunc(func(msg string, a ...interface{}) {
-				clog.Errorf(msg, a...)
-				fmt.Println()
-			}),
-		},
+		writer:            newWriter(cfg, cfg.KafkaShipDataTopic),
+		safetyAlertWriter: newWriter(cfg, cfg.KafkaSafetyAlertTopic),
+		serializer:        serializer,
+		topic:             cfg.KafkaShipDataTopic,
 	}, nil
 }
 
+func newWriter(cfg config.Config, topic string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:     kafka.TCP(cfg.KafkaAddress),
+		Topic:    topic,
+		Balancer: &kafka.LeastBytes{},
+		ErrorLogger: kafka.LoggerFunc(func(msg string, a ...interface{}) {
+			clog.Errorf(msg, a...)
+			fmt.Println()
+		}),
+	}
+}
+
+// writeMessage publishes the message to the writer's topic within a span whose context is passed on in the message's
+// headers
+func writeMessage(ctx context.Context, writer *kafka.Writer, msg kafka.Message) error {
+	span := kafka2.StartPublishSpan(ctx, writer.Topic, &msg)
+	err := writer.WriteMessages(ctx, msg)
+	kafka2.EndSpan(span, err)
+	return err
+}
+
 func (p *ShipDataProducer) Write(ctx context.Context, data domain.Ship) error {
 	dto := kafka2.NewShipDTOFromDomainEntity(data)
+	b, err := p.serializer.Serialize(ctx, dto)
+	if err != nil {
+		return fmt.Errorf("failed to serialize ship DTO: %w", err)
+	}
+	return writeMessage(ctx, p.writer, kafka.Message{
+		Key:     []byte(dto.Key),
+		Value:   b,
+		Headers: []kafka.Header{kafka2.MessageTypeHeader(kafka2.MessageTypeShipPosition)},
+	})
+}
+
+func (p *ShipDataProducer) WriteStaticData(ctx context.Context, data domain.ShipStaticData) error {
+	dto := kafka2.NewShipStaticDataDTOFromDomainEntity(data)
+	b, err := json.Marshal(dto)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ship static data DTO: %w", err)
+	}
+	return writeMessage(ctx, p.writer, kafka.Message{
+		Key:     []byte(dto.Key),
+		Value:   b,
+		Headers: []kafka.Header{kafka2.MessageTypeHeader(kafka2.MessageTypeShipStaticData)},
+	})
+}
+
+func (p *ShipDataProducer) WriteAidToNavigation(ctx context.Context, data domain.AidToNavigation) error {
+	dto := kafka2.NewAidToNavigationDTOFromDomainEntity(data)
+	b, err := json.Marshal(dto)
+	if err != nil {
+		return fmt.Errorf("failed to marshal aid to navigation DTO: %w", err)
+	}
+	return writeMessage(ctx, p.writer, kafka.Message{
+		Key:     []byte(dto.Key),
+		Value:   b,
+		Headers: []kafka.Header{kafka2.MessageTypeHeader(kafka2.MessageTypeAidToNavigation)},
+	})
+}
+
+func (p *ShipDataProducer) WriteBaseStation(ctx context.Context, data domain.BaseStation) error {
+	dto := kafka2.NewBaseStationDTOFromDomainEntity(data)
+	b, err := json.Marshal(dto)
+	if err != nil {
+		return fmt.Errorf("failed to marshal base station DTO: %w", err)
+	}
+	return writeMessage(ctx, p.writer, kafka.Message{
+		Key:     []byte(dto.Key),
+		Value:   b,
+		Headers: []kafka.Header{kafka2.MessageTypeHeader(kafka2.MessageTypeBaseStation)},
+	})
+}
+
+func (p *ShipDataProducer) WriteSafetyAlert(ctx context.Context, alert domain.SafetyAlert) error {
+	dto := kafka2.NewSafetyAlertDTOFromDomainEntity(alert)
 	b, err := json.Marshal(dto)
 	if err != nil {
-		return fmt.Errorf("failed to marshal ship DTO: %w", err)
+		return fmt.Errorf("failed to marshal safety alert DTO: %w", err)
 	}
-	return p.writer.WriteMessages(ctx, kafka.Message{Key: []byte(dto.Key), Value: b})
+	return writeMessage(ctx, p.safetyAlertWriter, kafka.Message{
+		Key:   []byte(dto.Key),
+		Value: b,
+	})
 }
 
 func (p *ShipDataProducer) Shutdown() {
-	if err := p.writer.Close(); err != nil {
-		clog.Errorf("failed to close Kafka writer: %s", err.Error())
+	for _, writer := range []*kafka.Writer{p.writer, p.safetyAlertWriter} {
+		if err := writer.Close(); err != nil {
+			clog.Errorf("failed to close Kafka writer: %s", err.Error())
+		}
 	}
 }
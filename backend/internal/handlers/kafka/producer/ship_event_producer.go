@@ -2,7 +2,6 @@ package producer
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/segmentio/kafka-go"
@@ -14,11 +13,22 @@ import (
 )
 
 type ShipEventProducer struct {
-	writer *kafka.Writer
+	writer     *kafka.Writer
+	serializer *kafka2.Serializer
+	topic      string
+	// source identifies the service publishing the events
+	source string
 }
 
-func NewShipEventProducer(cfg config.Config) (*ShipEventProducer, error) {
+func NewShipEventProducer(cfg config.Config, source string) (*ShipEventProducer, error) {
+	serializer, err := kafka2.NewSerializer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise serializer: %w", err)
+	}
 	return &ShipEventProducer{
+		serializer: serializer,
+		topic:      cfg.KafkaShipEventTopic,
+		source:     source,
 		writer: &kafka.Writer{
 			Addr:     kafka.TCP(cfg.KafkaAddress),
 			Topic:    cfg.KafkaShipEventTopic,
@@ -38,11 +48,18 @@ func (p *ShipEventProducer) PublishShipLocationsUpdatedEvent(ctx context.Context
 	// keep the latest message for a given ship.
 	for _, ship := range ships {
 		event := kafka2.NewShipLocationUpdatedEventDTOFromDomainEntity(ship)
-		b, err := json.Marshal(event)
+		b, err := p.serializer.Serialize(ctx, event)
 		if err != nil {
-			return fmt.Errorf("failed to marshal ship event DTO: %w", err)
+			return fmt.Errorf("failed to serialize ship event DTO: %w", err)
 		}
-		if err := p.writer.WriteMessages(ctx, kafka.Message{Key: []byte(event.Key), Value: b}); err != nil {
+		envelope := kafka2.NewEventEnvelope(kafka2.EventTypeShipLocationUpdated,
+			kafka2.ShipLocationUpdatedEventSchemaVersion, p.source, p.serializer.ContentType())
+		err = writeMessage(ctx, p.writer, kafka.Message{
+			Key:     []byte(event.Key),
+			Value:   b,
+			Headers: envelope.Headers(),
+		})
+		if err != nil {
 			return fmt.Errorf("failed to publish ship location updated event: %w", err)
 		}
 	}
@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+)
+
+type safetyAlertDTO struct {
+	Key             string    `json:"-"`
+	Kind            string    `json:"kind"`
+	DestinationMMSI int32     `json:"destinationMmsi,omitempty"`
+	Text            string    `json:"text,omitempty"`
+	Latitude        *float64  `json:"latitude,omitempty"`
+	Longitude       *float64  `json:"longitude,omitempty"`
+	TransmittedAt   time.Time `json:"transmittedAt"`
+	ReceivedAt      time.Time `json:"receivedAt"`
+}
+
+func NewSafetyAlertDTOFromDomainEntity(a domain.SafetyAlert) *safetyAlertDTO {
+	return &safetyAlertDTO{
+		Key:             strconv.FormatInt(int64(a.MMSI), 10),
+		Kind:            string(a.Kind),
+		DestinationMMSI: a.DestinationMMSI,
+		Text:            a.Text,
+		Latitude:        a.Latitude,
+		Longitude:       a.Longitude,
+		TransmittedAt:   a.TransmittedAt,
+		ReceivedAt:      a.ReceivedAt,
+	}
+}
+
+func NewSafetyAlertDTOFromKafkaMsg(msg *kafka.Message) (*safetyAlertDTO, error) {
+	var dto safetyAlertDTO
+	err := json.Unmarshal(msg.Value, &dto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal safety alert: %w", err)
+	}
+	dto.Key = string(msg.Key)
+	return &dto, err
+}
+
+func (dto *safetyAlertDTO) ToDomainEntity() (*domain.SafetyAlert, error) {
+	mmsi, err := strconv.ParseInt(dto.Key, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert key '%s' to integer: %w", dto.Key, err)
+	}
+	alert := &domain.SafetyAlert{
+		MMSI:            int32(mmsi),
+		Kind:            domain.SafetyAlertKind(dto.Kind),
+		DestinationMMSI: dto.DestinationMMSI,
+		Text:            dto.Text,
+		Latitude:        dto.Latitude,
+		Longitude:       dto.Longitude,
+		TransmittedAt:   dto.TransmittedAt,
+		ReceivedAt:      dto.ReceivedAt,
+	}
+	alert.Normalise()
+	return alert, nil
+}
@@ -0,0 +1,275 @@
+package kafka
+
+import (
+	"context"
+	"embed"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+	"github.com/mikeewhite/ship-locator/backend/pkg/schemaregistry"
+)
+
+// Serialization formats for the values of the ship position and ship location updated event messages
+const (
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+	FormatAvro     = "avro"
+)
+
+// magicByte starts every value framed in the Confluent wire format, followed by the 4 byte ID of the schema in the
+// registry and then (for Protobuf) the indexes of the message type within the schema
+const magicByte = 0
+
+//go:embed schemas
+var schemas embed.FS
+
+// SchemaRegistry registers and looks up the schemas used to encode messages
+type SchemaRegistry interface {
+	Register(ctx context.Context, subject string, schema schemaregistry.Schema) (int, error)
+	SchemaByID(ctx context.Context, id int) (schemaregistry.Schema, error)
+}
+
+// schemaRecord is a DTO that can be encoded with the Protobuf and Avro schemas in the schemas directory as well as JSON
+type schemaRecord interface {
+	// schemaName is the name of the record's schema files, without an extension
+	schemaName() string
+	// recordName is the fully qualified name of the record in its schemas, which names its subjects in the registry
+	recordName() string
+	marshalProtobuf() ([]byte, error)
+	unmarshalProtobuf(b []byte) error
+	marshalAvro(schema avro.Schema) ([]byte, error)
+	unmarshalAvro(schema avro.Schema, b []byte) error
+}
+
+// Serializer encodes message values in the configured format, registering the Protobuf and Avro schemas under a subject
+// named after the record and the format (see subject), so that a topic can carry several types of record.
+// Values in any of the formats can be decoded regardless of the configured format, so consumers keep working while
+// producers switch format.
+type Serializer struct {
+	format   string
+	registry SchemaRegistry
+	// resolved caches the Avro schemas resolving each writer's schema against ours, keyed by avroResolution
+	resolved sync.Map
+}
+
+// avroResolution identifies the resolution of a writer's schema in the registry against the schema of a record
+type avroResolution struct {
+	schemaID   int
+	schemaName string
+}
+
+// avroSchemas caches the parsed Avro schemas in the schemas directory, keyed by schema name
+var avroSchemas sync.Map
+
+func NewSerializer(cfg config.Config) (*Serializer, error) {
+	format := strings.ToLower(strings.TrimSpace(cfg.KafkaSerializationFormat))
+	switch format {
+	case FormatJSON, FormatProtobuf, FormatAvro:
+	default:
+		return nil, fmt.Errorf("unknown serialization format '%s'", cfg.KafkaSerializationFormat)
+	}
+
+	var registry SchemaRegistry
+	if cfg.KafkaSchemaRegistryURL != "" {
+		registry = schemaregistry.New(cfg.KafkaSchemaRegistryURL)
+	} else if format != FormatJSON {
+		return nil, fmt.Errorf("a schema registry URL must be set to use the %s format", format)
+	}
+	return &Serializer{format: format, registry: registry}, nil
+}
+
+// ContentType returns the media type of the values encoded by the serializer
+func (s *Serializer) ContentType() string {
+	switch s.format {
+	case FormatProtobuf:
+		return "application/x-protobuf"
+	case FormatAvro:
+		return "application/avro"
+	default:
+		return "application/json"
+	}
+}
+
+// Serialize encodes the record for publishing
+func (s *Serializer) Serialize(ctx context.Context, record schemaRecord) ([]byte, error) {
+	var schemaType string
+	var payload []byte
+	switch s.format {
+	case FormatProtobuf:
+		schemaType = schemaregistry.SchemaTypeProtobuf
+		b, err := record.marshalProtobuf()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s as Protobuf: %w", record.schemaName(), err)
+		}
+		// the message indexes of the first message in the schema are written as a single zero
+		payload = append([]byte{0}, b...)
+	case FormatAvro:
+		schemaType = schemaregistry.SchemaTypeAvro
+		schema, err := parseAvroSchema(record)
+		if err != nil {
+			return nil, err
+		}
+		payload, err = record.marshalAvro(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s as Avro: %w", record.schemaName(), err)
+		}
+	default:
+		b, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s as JSON: %w", record.schemaName(), err)
+		}
+		return b, nil
+	}
+
+	schema, err := loadSchema(record, schemaType)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.registry.Register(ctx, subject(record, s.format), schema)
+	if err != nil {
+		return nil, err
+	}
+	framed := make([]byte, 5, 5+len(payload))
+	framed[0] = magicByte
+	binary.BigEndian.PutUint32(framed[1:], uint32(id))
+	return append(framed, payload...), nil
+}
+
+// Deserialize decodes a value written in any of the formats into the record
+func (s *Serializer) Deserialize(ctx context.Context, value []byte, record schemaRecord) error {
+	if len(value) == 0 || value[0] != magicByte {
+		if err := json.Unmarshal(value, record); err != nil {
+			return fmt.Errorf("failed to unmarshal %s from JSON: %w", record.schemaName(), err)
+		}
+		return nil
+	}
+	if len(value) < 5 {
+		return errors.New("value is too short for the schema registry wire format")
+	}
+	if s.registry == nil {
+		return errors.New("a schema registry URL must be set to decode values encoded with a schema")
+	}
+
+	id := int(binary.BigEndian.Uint32(value[1:5]))
+	writerSchema, err := s.registry.SchemaByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	payload := value[5:]
+	switch writerSchema.Type {
+	case schemaregistry.SchemaTypeProtobuf:
+		payload, err = consumeMessageIndexes(payload)
+		if err != nil {
+			return err
+		}
+		// fields are identified by number so values written with other versions of the schema can be read
+		if err := record.unmarshalProtobuf(payload); err != nil {
+			return fmt.Errorf("failed to unmarshal %s from Protobuf: %w", record.schemaName(), err)
+		}
+	case schemaregistry.SchemaTypeAvro:
+		// reading Avro requires the writer's schema, so values written with other versions of the schema are
+		// resolved against ours first
+		schema, err := s.resolveAvroSchema(record, id, writerSchema)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s schema %d: %w", record.schemaName(), id, err)
+		}
+		if err := record.unmarshalAvro(schema, payload); err != nil {
+			return fmt.Errorf("failed to unmarshal %s from Avro: %w", record.schemaName(), err)
+		}
+	default:
+		return fmt.Errorf("unsupported schema type '%s' for schema %d", writerSchema.Type, id)
+	}
+	return nil
+}
+
+// subject returns the subject in the registry of the record's schema in the format. Each format has its own subject
+// (e.g. shiplocator.v1.ShipPosition-avro) as the registry rejects a schema of a different type to those already
+// registered under a subject, which would stop producers from switching format.
+func subject(record schemaRecord, format string) string {
+	return record.recordName() + "-" + format
+}
+
+func loadSchema(record schemaRecord, schemaType string) (schemaregistry.Schema, error) {
+	extension := ".avsc"
+	if schemaType == schemaregistry.SchemaTypeProtobuf {
+		extension = ".proto"
+	}
+	b, err := schemas.ReadFile("schemas/" + record.schemaName() + extension)
+	if err != nil {
+		return schemaregistry.Schema{}, fmt.Errorf("failed to read schema: %w", err)
+	}
+	return schemaregistry.Schema{Type: schemaType, Schema: string(b)}, nil
+}
+
+// consumeMessageIndexes strips the indexes identifying the message type within the Protobuf schema, which must be the
+// first message as our schemas only define one
+func consumeMessageIndexes(b []byte) ([]byte, error) {
+	count, n := binary.Varint(b)
+	if n <= 0 {
+		return nil, errors.New("invalid protobuf message indexes")
+	}
+	b = b[n:]
+	for i := int64(0); i < count; i++ {
+		index, n := binary.Varint(b)
+		if n <= 0 {
+			return nil, errors.New("invalid protobuf message indexes")
+		}
+		if index != 0 {
+			return nil, fmt.Errorf("unexpected protobuf message index %d", index)
+		}
+		b = b[n:]
+	}
+	return b, nil
+}
+
+// parseAvroSchema returns the record's Avro schema from the schemas directory
+func parseAvroSchema(record schemaRecord) (avro.Schema, error) {
+	if schema, ok := avroSchemas.Load(record.schemaName()); ok {
+		return schema.(avro.Schema), nil
+	}
+	b, err := loadSchema(record, schemaregistry.SchemaTypeAvro)
+	if err != nil {
+		return nil, err
+	}
+	// each schema is parsed with its own cache as the writers' versions of it share its name
+	schema, err := avro.ParseWithCache(b.Schema, "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s schema: %w", record.schemaName(), err)
+	}
+	avroSchemas.Store(record.schemaName(), schema)
+	return schema, nil
+}
+
+// resolveAvroSchema returns the schema to decode the record with when it was written with the writer's schema, which
+// is resolved against the record's own schema unless they are the same
+func (s *Serializer) resolveAvroSchema(record schemaRecord, id int,
+	writerSchema schemaregistry.Schema) (avro.Schema, error) {
+	key := avroResolution{schemaID: id, schemaName: record.schemaName()}
+	if schema, ok := s.resolved.Load(key); ok {
+		return schema.(avro.Schema), nil
+	}
+	readerSchema, err := parseAvroSchema(record)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := avro.ParseWithCache(writerSchema.Schema, "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse writer's schema: %w", err)
+	}
+	schema := readerSchema
+	if writer.Fingerprint() != readerSchema.Fingerprint() {
+		schema, err = avro.NewSchemaCompatibility().Resolve(readerSchema, writer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.resolved.Store(key, schema)
+	return schema, nil
+}
@@ -0,0 +1,274 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+	"github.com/mikeewhite/ship-locator/backend/pkg/schemaregistry"
+)
+
+var jsonSerializer = &Serializer{format: FormatJSON}
+
+// MockSchemaRegistry holds the registered schemas in memory
+type MockSchemaRegistry struct {
+	schemas  []schemaregistry.Schema
+	subjects []string
+}
+
+func (r *MockSchemaRegistry) Register(_ context.Context, subject string, schema schemaregistry.Schema) (int, error) {
+	for i, s := range r.schemas {
+		if r.subjects[i] != subject {
+			continue
+		}
+		if s == schema {
+			return i + 1, nil
+		}
+		// like the registry, reject schemas of a different type to those already registered under the subject
+		if s.Type != schema.Type {
+			return 0, assert.AnError
+		}
+	}
+	r.schemas = append(r.schemas, schema)
+	r.subjects = append(r.subjects, subject)
+	return len(r.schemas), nil
+}
+
+func (r *MockSchemaRegistry) SchemaByID(_ context.Context, id int) (schemaregistry.Schema, error) {
+	if id < 1 || id > len(r.schemas) {
+		return schemaregistry.Schema{}, assert.AnError
+	}
+	return r.schemas[id-1], nil
+}
+
+func testShip() domain.Ship {
+	return domain.Ship{
+		MMSI:              259000420,
+		Name:              "AUGUSTSON",
+		Latitude:          66.02695,
+		Longitude:         12.253821666666665,
+		Kinematics:        domain.NewKinematics(12.3, 308, 235, 0, 127),
+		TransponderClass:  domain.TransponderClassB,
+		ImplausibleReason: domain.ImplausibleReasonNullIsland,
+		LastUpdated:       time.Date(2022, time.December, 29, 18, 22, 31, 0, time.UTC),
+		ReceivedAt:        time.Date(2022, time.December, 29, 18, 25, 0, 0, time.UTC),
+	}
+}
+
+func TestSerializer_ShipDTORoundTrip(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatProtobuf, FormatAvro} {
+		t.Run(format, func(t *testing.T) {
+			registry := &MockSchemaRegistry{}
+			serializer := &Serializer{format: format, registry: registry}
+			ship := testShip()
+
+			b, err := serializer.Serialize(context.Background(), NewShipDTOFromDomainEntity(ship))
+			require.NoError(t, err)
+			if format == FormatJSON {
+				assert.Empty(t, registry.schemas)
+			} else {
+				require.Len(t, registry.schemas, 1)
+				assert.Equal(t, []byte{magicByte, 0, 0, 0, 1}, b[:5])
+			}
+
+			decoded := &shipDTO{Key: "259000420"}
+			require.NoError(t, serializer.Deserialize(context.Background(), b, decoded))
+			entity, err := decoded.ToDomainEntity()
+			require.NoError(t, err)
+			assert.Equal(t, ship, *entity)
+		})
+	}
+}
+
+func TestSerializer_ShipLocationUpdatedEventRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatProtobuf, FormatAvro} {
+		t.Run(format, func(t *testing.T) {
+			serializer := &Serializer{format: format, registry: &MockSchemaRegistry{}}
+			ship := testShip()
+
+			b, err := serializer.Serialize(context.Background(),
+				NewShipLocationUpdatedEventDTOFromDomainEntity(ship))
+			require.NoError(t, err)
+			decoded := &ShipLocationUpdatedEventDTO{Key: "259000420"}
+			require.NoError(t, serializer.Deserialize(context.Background(), b, decoded))
+			result, err := decoded.ToDomainEntity()
+			require.NoError(t, err)
+			assert.Equal(t, ship.MMSI, result.MMSI)
+			assert.Equal(t, ship.Name, result.Name)
+		})
+	}
+}
+
+func TestSerializer_DecodesAnyFormat(t *testing.T) {
+	registry := &MockSchemaRegistry{}
+	producer := &Serializer{format: FormatProtobuf, registry: registry}
+	consumer := &Serializer{format: FormatJSON, registry: registry}
+
+	b, err := producer.Serialize(context.Background(), NewShipDTOFromDomainEntity(testShip()))
+	require.NoError(t, err)
+	decoded := &shipDTO{}
+	require.NoError(t, consumer.Deserialize(context.Background(), b, decoded))
+	assert.Equal(t, "AUGUSTSON", decoded.Name)
+
+	decoded = &shipDTO{}
+	require.NoError(t, consumer.Deserialize(context.Background(), []byte(`{"name":"AUGUSTSON"}`), decoded))
+	assert.Equal(t, "AUGUSTSON", decoded.Name)
+}
+
+func TestSerializer_RegistersSchemasUnderRecordAndFormatSubjects(t *testing.T) {
+	registry := &MockSchemaRegistry{}
+	serializer := &Serializer{format: FormatAvro, registry: registry}
+
+	_, err := serializer.Serialize(context.Background(), NewShipDTOFromDomainEntity(testShip()))
+	require.NoError(t, err)
+	_, err = serializer.Serialize(context.Background(), NewShipLocationUpdatedEventDTOFromDomainEntity(testShip()))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shiplocator.v1.ShipPosition-avro", "shiplocator.v1.ShipLocationUpdatedEvent-avro"},
+		registry.subjects)
+
+	// the subjects must be named after the records in the schemas
+	for i, schema := range registry.schemas {
+		record, err := avro.ParseWithCache(schema.Schema, "", &avro.SchemaCache{})
+		require.NoError(t, err)
+		assert.Equal(t, registry.subjects[i], record.(avro.NamedSchema).FullName()+"-avro")
+	}
+}
+
+func TestSerializer_SwitchesFormatUnderAnotherSubject(t *testing.T) {
+	registry := &MockSchemaRegistry{}
+	avroProducer := &Serializer{format: FormatAvro, registry: registry}
+	protobufProducer := &Serializer{format: FormatProtobuf, registry: registry}
+	ship := NewShipDTOFromDomainEntity(testShip())
+
+	avroValue, err := avroProducer.Serialize(context.Background(), ship)
+	require.NoError(t, err)
+	protobufValue, err := protobufProducer.Serialize(context.Background(), ship)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shiplocator.v1.ShipPosition-avro", "shiplocator.v1.ShipPosition-protobuf"},
+		registry.subjects)
+
+	// values published before and after the switch can both be decoded
+	for _, value := range [][]byte{avroValue, protobufValue} {
+		decoded := &shipDTO{}
+		require.NoError(t, protobufProducer.Deserialize(context.Background(), value, decoded))
+		assert.Equal(t, "AUGUSTSON", decoded.Name)
+	}
+}
+
+// frameAvro frames the payload in the wire format, registering the schema it was written with
+func frameAvro(t *testing.T, registry *MockSchemaRegistry, schema string, payload []byte) []byte {
+	id, err := registry.Register(context.Background(), "shiplocator.v1.ShipPosition-avro",
+		schemaregistry.Schema{Type: schemaregistry.SchemaTypeAvro, Schema: schema})
+	require.NoError(t, err)
+	return append([]byte{magicByte, 0, 0, 0, byte(id)}, payload...)
+}
+
+func TestSerializer_ResolvesOtherAvroSchemaVersions(t *testing.T) {
+	registry := &MockSchemaRegistry{}
+	serializer := &Serializer{format: FormatAvro, registry: registry}
+
+	// written by an older producer, before the no turn indicator flag was added, with a field that has since been
+	// removed and a rate of turn written as a float
+	writerSchema := `{
+		"type": "record",
+		"name": "ShipPosition",
+		"namespace": "shiplocator.v1",
+		"fields": [
+			{"name": "name", "type": "string"},
+			{"name": "callSign", "type": "string", "default": ""},
+			{"name": "latitude", "type": "double"},
+			{"name": "longitude", "type": "double"},
+			{"name": "speedOverGround", "type": ["null", "double"], "default": null},
+			{"name": "courseOverGround", "type": ["null", "double"], "default": null},
+			{"name": "trueHeading", "type": ["null", "int"], "default": null},
+			{"name": "navigationalStatus", "type": ["null", "int"], "default": null},
+			{"name": "rateOfTurn", "type": ["null", "float"], "default": null},
+			{"name": "transponderClass", "type": "string", "default": ""},
+			{"name": "implausibleReason", "type": "string", "default": ""},
+			{"name": "lastUpdated", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+			{"name": "receivedAt", "type": {"type": "long", "logicalType": "timestamp-micros"}}
+		]
+	}`
+	payload, err := avro.Marshal(avro.MustParse(writerSchema), struct {
+		Name               string   `avro:"name"`
+		CallSign           string   `avro:"callSign"`
+		Latitude           float64  `avro:"latitude"`
+		Longitude          float64  `avro:"longitude"`
+		SpeedOverGround    *float64 `avro:"speedOverGround"`
+		CourseOverGround   *float64 `avro:"courseOverGround"`
+		TrueHeading        *int32   `avro:"trueHeading"`
+		NavigationalStatus *int32   `avro:"navigationalStatus"`
+		RateOfTurn         *float32 `avro:"rateOfTurn"`
+		TransponderClass   string   `avro:"transponderClass"`
+		ImplausibleReason  string   `avro:"implausibleReason"`
+		LastUpdated        int64    `avro:"lastUpdated"`
+		ReceivedAt         int64    `avro:"receivedAt"`
+	}{
+		Name:             "AUGUSTSON",
+		CallSign:         "LAUP",
+		Latitude:         66.02695,
+		Longitude:        12.253821666666665,
+		SpeedOverGround:  ptr(12.3),
+		TrueHeading:      ptr(int32(235)),
+		RateOfTurn:       ptr(float32(-2.5)),
+		TransponderClass: "B",
+		LastUpdated:      1672338151000000,
+	})
+	require.NoError(t, err)
+
+	decoded := &shipDTO{}
+	require.NoError(t, serializer.Deserialize(context.Background(), frameAvro(t, registry, writerSchema, payload), decoded))
+	assert.Equal(t, &shipDTO{
+		Name:             "AUGUSTSON",
+		Latitude:         66.02695,
+		Longitude:        12.253821666666665,
+		SpeedOverGround:  ptr(12.3),
+		TrueHeading:      ptr(int32(235)),
+		RateOfTurn:       ptr(-2.5),
+		TransponderClass: "B",
+		LastUpdated:      time.Date(2022, time.December, 29, 18, 22, 31, 0, time.UTC),
+	}, decoded)
+}
+
+func TestSerializer_RejectsAvroSchemaMissingFieldsWithoutDefaults(t *testing.T) {
+	registry := &MockSchemaRegistry{}
+	serializer := &Serializer{format: FormatAvro, registry: registry}
+
+	writerSchema := `{"type":"record","name":"ShipPosition","namespace":"shiplocator.v1","fields":[{"name":"name","type":"string"}]}`
+	payload, err := avro.Marshal(avro.MustParse(writerSchema), map[string]any{"name": "AUGUSTSON"})
+	require.NoError(t, err)
+	err = serializer.Deserialize(context.Background(), frameAvro(t, registry, writerSchema, payload), &shipDTO{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "latitude is missing in writer schema and has no default")
+}
+
+func TestSerializer_RejectsUnknownAvroSchema(t *testing.T) {
+	registry := &MockSchemaRegistry{}
+	serializer := &Serializer{format: FormatAvro, registry: registry}
+
+	b, err := serializer.Serialize(context.Background(), NewShipDTOFromDomainEntity(testShip()))
+	require.NoError(t, err)
+	registry.schemas[0].Schema = `{"type":"record","name":"Other","fields":[]}`
+	assert.Error(t, serializer.Deserialize(context.Background(), b, &shipDTO{}))
+}
+
+func TestNewSerializer(t *testing.T) {
+	_, err := NewSerializer(config.Config{KafkaSerializationFormat: "xml"})
+	assert.Error(t, err)
+	_, err = NewSerializer(config.Config{KafkaSerializationFormat: "avro"})
+	assert.Error(t, err)
+	serializer, err := NewSerializer(config.Config{KafkaSerializationFormat: "Protobuf",
+		KafkaSchemaRegistryURL: "http://localhost:8081"})
+	require.NoError(t, err)
+	assert.Equal(t, FormatProtobuf, serializer.format)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -1,13 +1,16 @@
 package kafka
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"strconv"
 
+	"github.com/hamba/avro/v2"
 	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/kafka/pb"
 )
 
 type ShipLocationUpdatedEventDTO struct {
@@ -26,9 +29,10 @@ func NewShipLocationUpdatedEventDTOFromDomainEntity(s domain.Ship) *ShipLocation
 	}
 }
 
-func NewShipLocationUpdatedEventDTOFromKafkaMsg(msg *kafka.Message) (*ShipLocationUpdatedEventDTO, error) {
+func NewShipLocationUpdatedEventDTOFromKafkaMsg(ctx context.Context, serializer *Serializer,
+	msg *kafka.Message) (*ShipLocationUpdatedEventDTO, error) {
 	var dto ShipLocationUpdatedEventDTO
-	err := json.Unmarshal(msg.Value, &dto)
+	err := serializer.Deserialize(ctx, msg.Value, &dto)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal ship data: %w", err)
 	}
@@ -36,6 +40,59 @@ func NewShipLocationUpdatedEventDTOFromKafkaMsg(msg *kafka.Message) (*ShipLocati
 	return &dto, err
 }
 
+func (dto *ShipLocationUpdatedEventDTO) schemaName() string {
+	return "ship_location_updated_event"
+}
+
+func (dto *ShipLocationUpdatedEventDTO) recordName() string {
+	return "shiplocator.v1.ShipLocationUpdatedEvent"
+}
+
+func (dto *ShipLocationUpdatedEventDTO) marshalProtobuf() ([]byte, error) {
+	return proto.Marshal(&pb.ShipLocationUpdatedEvent{
+		Name:      dto.Name,
+		Latitude:  dto.Latitude,
+		Longitude: dto.Longitude,
+	})
+}
+
+func (dto *ShipLocationUpdatedEventDTO) unmarshalProtobuf(b []byte) error {
+	var msg pb.ShipLocationUpdatedEvent
+	if err := proto.Unmarshal(b, &msg); err != nil {
+		return err
+	}
+	dto.Name = msg.Name
+	dto.Latitude = msg.Latitude
+	dto.Longitude = msg.Longitude
+	return nil
+}
+
+// shipLocationUpdatedEventAvro is the ShipLocationUpdatedEvent record in schemas/ship_location_updated_event.avsc
+type shipLocationUpdatedEventAvro struct {
+	Name      string  `avro:"name"`
+	Latitude  float64 `avro:"latitude"`
+	Longitude float64 `avro:"longitude"`
+}
+
+func (dto *ShipLocationUpdatedEventDTO) marshalAvro(schema avro.Schema) ([]byte, error) {
+	return avro.Marshal(schema, shipLocationUpdatedEventAvro{
+		Name:      dto.Name,
+		Latitude:  dto.Latitude,
+		Longitude: dto.Longitude,
+	})
+}
+
+func (dto *ShipLocationUpdatedEventDTO) unmarshalAvro(schema avro.Schema, b []byte) error {
+	var record shipLocationUpdatedEventAvro
+	if err := avro.Unmarshal(schema, b, &record); err != nil {
+		return err
+	}
+	dto.Name = record.Name
+	dto.Latitude = record.Latitude
+	dto.Longitude = record.Longitude
+	return nil
+}
+
 func (dto *ShipLocationUpdatedEventDTO) ToDomainEntity() (*domain.ShipSearchResult, error) {
 	mmsi, err := strconv.ParseInt(dto.Key, 10, 32)
 	if err != nil {
@@ -0,0 +1,80 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+)
+
+type shipStaticDataDTO struct {
+	Key                  string     `json:"-"`
+	Name                 string     `json:"name"`
+	IMONumber            int32      `json:"imoNumber"`
+	CallSign             string     `json:"callSign"`
+	ShipType             int32      `json:"shipType"`
+	DimensionToBow       int32      `json:"dimensionToBow"`
+	DimensionToStern     int32      `json:"dimensionToStern"`
+	DimensionToPort      int32      `json:"dimensionToPort"`
+	DimensionToStarboard int32      `json:"dimensionToStarboard"`
+	Draught              float64    `json:"draught"`
+	Destination          string     `json:"destination"`
+	ETA                  *time.Time `json:"eta,omitempty"`
+	LastUpdated          time.Time  `json:"lastUpdated"`
+}
+
+func NewShipStaticDataDTOFromDomainEntity(d domain.ShipStaticData) *shipStaticDataDTO {
+	return &shipStaticDataDTO{
+		Key:                  strconv.FormatInt(int64(d.MMSI), 10),
+		Name:                 d.Name,
+		IMONumber:            d.IMONumber,
+		CallSign:             d.CallSign,
+		ShipType:             d.ShipType,
+		DimensionToBow:       d.DimensionToBow,
+		DimensionToStern:     d.DimensionToStern,
+		DimensionToPort:      d.DimensionToPort,
+		DimensionToStarboard: d.DimensionToStarboard,
+		Draught:              d.Draught,
+		Destination:          d.Destination,
+		ETA:                  d.ETA,
+		LastUpdated:          d.LastUpdated,
+	}
+}
+
+func NewShipStaticDataDTOFromKafkaMsg(msg *kafka.Message) (*shipStaticDataDTO, error) {
+	var dto shipStaticDataDTO
+	err := json.Unmarshal(msg.Value, &dto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ship static data: %w", err)
+	}
+	dto.Key = string(msg.Key)
+	return &dto, err
+}
+
+func (dto *shipStaticDataDTO) ToDomainEntity() (*domain.ShipStaticData, error) {
+	mmsi, err := strconv.ParseInt(dto.Key, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert key '%s' to integer: %w", dto.Key, err)
+	}
+	data := &domain.ShipStaticData{
+		MMSI:                 int32(mmsi),
+		Name:                 dto.Name,
+		IMONumber:            dto.IMONumber,
+		CallSign:             dto.CallSign,
+		ShipType:             dto.ShipType,
+		DimensionToBow:       dto.DimensionToBow,
+		DimensionToStern:     dto.DimensionToStern,
+		DimensionToPort:      dto.DimensionToPort,
+		DimensionToStarboard: dto.DimensionToStarboard,
+		Draught:              dto.Draught,
+		Destination:          dto.Destination,
+		ETA:                  dto.ETA,
+		LastUpdated:          dto.LastUpdated,
+	}
+	data.Normalise()
+	return data, nil
+}
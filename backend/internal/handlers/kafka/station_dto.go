@@ -0,0 +1,122 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+)
+
+type aidToNavigationDTO struct {
+	Key                  string    `json:"-"`
+	Name                 string    `json:"name"`
+	AidType              int32     `json:"aidType"`
+	Latitude             float64   `json:"latitude"`
+	Longitude            float64   `json:"longitude"`
+	DimensionToBow       int32     `json:"dimensionToBow"`
+	DimensionToStern     int32     `json:"dimensionToStern"`
+	DimensionToPort      int32     `json:"dimensionToPort"`
+	DimensionToStarboard int32     `json:"dimensionToStarboard"`
+	VirtualAid           bool      `json:"virtualAid"`
+	OffPosition          bool      `json:"offPosition"`
+	LastUpdated          time.Time `json:"lastUpdated"`
+	ReceivedAt           time.Time `json:"receivedAt"`
+}
+
+func NewAidToNavigationDTOFromDomainEntity(a domain.AidToNavigation) *aidToNavigationDTO {
+	return &aidToNavigationDTO{
+		Key:                  strconv.FormatInt(int64(a.MMSI), 10),
+		Name:                 a.Name,
+		AidType:              a.AidType,
+		Latitude:             a.Latitude,
+		Longitude:            a.Longitude,
+		DimensionToBow:       a.DimensionToBow,
+		DimensionToStern:     a.DimensionToStern,
+		DimensionToPort:      a.DimensionToPort,
+		DimensionToStarboard: a.DimensionToStarboard,
+		VirtualAid:           a.VirtualAid,
+		OffPosition:          a.OffPosition,
+		LastUpdated:          a.LastUpdated,
+		ReceivedAt:           a.ReceivedAt,
+	}
+}
+
+func NewAidToNavigationDTOFromKafkaMsg(msg *kafka.Message) (*aidToNavigationDTO, error) {
+	var dto aidToNavigationDTO
+	err := json.Unmarshal(msg.Value, &dto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal aid to navigation: %w", err)
+	}
+	dto.Key = string(msg.Key)
+	return &dto, err
+}
+
+func (dto *aidToNavigationDTO) ToDomainEntity() (*domain.AidToNavigation, error) {
+	mmsi, err := strconv.ParseInt(dto.Key, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert key '%s' to integer: %w", dto.Key, err)
+	}
+	aid := &domain.AidToNavigation{
+		MMSI:                 int32(mmsi),
+		Name:                 dto.Name,
+		AidType:              dto.AidType,
+		Latitude:             dto.Latitude,
+		Longitude:            dto.Longitude,
+		DimensionToBow:       dto.DimensionToBow,
+		DimensionToStern:     dto.DimensionToStern,
+		DimensionToPort:      dto.DimensionToPort,
+		DimensionToStarboard: dto.DimensionToStarboard,
+		VirtualAid:           dto.VirtualAid,
+		OffPosition:          dto.OffPosition,
+		LastUpdated:          dto.LastUpdated,
+		ReceivedAt:           dto.ReceivedAt,
+	}
+	aid.Normalise()
+	return aid, nil
+}
+
+type baseStationDTO struct {
+	Key         string    `json:"-"`
+	Latitude    float64   `json:"latitude"`
+	Longitude   float64   `json:"longitude"`
+	LastUpdated time.Time `json:"lastUpdated"`
+	ReceivedAt  time.Time `json:"receivedAt"`
+}
+
+func NewBaseStationDTOFromDomainEntity(b domain.BaseStation) *baseStationDTO {
+	return &baseStationDTO{
+		Key:         strconv.FormatInt(int64(b.MMSI), 10),
+		Latitude:    b.Latitude,
+		Longitude:   b.Longitude,
+		LastUpdated: b.LastUpdated,
+		ReceivedAt:  b.ReceivedAt,
+	}
+}
+
+func NewBaseStationDTOFromKafkaMsg(msg *kafka.Message) (*baseStationDTO, error) {
+	var dto baseStationDTO
+	err := json.Unmarshal(msg.Value, &dto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal base station: %w", err)
+	}
+	dto.Key = string(msg.Key)
+	return &dto, err
+}
+
+func (dto *baseStationDTO) ToDomainEntity() (*domain.BaseStation, error) {
+	mmsi, err := strconv.ParseInt(dto.Key, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert key '%s' to integer: %w", dto.Key, err)
+	}
+	return &domain.BaseStation{
+		MMSI:        int32(mmsi),
+		Latitude:    dto.Latitude,
+		Longitude:   dto.Longitude,
+		LastUpdated: dto.LastUpdated.UTC(),
+		ReceivedAt:  dto.ReceivedAt.UTC(),
+	}, nil
+}
@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/mikeewhite/ship-locator/kafka"
+
+// headerCarrier lets the trace context be injected into and extracted from the headers of a Kafka message
+type headerCarrier struct {
+	msg *kafka.Message
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, header := range c.msg.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, header := range c.msg.Headers {
+		if header.Key == key {
+			c.msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.msg.Headers = append(c.msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, len(c.msg.Headers))
+	for i, header := range c.msg.Headers {
+		keys[i] = header.Key
+	}
+	return keys
+}
+
+// StartPublishSpan starts a span for publishing the message to the topic and injects its context into the message's
+// headers, so that the consumers' spans join the same trace
+func StartPublishSpan(ctx context.Context, topic string, msg *kafka.Message) trace.Span {
+	// See https://opentelemetry.io/docs/specs/otel/trace/semantic_conventions/messaging/
+	ctx, span := otel.Tracer(tracerName).Start(ctx, fmt.Sprintf("%s publish", topic),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystem("kafka"),
+			semconv.MessagingOperationPublish,
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+		))
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{msg})
+	return span
+}
+
+// StartProcessSpan starts a span for processing the message, continuing the trace whose context was injected into the
+// message's headers by the producer
+func StartProcessSpan(ctx context.Context, consumerGroup string, msg *kafka.Message) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier{msg})
+	return otel.Tracer(tracerName).Start(ctx, fmt.Sprintf("%s process", msg.Topic),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystem("kafka"),
+			semconv.MessagingOperationProcess,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingKafkaConsumerGroup(consumerGroup),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+			semconv.MessagingKafkaDestinationPartition(msg.Partition),
+			semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+		))
+}
+
+// EndSpan ends the span, marking it as failed if there was an error
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
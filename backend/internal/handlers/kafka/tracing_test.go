@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing_PropagatesTraceContextThroughHeaders(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	msg := kafka.Message{
+		Key:     []byte("259000420"),
+		Headers: []kafka.Header{MessageTypeHeader(MessageTypeShipPosition)},
+	}
+	publishSpan := StartPublishSpan(context.Background(), "ship-data-topic", &msg)
+	EndSpan(publishSpan, nil)
+	require.Len(t, msg.Headers, 2)
+	assert.Equal(t, "traceparent", msg.Headers[1].Key)
+
+	// as fetched by the consumer
+	msg.Topic, msg.Partition, msg.Offset = "ship-data-topic", 3, 42
+	ctx, processSpan := StartProcessSpan(context.Background(), "ship-locator", &msg)
+	EndSpan(processSpan, assert.AnError)
+	assert.Equal(t, processSpan.SpanContext(), trace.SpanContextFromContext(ctx))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "ship-data-topic publish", spans[0].Name())
+	assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind())
+	assert.Equal(t, "ship-data-topic process", spans[1].Name())
+	assert.Equal(t, trace.SpanKindConsumer, spans[1].SpanKind())
+	assert.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.Len(t, spans[1].Events(), 1, "the error should be recorded")
+}
@@ -0,0 +1,328 @@
+package nmea
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/internal/core/ports"
+	"github.com/mikeewhite/ship-locator/backend/pkg/aivdm"
+	"github.com/mikeewhite/ship-locator/backend/pkg/backoff"
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+const (
+	protocolTCP = "tcp"
+	protocolUDP = "udp"
+
+	maxDatagramSize = 64 * 1024
+	// maxClassBNames caps the number of Class B names held while waiting for the second part of a static data report
+	maxClassBNames = 100000
+)
+
+// Listener receives NMEA 0183 AIVDM/AIVDO sentences from an AIS receiver and passes the decoded reports to the
+// collector service. Over TCP the listener connects to the receiver (reconnecting if the connection drops) whereas
+// over UDP it listens for datagrams sent by the receiver.
+type Listener struct {
+	protocol         string
+	address          string
+	readTimeout      time.Duration
+	backoff          backoff.Backoff
+	collectorService ports.CollectorService
+
+	// classBNamesMu guards the names received in part A of Class B static data reports
+	classBNamesMu sync.Mutex
+	classBNames   map[int32]string
+}
+
+// NewListener creates a listener for the receiver at the given address
+func NewListener(cfg config.Config, address string, collectorService ports.CollectorService) (*Listener, error) {
+	protocol := strings.ToLower(strings.TrimSpace(cfg.NMEAProtocol))
+	if protocol != protocolTCP && protocol != protocolUDP {
+		return nil, fmt.Errorf("unsupported NMEA protocol '%s' (expected tcp or udp)", cfg.NMEAProtocol)
+	}
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return nil, errors.New("NMEA address must be set")
+	}
+
+	return &Listener{
+		protocol:         protocol,
+		address:          address,
+		readTimeout:      cfg.NMEAReadTimeout,
+		backoff:          backoff.New(cfg.NMEAReconnectMinBackoff, cfg.NMEAReconnectMaxBackoff),
+		collectorService: collectorService,
+		classBNames:      make(map[int32]string),
+	}, nil
+}
+
+// Name identifies the listener by the protocol and address of its receiver
+func (l *Listener) Name() string {
+	return fmt.Sprintf("nmea+%s://%s", l.protocol, l.address)
+}
+
+// Listen receives and processes sentences until the context is cancelled
+func (l *Listener) Listen(ctx context.Context) error {
+	if l.protocol == protocolUDP {
+		return l.listenUDP(ctx)
+	}
+	return l.listenTCP(ctx)
+}
+
+func (l *Listener) listenTCP(ctx context.Context) error {
+	attempt := 0
+	for {
+		received, err := l.connectAndRead(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// only back off further if the connection failed before any sentences were received
+		if received {
+			attempt = 0
+		}
+		delay := l.backoff.Duration(attempt)
+		attempt++
+
+		clog.Warnw("NMEA connection lost, reconnecting",
+			"error", err.Error(),
+			"address", l.address,
+			"attempt", attempt,
+			"delay", delay.String())
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// connectAndRead connects to the receiver and processes sentences until the connection fails. It reports whether
+// any sentences were received over the connection.
+func (l *Listener) connectAndRead(ctx context.Context) (bool, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, protocolTCP, l.address)
+	if err != nil {
+		return false, fmt.Errorf("error on connecting to NMEA receiver: %w", err)
+	}
+	defer conn.Close()
+	clog.Infow("connected to NMEA receiver", "address", l.address)
+
+	stop := closeOnDone(ctx, conn)
+	defer stop()
+
+	decoder := aivdm.NewDecoder()
+	scanner := bufio.NewScanner(conn)
+	received := false
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(l.readTimeout))
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return received, fmt.Errorf("error on reading from NMEA receiver: %w", err)
+			}
+			return received, errors.New("NMEA receiver closed the connection")
+		}
+		received = true
+		l.processSentence(ctx, decoder, scanner.Text(), time.Now())
+	}
+}
+
+func (l *Listener) listenUDP(ctx context.Context) error {
+	conn, err := net.ListenPacket(protocolUDP, l.address)
+	if err != nil {
+		return fmt.Errorf("error on listening for NMEA datagrams: %w", err)
+	}
+	defer conn.Close()
+	clog.Infow("listening for NMEA datagrams", "address", conn.LocalAddr().String())
+
+	stop := closeOnDone(ctx, conn)
+	defer stop()
+
+	decoder := aivdm.NewDecoder()
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("error on reading NMEA datagram: %w", err)
+		}
+		receivedAt := time.Now()
+		// a datagram may hold several sentences
+		for _, line := range bytes.Split(buf[:n], []byte("\n")) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				l.processSentence(ctx, decoder, string(line), receivedAt)
+			}
+		}
+	}
+}
+
+// closeOnDone closes the connection when the context is cancelled to unblock any pending reads. The returned
+// function must be called once the connection is no longer in use.
+func closeOnDone(ctx context.Context, conn io.Closer) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (l *Listener) processSentence(ctx context.Context, decoder *aivdm.Decoder, sentence string,
+	receivedAt time.Time) {
+	msg, err := decoder.Decode(sentence)
+	if err != nil {
+		clog.Warnw("failed to decode NMEA sentence",
+			"error", err.Error(),
+			"sentence", sentence)
+		return
+	}
+	if msg == nil {
+		// waiting on further fragments
+		return
+	}
+
+	if err := l.processMessage(ctx, msg, receivedAt); err != nil {
+		clog.Errorw("failed to process AIS message",
+			"error", err.Error(),
+			"messageType", msg.MessageHeader().MessageType,
+			"mmsi", msg.MessageHeader().MMSI)
+	}
+}
+
+func (l *Listener) processMessage(ctx context.Context, msg aivdm.Message, receivedAt time.Time) error {
+	switch m := msg.(type) {
+	case aivdm.PositionReport:
+		return l.processPosition(ctx, m.Header, "", m.Latitude, m.Longitude, m.Timestamp, receivedAt,
+			domain.NewKinematics(m.SpeedOverGround, m.CourseOverGround, m.TrueHeading, m.NavigationalStatus, m.RateOfTurn),
+			domain.TransponderClassA)
+	case aivdm.StandardClassBPositionReport:
+		return l.processPosition(ctx, m.Header, "", m.Latitude, m.Longitude, m.Timestamp, receivedAt,
+			domain.NewClassBKinematics(m.SpeedOverGround, m.CourseOverGround, m.TrueHeading), domain.TransponderClassB)
+	case aivdm.ExtendedClassBPositionReport:
+		return l.processPosition(ctx, m.Header, m.Name, m.Latitude, m.Longitude, m.Timestamp, receivedAt,
+			domain.NewClassBKinematics(m.SpeedOverGround, m.CourseOverGround, m.TrueHeading), domain.TransponderClassB)
+	case aivdm.StaticVoyageData:
+		return l.collectorService.ProcessStaticData(ctx, domain.ShipStaticData{
+			MMSI:                 m.MMSI,
+			Name:                 m.Name,
+			IMONumber:            m.IMONumber,
+			CallSign:             m.CallSign,
+			ShipType:             m.ShipType,
+			DimensionToBow:       m.DimensionToBow,
+			DimensionToStern:     m.DimensionToStern,
+			DimensionToPort:      m.DimensionToPort,
+			DimensionToStarboard: m.DimensionToStarboard,
+			Draught:              m.Draught,
+			Destination:          m.Destination,
+			ETA:                  domain.NewETA(m.ETAMonth, m.ETADay, m.ETAHour, m.ETAMinute, receivedAt),
+			LastUpdated:          receivedAt,
+		})
+	case aivdm.StaticDataReport:
+		return l.processStaticDataReport(ctx, m, receivedAt)
+	case aivdm.AidToNavigationReport:
+		if m.Latitude == aivdm.LatitudeNotAvailable || m.Longitude == aivdm.LongitudeNotAvailable {
+			return nil
+		}
+		return l.collectorService.ProcessAidToNavigation(ctx, domain.AidToNavigation{
+			MMSI:                 m.MMSI,
+			Name:                 m.Name,
+			AidType:              m.AidType,
+			Latitude:             m.Latitude,
+			Longitude:            m.Longitude,
+			DimensionToBow:       m.DimensionToBow,
+			DimensionToStern:     m.DimensionToStern,
+			DimensionToPort:      m.DimensionToPort,
+			DimensionToStarboard: m.DimensionToStarboard,
+			VirtualAid:           m.VirtualAid,
+			OffPosition:          m.OffPosition,
+			LastUpdated:          domain.ObservationTime(receivedAt, m.Timestamp),
+			ReceivedAt:           receivedAt,
+		})
+	case aivdm.BaseStationReport:
+		if m.Latitude == aivdm.LatitudeNotAvailable || m.Longitude == aivdm.LongitudeNotAvailable {
+			return nil
+		}
+		return l.collectorService.ProcessBaseStation(ctx, domain.BaseStation{
+			MMSI:        m.MMSI,
+			Latitude:    m.Latitude,
+			Longitude:   m.Longitude,
+			LastUpdated: domain.BaseStationTime(m.Year, m.Month, m.Day, m.Hour, m.Minute, m.Second, receivedAt),
+			ReceivedAt:  receivedAt.UTC(),
+		})
+	case aivdm.AddressedSafetyMessage:
+		return l.collectorService.ProcessSafetyAlert(ctx, domain.SafetyAlert{
+			MMSI:            m.MMSI,
+			Kind:            domain.SafetyAlertKindAddressed,
+			DestinationMMSI: m.DestinationMMSI,
+			Text:            m.Text,
+			TransmittedAt:   receivedAt,
+			ReceivedAt:      receivedAt,
+		})
+	case aivdm.SafetyBroadcastMessage:
+		return l.collectorService.ProcessSafetyAlert(ctx, domain.SafetyAlert{
+			MMSI:          m.MMSI,
+			Kind:          domain.SafetyAlertKindBroadcast,
+			Text:          m.Text,
+			TransmittedAt: receivedAt,
+			ReceivedAt:    receivedAt,
+		})
+	default:
+		return nil
+	}
+}
+
+func (l *Listener) processPosition(ctx context.Context, header aivdm.Header, name string, latitude, longitude float64, utcSecond int32,
+	receivedAt time.Time, kinematics domain.Kinematics, transponderClass domain.TransponderClass) error {
+	if latitude == aivdm.LatitudeNotAvailable || longitude == aivdm.LongitudeNotAvailable {
+		return nil
+	}
+
+	ship := domain.NewShip(header.MMSI, name, latitude, longitude, domain.ObservationTime(receivedAt, utcSecond))
+	ship.ReceivedAt = receivedAt.UTC()
+	ship.Kinematics = kinematics
+	ship.TransponderClass = transponderClass
+	return l.collectorService.Process(ctx, *ship)
+}
+
+// processStaticDataReport combines the two parts of a Class B static data report. Part A (the name) is held until
+// part B (the remaining static data) is received.
+func (l *Listener) processStaticDataReport(ctx context.Context, report aivdm.StaticDataReport, receivedAt time.Time) error {
+	l.classBNamesMu.Lock()
+	if report.PartNumber == 0 {
+		if len(l.classBNames) >= maxClassBNames {
+			l.classBNames = make(map[int32]string)
+		}
+		l.classBNames[report.MMSI] = report.Name
+		l.classBNamesMu.Unlock()
+		return nil
+	}
+	name := l.classBNames[report.MMSI]
+	l.classBNamesMu.Unlock()
+
+	return l.collectorService.ProcessStaticData(ctx, domain.ShipStaticData{
+		MMSI:                 report.MMSI,
+		Name:                 name,
+		CallSign:             report.CallSign,
+		ShipType:             report.ShipType,
+		DimensionToBow:       report.DimensionToBow,
+		DimensionToStern:     report.DimensionToStern,
+		DimensionToPort:      report.DimensionToPort,
+		DimensionToStarboard: report.DimensionToStarboard,
+		LastUpdated:          receivedAt,
+	})
+}
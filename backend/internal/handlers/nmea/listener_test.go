@@ -0,0 +1,290 @@
+package nmea
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/aivdm"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+const (
+	positionReportSentence  = "!AIVDM,1,1,,B,177KQJ5000G?tO`K>RA1wUbN0TKH,0*5C"
+	staticDataSentence1     = "!AIVDM,2,1,1,A,55?MbV02;H;s<HtKR20EHE:0@T4@Dn2222222216L961O5Gf0NSQEp6ClRp8,0*1C"
+	staticDataSentence2     = "!AIVDM,2,2,1,A,88888888880,2*25"
+	baseStationSentence     = "!AIVDM,1,1,,A,402=VPAvNEi45wr390M4FP700000,0*28"
+	aidToNavigationSentence = "!AIVDM,1,1,,A,E>jHC6:190VQ62h10W5P0000000Ou32@>QwR01088;g000,4*08"
+	safetyBroadcastSentence = "!AIVDM,1,1,,A,>>O5e@0lt:04=@UHD,2*49"
+)
+
+type MockCollectorService struct {
+	mu               sync.Mutex
+	ships            []domain.Ship
+	staticData       []domain.ShipStaticData
+	aidsToNavigation []domain.AidToNavigation
+	baseStations     []domain.BaseStation
+	safetyAlerts     []domain.SafetyAlert
+}
+
+func (m *MockCollectorService) Process(_ context.Context, ship domain.Ship) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ships = append(m.ships, ship)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessStaticData(_ context.Context, data domain.ShipStaticData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.staticData = append(m.staticData, data)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessAidToNavigation(_ context.Context, aid domain.AidToNavigation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.aidsToNavigation = append(m.aidsToNavigation, aid)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessBaseStation(_ context.Context, station domain.BaseStation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.baseStations = append(m.baseStations, station)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessSafetyAlert(_ context.Context, alert domain.SafetyAlert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.safetyAlerts = append(m.safetyAlerts, alert)
+	return nil
+}
+
+func (m *MockCollectorService) processed() (int, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.ships), len(m.staticData)
+}
+
+func newTestListener(t *testing.T, protocol, address string, collectorService *MockCollectorService) *Listener {
+	t.Helper()
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.NMEAProtocol = protocol
+	cfg.NMEAReconnectMinBackoff = 10 * time.Millisecond
+	cfg.NMEAReconnectMaxBackoff = 50 * time.Millisecond
+
+	listener, err := NewListener(*cfg, address, collectorService)
+	require.NoError(t, err)
+	return listener
+}
+
+func TestListen_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("garbage\r\n" + positionReportSentence + "\r\n" + staticDataSentence1 + "\r\n" +
+				staticDataSentence2 + "\r\n"))
+			conn.Close()
+		}
+	}()
+
+	collectorService := &MockCollectorService{}
+	listener := newTestListener(t, "tcp", ln.Addr().String(), collectorService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- listener.Listen(ctx)
+	}()
+
+	// the listener reconnects after the receiver closes the connection
+	require.Eventually(t, func() bool {
+		ships, staticData := collectorService.processed()
+		return ships >= 2 && staticData >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+
+	collectorService.mu.Lock()
+	defer collectorService.mu.Unlock()
+	ship := collectorService.ships[0]
+	assert.Equal(t, int32(477553000), ship.MMSI)
+	assert.InDelta(t, 47.582833, ship.Latitude, 0.000001)
+	assert.InDelta(t, -122.345833, ship.Longitude, 0.000001)
+	require.NotNil(t, ship.NavigationalStatus)
+	assert.Equal(t, int32(5), *ship.NavigationalStatus)
+	require.NotNil(t, ship.CourseOverGround)
+	assert.Equal(t, 51.0, *ship.CourseOverGround)
+	assert.Equal(t, domain.TransponderClassA, ship.TransponderClass)
+	assert.Equal(t, 15, ship.LastUpdated.Second())
+	assert.WithinDuration(t, time.Now(), ship.ReceivedAt, 5*time.Second)
+
+	data := collectorService.staticData[0]
+	assert.Equal(t, int32(351759000), data.MMSI)
+	assert.Equal(t, "EVER DIADEM", data.Name)
+	assert.Equal(t, "3FOF8", data.CallSign)
+	assert.Equal(t, "NEW YORK", data.Destination)
+	require.NotNil(t, data.ETA)
+	assert.Equal(t, time.May, data.ETA.Month())
+}
+
+func TestListen_UDP(t *testing.T) {
+	collectorService := &MockCollectorService{}
+	listener := newTestListener(t, "udp", "127.0.0.1:0", collectorService)
+
+	// bind to a free port up front so that the test knows where to send datagrams
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener.address = conn.LocalAddr().String()
+	require.NoError(t, conn.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- listener.Listen(ctx)
+	}()
+
+	sender, err := net.Dial("udp", listener.address)
+	require.NoError(t, err)
+	defer sender.Close()
+	require.Eventually(t, func() bool {
+		_, _ = sender.Write([]byte(positionReportSentence + "\r\n"))
+		ships, _ := collectorService.processed()
+		return ships > 0
+	}, 5*time.Second, 50*time.Millisecond)
+	cancel()
+
+	require.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestListen_TCP_ClosesConnectionOnShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	listener := newTestListener(t, "tcp", ln.Addr().String(), &MockCollectorService{})
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- listener.Listen(ctx)
+	}()
+
+	var conn net.Conn
+	select {
+	case conn = <-accepted:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "listener didn't connect")
+	}
+	defer conn.Close()
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+
+	// the receiver sees the connection being closed
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestListen_UDP_ClosesConnectionOnShutdown(t *testing.T) {
+	listener := newTestListener(t, "udp", "127.0.0.1:0", &MockCollectorService{})
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener.address = conn.LocalAddr().String()
+	require.NoError(t, conn.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- listener.Listen(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		// the address can't be bound while the listener is using it
+		conn, err := net.ListenPacket("udp", listener.address)
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+
+	conn, err = net.ListenPacket("udp", listener.address)
+	require.NoError(t, err, "the listener should release the address on shutdown")
+	require.NoError(t, conn.Close())
+}
+
+func TestProcessSentence_AidToNavigationAndBaseStation(t *testing.T) {
+	collectorService := &MockCollectorService{}
+	listener := newTestListener(t, "tcp", "localhost:10110", collectorService)
+	receivedAt := time.Date(2023, time.September, 11, 17, 4, 45, 0, time.UTC)
+
+	decoder := aivdm.NewDecoder()
+	listener.processSentence(context.Background(), decoder, aidToNavigationSentence, receivedAt)
+	listener.processSentence(context.Background(), decoder, baseStationSentence, receivedAt)
+
+	require.Len(t, collectorService.aidsToNavigation, 1)
+	aid := collectorService.aidsToNavigation[0]
+	assert.Equal(t, int32(992351000), aid.MMSI)
+	assert.Equal(t, "BRAMBLE BANK", aid.Name)
+	assert.Equal(t, int32(20), aid.AidType)
+	assert.InDelta(t, 50.79, aid.Latitude, 0.000001)
+	assert.InDelta(t, -1.29, aid.Longitude, 0.000001)
+	assert.Equal(t, time.Date(2023, time.September, 11, 17, 4, 30, 0, time.UTC), aid.LastUpdated)
+	assert.Equal(t, receivedAt, aid.ReceivedAt)
+
+	require.Len(t, collectorService.baseStations, 1)
+	station := collectorService.baseStations[0]
+	assert.Equal(t, int32(2320001), station.MMSI)
+	assert.InDelta(t, 50.8, station.Latitude, 0.000001)
+	assert.InDelta(t, -1.3, station.Longitude, 0.000001)
+	assert.Equal(t, time.Date(2023, time.September, 11, 17, 4, 5, 0, time.UTC), station.LastUpdated)
+}
+
+func TestProcessSentence_SafetyBroadcastMessage(t *testing.T) {
+	collectorService := &MockCollectorService{}
+	listener := newTestListener(t, "tcp", "localhost:10110", collectorService)
+	receivedAt := time.Date(2023, time.September, 11, 17, 4, 45, 0, time.UTC)
+
+	listener.processSentence(context.Background(), aivdm.NewDecoder(), safetyBroadcastSentence, receivedAt)
+
+	assert.Equal(t, []domain.SafetyAlert{{
+		MMSI:          972123456,
+		Kind:          domain.SafetyAlertKindBroadcast,
+		Text:          "MOB ACTIVE",
+		TransmittedAt: receivedAt,
+		ReceivedAt:    receivedAt,
+	}}, collectorService.safetyAlerts)
+}
+
+func TestNewListener_RejectsUnsupportedProtocol(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.NMEAProtocol = "serial"
+
+	_, err = NewListener(*cfg, "localhost:10110", &MockCollectorService{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/ports"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/aisstream"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/capture"
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+// maxLineSize caps the size of a single packet within a capture file
+const maxLineSize = 1024 * 1024
+
+// Replayer reads a capture file of aisstream packets (one JSON packet or capture record per line, optionally gzipped)
+// and passes the packets to the collector service, preserving the original time between packets scaled by a speed multiplier
+type Replayer struct {
+	path             string
+	speed            float64
+	collectorService ports.CollectorService
+}
+
+// NewReplayer creates a replayer for the capture file at the given path. A speed of 1 replays the packets in real
+// time, 10 replays them ten times faster and 0 replays them as fast as possible.
+func NewReplayer(cfg config.Config, path string, collectorService ports.CollectorService) (*Replayer, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, errors.New("replay file must be set")
+	}
+	if cfg.ReplaySpeed < 0 {
+		return nil, fmt.Errorf("invalid replay speed %g (must be zero or positive)", cfg.ReplaySpeed)
+	}
+
+	return &Replayer{
+		path:             path,
+		speed:            cfg.ReplaySpeed,
+		collectorService: collectorService,
+	}, nil
+}
+
+// Name identifies the replayer by its capture file
+func (r *Replayer) Name() string {
+	return "replay:" + r.path
+}
+
+// Listen replays the capture file until it has been read in full or the context is cancelled
+func (r *Replayer) Listen(ctx context.Context) error {
+	f, err := os.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("error on opening capture file: %w", err)
+	}
+	defer f.Close()
+
+	reader, err := decompress(f)
+	if err != nil {
+		return fmt.Errorf("error on reading capture file: %w", err)
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	var previous time.Time
+	var lineNumber, replayed, failed int
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		packet, receivedAt, err := parseLine(line)
+		if err != nil {
+			failed++
+			clog.Warnw("failed to unmarshal packet in capture file",
+				"error", err.Error(),
+				"file", r.path,
+				"line", lineNumber)
+			continue
+		}
+
+		if err := r.wait(ctx, previous, receivedAt); err != nil {
+			return err
+		}
+		if !receivedAt.IsZero() {
+			previous = receivedAt
+		}
+
+		if err := aisstream.ProcessPacket(ctx, r.collectorService, packet, time.Now()); err != nil {
+			failed++
+			clog.Warnw("failed to process packet in capture file",
+				"error", err.Error(),
+				"file", r.path,
+				"line", lineNumber)
+			continue
+		}
+		replayed++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error on reading capture file: %w", err)
+	}
+
+	clog.Infow("finished replaying capture file",
+		"file", r.path,
+		"replayed", replayed,
+		"failed", failed)
+	return nil
+}
+
+// parseLine parses a line that holds either a packet or a capture record (as written by the capture recorder)
+// wrapping a packet. The returned time is when the packet was originally received, or zero if it is not known.
+func parseLine(line []byte) (aisstream.AISPacket, time.Time, error) {
+	var record capture.Record
+	if err := json.Unmarshal(line, &record); err == nil && record.Frame != "" {
+		line = []byte(record.Frame)
+	}
+
+	var packet aisstream.AISPacket
+	if err := json.Unmarshal(line, &packet); err != nil {
+		return aisstream.AISPacket{}, time.Time{}, err
+	}
+	if !record.ReceivedAt.IsZero() {
+		return packet, record.ReceivedAt, nil
+	}
+	return packet, packet.ReceivedAt(time.Time{}), nil
+}
+
+// wait sleeps for the time between the previous packet and the current one, scaled by the replay speed
+func (r *Replayer) wait(ctx context.Context, previous, current time.Time) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if r.speed == 0 || previous.IsZero() || current.IsZero() || !current.After(previous) {
+		return nil
+	}
+
+	delay := time.Duration(float64(current.Sub(previous)) / r.speed)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// decompress returns a reader that transparently decompresses gzipped capture files
+func decompress(f io.Reader) (io.Reader, error) {
+	reader := bufio.NewReader(f)
+	magic, err := reader.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(reader)
+	}
+	return reader, nil
+}
@@ -0,0 +1,173 @@
+package replay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/capture"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+type MockCollectorService struct {
+	mu         sync.Mutex
+	ships      []domain.Ship
+	staticData []domain.ShipStaticData
+}
+
+func (m *MockCollectorService) Process(_ context.Context, ship domain.Ship) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ships = append(m.ships, ship)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessStaticData(_ context.Context, data domain.ShipStaticData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.staticData = append(m.staticData, data)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessAidToNavigation(_ context.Context, _ domain.AidToNavigation) error {
+	// noop
+	return nil
+}
+
+func (m *MockCollectorService) ProcessBaseStation(_ context.Context, _ domain.BaseStation) error {
+	// noop
+	return nil
+}
+
+func (m *MockCollectorService) ProcessSafetyAlert(_ context.Context, _ domain.SafetyAlert) error {
+	// noop
+	return nil
+}
+
+// writeCapture writes a capture file holding the test data packets (and an unparseable line) and returns its path.
+// The packets were received one second apart.
+func writeCapture(t *testing.T, gzipped bool) string {
+	t.Helper()
+
+	var lines []string
+	for _, file := range []string{"../../../testdata/ais_data.json", "../../../testdata/ais_static_data.json"} {
+		packet, err := os.ReadFile(file)
+		require.NoError(t, err)
+		var compacted bytes.Buffer
+		require.NoError(t, json.Compact(&compacted, packet))
+		lines = append(lines, compacted.String())
+	}
+	lines[1] = strings.Replace(lines[1], "18:22:32.318353", "18:22:33.318353", 1)
+	lines = append(lines, "not json")
+	content := []byte(strings.Join(lines, "\n") + "\n")
+
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	if gzipped {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		content = buf.Bytes()
+		path += ".gz"
+	}
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+func newTestReplayer(t *testing.T, path string, speed float64, collectorService *MockCollectorService) *Replayer {
+	t.Helper()
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.ReplaySpeed = speed
+
+	replayer, err := NewReplayer(*cfg, path, collectorService)
+	require.NoError(t, err)
+	return replayer
+}
+
+func TestListen_ReplaysCaptureFile(t *testing.T) {
+	for name, gzipped := range map[string]bool{"plain": false, "gzipped": true} {
+		gzipped := gzipped
+		t.Run(name, func(t *testing.T) {
+			collectorService := &MockCollectorService{}
+			replayer := newTestReplayer(t, writeCapture(t, gzipped), 0, collectorService)
+
+			require.NoError(t, replayer.Listen(context.Background()))
+
+			require.Len(t, collectorService.ships, 1)
+			assert.Equal(t, int32(259000420), collectorService.ships[0].MMSI)
+			assert.Equal(t, time.Date(2022, time.December, 29, 18, 22, 31, 0, time.UTC), collectorService.ships[0].LastUpdated)
+			require.Len(t, collectorService.staticData, 1)
+			assert.Equal(t, int32(259000420), collectorService.staticData[0].MMSI)
+		})
+	}
+}
+
+func TestListen_ReplaysRecordedCapture(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.CaptureDirectory = t.TempDir()
+	recorder, err := capture.NewRecorder(*cfg, "aisstream")
+	require.NoError(t, err)
+
+	packet, err := os.ReadFile("../../../testdata/ais_data.json")
+	require.NoError(t, err)
+	receivedAt := time.Date(2022, time.December, 29, 18, 22, 33, 0, time.UTC)
+	require.NoError(t, recorder.Record(packet, receivedAt))
+	require.NoError(t, recorder.Record([]byte(`{"MessageType":`), receivedAt.Add(time.Second)))
+	require.NoError(t, recorder.Close())
+	files, err := filepath.Glob(filepath.Join(cfg.CaptureDirectory, "*.jsonl.gz"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	collectorService := &MockCollectorService{}
+	replayer := newTestReplayer(t, files[0], 0, collectorService)
+	require.NoError(t, replayer.Listen(context.Background()))
+
+	require.Len(t, collectorService.ships, 1)
+	assert.Equal(t, int32(259000420), collectorService.ships[0].MMSI)
+}
+
+func TestListen_HonoursSpeedMultiplier(t *testing.T) {
+	collectorService := &MockCollectorService{}
+	// the packets are 1s apart so replaying at 10x should take ~100ms
+	replayer := newTestReplayer(t, writeCapture(t, false), 10, collectorService)
+
+	start := time.Now()
+	require.NoError(t, replayer.Listen(context.Background()))
+	elapsed := time.Since(start)
+
+	assert.GreaterOrEqual(t, elapsed, 80*time.Millisecond)
+	assert.Less(t, elapsed, time.Second)
+}
+
+func TestListen_StopsOnContextCancellation(t *testing.T) {
+	// replaying in real time means the second packet is not due for over a second
+	replayer := newTestReplayer(t, writeCapture(t, false), 1, &MockCollectorService{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, replayer.Listen(ctx), context.DeadlineExceeded)
+}
+
+func TestNewReplayer_RejectsNegativeSpeed(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.ReplaySpeed = -1
+
+	_, err = NewReplayer(*cfg, "capture.jsonl", &MockCollectorService{})
+	assert.Error(t, err)
+}
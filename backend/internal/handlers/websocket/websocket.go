@@ -5,130 +5,543 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/mikeewhite/ship-locator/backend/internal/core/ports"
+	"github.com/mikeewhite/ship-locator/backend/internal/handlers/aisstream"
+	"github.com/mikeewhite/ship-locator/backend/pkg/backoff"
 	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
 	"github.com/mikeewhite/ship-locator/backend/pkg/config"
 )
 
+const tracerName = "github.com/mikeewhite/ship-locator/websocket"
+
 const (
-	url                       = "wss://stream.aisstream.io/v0/stream"
-	messageTypePositionReport = "PositionReport"
+	sourceName   = "aisstream"
+	writeTimeout = 10 * time.Second
+	// rejectReasonUnknown is used for rejected messages that don't give a reason
+	rejectReasonUnknown = "unknown"
 )
 
 type SubscriptionMessage struct {
-	APIKey        string        `json:"APIKey"`
-	BoundingBoxes [][][]float64 `json:"BoundingBoxes"`
+	APIKey             string        `json:"APIKey"`
+	BoundingBoxes      [][][]float64 `json:"BoundingBoxes"`
+	FiltersShipMMSI    []string      `json:"FiltersShipMMSI,omitempty"`
+	FilterMessageTypes []string      `json:"FilterMessageTypes,omitempty"`
+}
+
+func newSubscriptionMessage(cfg config.Config) (SubscriptionMessage, error) {
+	if len(cfg.WebSocketBoundingBoxes) == 0 {
+		return SubscriptionMessage{}, errors.New("at least one bounding box must be set")
+	}
+	boundingBoxes := make([][][]float64, len(cfg.WebSocketBoundingBoxes))
+	for i, box := range cfg.WebSocketBoundingBoxes {
+		boundingBoxes[i] = [][]float64{{box.MinLatitude, box.MinLongitude}, {box.MaxLatitude, box.MaxLongitude}}
+	}
+
+	var mmsis []string
+	for _, mmsi := range cfg.WebSocketMMSIFilter {
+		mmsi = strings.TrimSpace(mmsi)
+		if _, err := strconv.ParseInt(mmsi, 10, 32); err != nil {
+			return SubscriptionMessage{}, fmt.Errorf("invalid mmsi '%s' in filter: %w", mmsi, err)
+		}
+		mmsis = append(mmsis, mmsi)
+	}
+
+	var messageTypes []string
+	for _, messageType := range cfg.WebSocketMessageTypeFilter {
+		messageType = strings.TrimSpace(messageType)
+		if messageType == "" {
+			return SubscriptionMessage{}, errors.New("empty message type in filter")
+		}
+		messageTypes = append(messageTypes, messageType)
+	}
+
+	return SubscriptionMessage{
+		APIKey:             cfg.WebSocketAPIKey,
+		BoundingBoxes:      boundingBoxes,
+		FiltersShipMMSI:    mmsis,
+		FilterMessageTypes: messageTypes,
+	}, nil
+}
+
+// ConnectionState describes the state of the listener's connection to the web socket
+type ConnectionState int32
+
+const (
+	StateDisconnected ConnectionState = iota
+	StateConnecting
+	StateConnected
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	default:
+		return "unknown"
+	}
 }
 
-type AISPacket struct {
-	MetaData    map[string]interface{} `json:"MetaData"`
-	MessageType string                 `json:"MessageType"`
-	Message     AISPacketMessage       `json:"Message"`
+type Metrics interface {
+	WebSocketReconnect(source string)
+	WebSocketConnected(source string, connected bool)
+	WebSocketMessageReceived(source string)
+	PacketRejected(source, reason string)
 }
 
-type AISPacketMessage struct {
-	PositionReport *PositionReport `json:"PositionReport,omitempty"`
+// FrameRecorder archives the raw frames read from the web socket
+type FrameRecorder interface {
+	Record(frame []byte, receivedAt time.Time) error
 }
 
-type PositionReport struct {
-	MessageID                 int32   `json:"MessageID"`
-	RepeatIndicator           int32   `json:"RepeatIndicator"`
-	UserID                    int32   `json:"UserID"`
-	Valid                     bool    `json:"Valid"`
-	NavigationalStatus        int32   `json:"NavigationalStatus"`
-	RateOfTurn                int32   `json:"RateOfTurn"`
-	Sog                       float64 `json:"Sog"`
-	PositionAccuracy          bool    `json:"PositionAccuracy"`
-	Longitude                 float64 `json:"Longitude"`
-	Latitude                  float64 `json:"Latitude"`
-	Cog                       float64 `json:"Cog"`
-	TrueHeading               int32   `json:"TrueHeading"`
-	Timestamp                 int32   `json:"Timestamp"`
-	SpecialManoeuvreIndicator int32   `json:"SpecialManoeuvreIndicator"`
-	Spare                     int32   `json:"Spare"`
-	Raim                      bool    `json:"Raim"`
-	CommunicationState        int32   `json:"CommunicationState"`
+// Quarantine samples the raw frames of rejected messages
+type Quarantine interface {
+	Sample(reason string, frame []byte, err error, rejectedAt time.Time) error
+}
+
+// connectionError indicates that the connection to the web socket has been lost and should be re-established
+type connectionError struct {
+	err error
+}
+
+func (e *connectionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *connectionError) Unwrap() error {
+	return e.err
 }
 
 type WebSocketListener struct {
-	apiKey           string
+	// name identifies the listener in logs and metrics and shard is the name of the shard it subscribes to (empty if
+	// the bounding boxes are not sharded)
+	name             string
+	shard            string
+	url              string
 	collectorService ports.CollectorService
-	conn             *websocket.Conn
+	metrics          Metrics
+	recorder         FrameRecorder
+	quarantine       Quarantine
+	backoff          backoff.Backoff
+	pingInterval     time.Duration
+	pongTimeout      time.Duration
+	// rejections limits logging rejected messages to at most once per reason per log interval
+	rejections *clog.Sampler
+
+	// connMu guards the current connection and the subscription, and serialises writes to the connection
+	connMu       sync.Mutex
+	conn         *websocket.Conn
+	subscription SubscriptionMessage
+
+	state      atomic.Int32
+	reconnects atomic.Int64
+}
+
+// NewWebSocketListener creates a listener for the aisstream web socket that subscribes to every configured bounding
+// box. If a recorder is given then every frame read from the web socket is passed to it before being processed. If a
+// quarantine is given then the frames of rejected messages are sampled to it.
+func NewWebSocketListener(cfg config.Config, collectorService ports.CollectorService, metrics Metrics,
+	recorder FrameRecorder, quarantine Quarantine) (*WebSocketListener, error) {
+	return newWebSocketListener(cfg, "", collectorService, metrics, recorder, quarantine)
+}
+
+// NewShardedWebSocketListeners creates a listener per configured shard, each subscribing to its own bounding boxes
+// over a separate connection. If no shards are configured then a single listener is created for every bounding box.
+// The recorder and quarantine (if given) are shared by the listeners.
+func NewShardedWebSocketListeners(cfg config.Config, collectorService ports.CollectorService, metrics Metrics,
+	recorder FrameRecorder, quarantine Quarantine) ([]*WebSocketListener, error) {
+	if len(cfg.WebSocketShards) == 0 {
+		listener, err := NewWebSocketListener(cfg, collectorService, metrics, recorder, quarantine)
+		if err != nil {
+			return nil, err
+		}
+		return []*WebSocketListener{listener}, nil
+	}
+
+	if err := validateShards(cfg); err != nil {
+		return nil, fmt.Errorf("invalid shard config: %w", err)
+	}
+	listeners := make([]*WebSocketListener, 0, len(cfg.WebSocketShards))
+	for _, shard := range cfg.WebSocketShards {
+		listener, err := newWebSocketListener(cfg, shard.Name, collectorService, metrics, recorder, quarantine)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create listener for shard '%s': %w", shard.Name, err)
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
 }
 
-func NewWebSocketListener(cfg config.Config, collectorService ports.CollectorService) (*WebSocketListener, error) {
+func newWebSocketListener(cfg config.Config, shard string, collectorService ports.CollectorService, metrics Metrics,
+	recorder FrameRecorder, quarantine Quarantine) (*WebSocketListener, error) {
+	if strings.TrimSpace(cfg.WebSocketURL) == "" {
+		return nil, errors.New("web socket URL must be set")
+	}
 	if strings.TrimSpace(cfg.WebSocketAPIKey) == "" {
 		return nil, errors.New("web socket API key must be set")
 	}
+	shardCfg, err := shardConfig(cfg, shard)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shard config: %w", err)
+	}
+	subscription, err := newSubscriptionMessage(shardCfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription config: %w", err)
+	}
 
+	name := sourceName
+	if shard != "" {
+		name = sourceName + ":" + shard
+	}
 	return &WebSocketListener{
-		apiKey:           cfg.WebSocketAPIKey,
+		name:             name,
+		shard:            shard,
+		url:              cfg.WebSocketURL,
+		subscription:     subscription,
 		collectorService: collectorService,
+		metrics:          metrics,
+		recorder:         recorder,
+		quarantine:       quarantine,
+		backoff:          backoff.New(cfg.WebSocketReconnectMinBackoff, cfg.WebSocketReconnectMaxBackoff),
+		pingInterval:     cfg.WebSocketPingInterval,
+		pongTimeout:      cfg.WebSocketPongTimeout,
+		rejections:       clog.NewSampler(cfg.WebSocketRejectLogInterval),
 	}, nil
 }
 
-func (wsl *WebSocketListener) Listen(ctx context.Context) error {
-	var dialErr error
-	wsl.conn, _, dialErr = websocket.DefaultDialer.Dial(url, nil)
-	if dialErr != nil {
-		return fmt.Errorf("error on dialing web socket URL: %w", dialErr)
+// Name identifies the listener as the aisstream source, qualified by the name of its shard (if any)
+func (wsl *WebSocketListener) Name() string {
+	return wsl.name
+}
+
+// validateShards checks that every shard and bounding box has a unique name and that each bounding box belongs to
+// exactly one shard
+func validateShards(cfg config.Config) error {
+	boxes := make(map[string]string, len(cfg.WebSocketBoundingBoxes))
+	for _, box := range cfg.WebSocketBoundingBoxes {
+		if _, found := boxes[box.Name]; found {
+			return fmt.Errorf("bounding box '%s' is configured more than once", box.Name)
+		}
+		boxes[box.Name] = ""
 	}
 
-	subMsgBytes, _ := json.Marshal(SubscriptionMessage{
-		APIKey:        wsl.apiKey,
-		BoundingBoxes: [][][]float64{{{-90.0, -180.0}, {90.0, 180.0}}}, // bounding box for the entire world
-	})
-	if err := wsl.conn.WriteMessage(websocket.TextMessage, subMsgBytes); err != nil {
-		return fmt.Errorf("error on subscribing to web socket: %w", err)
+	shards := make(map[string]bool, len(cfg.WebSocketShards))
+	for _, shard := range cfg.WebSocketShards {
+		if shards[shard.Name] {
+			return fmt.Errorf("shard '%s' is configured more than once", shard.Name)
+		}
+		shards[shard.Name] = true
+
+		for _, box := range shard.BoundingBoxes {
+			owner, found := boxes[box]
+			if !found {
+				return fmt.Errorf("unknown bounding box '%s' in shard '%s'", box, shard.Name)
+			}
+			if owner != "" {
+				return fmt.Errorf("bounding box '%s' is in both shard '%s' and shard '%s'", box, owner, shard.Name)
+			}
+			boxes[box] = shard.Name
+		}
+	}
+
+	for _, box := range cfg.WebSocketBoundingBoxes {
+		if boxes[box.Name] == "" {
+			return fmt.Errorf("bounding box '%s' is not in any shard", box.Name)
+		}
+	}
+	return nil
+}
+
+// shardConfig returns a copy of the config whose bounding boxes are restricted to those of the named shard. The config
+// is returned unchanged if the shard name is empty.
+func shardConfig(cfg config.Config, shard string) (config.Config, error) {
+	if shard == "" {
+		return cfg, nil
+	}
+	if err := validateShards(cfg); err != nil {
+		return config.Config{}, err
 	}
 
+	for _, s := range cfg.WebSocketShards {
+		if s.Name != shard {
+			continue
+		}
+		boxes := make(config.BoundingBoxes, 0, len(s.BoundingBoxes))
+		for _, box := range cfg.WebSocketBoundingBoxes {
+			for _, name := range s.BoundingBoxes {
+				if box.Name == name {
+					boxes = append(boxes, box)
+				}
+			}
+		}
+		cfg.WebSocketBoundingBoxes = boxes
+		return cfg, nil
+	}
+	return config.Config{}, fmt.Errorf("shard '%s' is not configured", shard)
+}
+
+// Listen connects to the web socket and processes messages until the context is cancelled. Dropped connections
+// are re-established with a jittered exponential backoff.
+func (wsl *WebSocketListener) Listen(ctx context.Context) error {
+	attempt := 0
 	for {
+		received, err := wsl.connectAndRead(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var connErr *connectionError
+		if !errors.As(err, &connErr) {
+			return err
+		}
+
+		// only back off further if the connection failed before any messages were received
+		if received {
+			attempt = 0
+		}
+		delay := wsl.backoff.Duration(attempt)
+		attempt++
+
+		clog.Warnw("web socket connection lost, reconnecting",
+			"source", wsl.name,
+			"error", err.Error(),
+			"attempt", attempt,
+			"delay", delay.String())
+
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		default:
-			if err := wsl.readAndProcessMessage(); err != nil {
-				return err
-			}
+		case <-time.After(delay):
 		}
+		wsl.reconnects.Add(1)
+		wsl.metrics.WebSocketReconnect(wsl.name)
+	}
+}
+
+// UpdateSubscription replaces the subscription (bounding boxes and filters) with one built from the given config,
+// sending it over the current connection if there is one. The new subscription is also used on any reconnects. A
+// sharded listener only takes the bounding boxes of its own shard, which must still be configured.
+func (wsl *WebSocketListener) UpdateSubscription(cfg config.Config) error {
+	if strings.TrimSpace(cfg.WebSocketAPIKey) == "" {
+		return errors.New("web socket API key must be set")
+	}
+	cfg, err := shardConfig(cfg, wsl.shard)
+	if err != nil {
+		return fmt.Errorf("invalid shard config: %w", err)
+	}
+	subscription, err := newSubscriptionMessage(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid subscription config: %w", err)
+	}
+
+	wsl.connMu.Lock()
+	defer wsl.connMu.Unlock()
+	wsl.subscription = subscription
+	clog.Infow("updated web socket subscription",
+		"source", wsl.name,
+		"boundingBoxes", cfg.WebSocketBoundingBoxes.Names(),
+		"mmsiFilter", subscription.FiltersShipMMSI,
+		"messageTypeFilter", subscription.FilterMessageTypes)
+	if wsl.conn == nil {
+		return nil
 	}
+	return wsl.subscribe(wsl.conn)
+}
+
+// State returns the current state of the connection to the web socket
+func (wsl *WebSocketListener) State() ConnectionState {
+	return ConnectionState(wsl.state.Load())
+}
+
+// ReconnectCount returns the number of times the connection to the web socket has been re-established
+func (wsl *WebSocketListener) ReconnectCount() int64 {
+	return wsl.reconnects.Load()
 }
 
 func (wsl *WebSocketListener) Shutdown() {
+	wsl.connMu.Lock()
+	defer wsl.connMu.Unlock()
+	if wsl.conn == nil {
+		return
+	}
 	err := wsl.conn.Close()
 	if err != nil {
 		clog.Errorf("error on shutting down webhook listener: %s", err.Error())
 	}
 }
 
-func (wsl *WebSocketListener) readAndProcessMessage() error {
-	_, p, err := wsl.conn.ReadMessage()
+// connectAndRead dials and subscribes to the web socket then reads messages until the connection fails. It reports
+// whether any messages were received over the connection.
+func (wsl *WebSocketListener) connectAndRead(ctx context.Context) (bool, error) {
+	wsl.setState(StateConnecting)
+	defer wsl.setState(StateDisconnected)
+
+	conn, err := wsl.connect(ctx)
 	if err != nil {
-		return fmt.Errorf("error on reading message: %w", err)
+		return false, err
+	}
+	defer wsl.closeConn(conn)
+	wsl.setState(StateConnected)
+
+	// close the connection when the context is cancelled (to unblock any pending reads) or stop pinging the
+	// server once the connection has failed
+	done := make(chan struct{})
+	defer close(done)
+	go wsl.keepAlive(ctx, conn, done)
+
+	received := false
+	for {
+		if err := wsl.readAndProcessMessage(ctx, conn); err != nil {
+			return received, err
+		}
+		received = true
 	}
+}
 
-	var packet AISPacket
-	err = json.Unmarshal(p, &packet)
+func (wsl *WebSocketListener) connect(ctx context.Context) (*websocket.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsl.url, nil)
 	if err != nil {
-		return fmt.Errorf("error on unmarshalling packet: %w", err)
+		return nil, &connectionError{fmt.Errorf("error on dialing web socket URL: %w", err)}
 	}
 
-	var shipName string
-	if packetShipName, ok := packet.MetaData["ShipName"]; ok {
-		shipName = packetShipName.(string)
+	wsl.connMu.Lock()
+	defer wsl.connMu.Unlock()
+	if err := wsl.subscribe(conn); err != nil {
+		conn.Close()
+		return nil, &connectionError{err}
 	}
 
-	if packet.MessageType == messageTypePositionReport {
-		positionReport := *packet.Message.PositionReport
-		err = wsl.collectorService.Process(positionReport.UserID, shipName, positionReport.Latitude, positionReport.Longitude)
-		if err != nil {
-			return fmt.Errorf("error on processing webhook message: %w", err)
+	// treat the connection as dead if neither a message nor a pong is received within the timeout
+	_ = conn.SetReadDeadline(time.Now().Add(wsl.pongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsl.pongTimeout))
+	})
+
+	wsl.conn = conn
+
+	return conn, nil
+}
+
+// subscribe sends the subscription message over the connection. The caller must hold connMu.
+func (wsl *WebSocketListener) subscribe(conn *websocket.Conn) error {
+	subMsgBytes, err := json.Marshal(wsl.subscription)
+	if err != nil {
+		return fmt.Errorf("error on marshalling subscription message: %w", err)
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := conn.WriteMessage(websocket.TextMessage, subMsgBytes); err != nil {
+		return fmt.Errorf("error on subscribing to web socket: %w", err)
+	}
+	return nil
+}
+
+func (wsl *WebSocketListener) closeConn(conn *websocket.Conn) {
+	wsl.connMu.Lock()
+	defer wsl.connMu.Unlock()
+	if wsl.conn == conn {
+		wsl.conn = nil
+	}
+	conn.Close()
+}
+
+func (wsl *WebSocketListener) keepAlive(ctx context.Context, conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(wsl.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeTimeout))
+			conn.Close()
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				clog.Warnf("failed to ping web socket: %s", err.Error())
+			}
+		}
+	}
+}
+
+func (wsl *WebSocketListener) setState(state ConnectionState) {
+	wsl.state.Store(int32(state))
+	wsl.metrics.WebSocketConnected(wsl.name, state == StateConnected)
+}
+
+func (wsl *WebSocketListener) readAndProcessMessage(ctx context.Context, conn *websocket.Conn) error {
+	_, p, err := conn.ReadMessage()
+	if err != nil {
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			return &connectionError{fmt.Errorf("web socket closed by server (code %d): %w", closeErr.Code, err)}
 		}
+		return &connectionError{fmt.Errorf("error on reading message: %w", err)}
+	}
+	now := time.Now()
+	_ = conn.SetReadDeadline(now.Add(wsl.pongTimeout))
+	wsl.metrics.WebSocketMessageReceived(wsl.name)
+
+	if wsl.recorder != nil {
+		if err := wsl.recorder.Record(p, now); err != nil {
+			clog.Errorf("failed to record web socket frame: %s", err.Error())
+		}
+	}
+
+	// each message starts a trace that follows its reports through Kafka to the services that store them
+	ctx, span := otel.Tracer(tracerName).Start(ctx, fmt.Sprintf("%s receive", wsl.name),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithNewRoot())
+	defer span.End()
+
+	// a bad message is rejected rather than returned so that it doesn't stop the listener
+	packet, err := aisstream.ParsePacket(p)
+	if err == nil {
+		span.SetAttributes(attribute.Key("ais.message_type").String(packet.MessageType))
+		err = aisstream.ProcessPacket(ctx, wsl.collectorService, packet, now)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		wsl.reject(p, err, now)
 	}
 
 	return nil
 }
+
+// reject counts, logs and (if configured) quarantines a message that could not be processed
+func (wsl *WebSocketListener) reject(frame []byte, err error, rejectedAt time.Time) {
+	reason := rejectReasonUnknown
+	var rejectErr *aisstream.RejectError
+	if errors.As(err, &rejectErr) {
+		reason = rejectErr.Reason
+	}
+
+	wsl.metrics.PacketRejected(wsl.name, reason)
+	if log, unlogged := wsl.rejections.Sample(reason, rejectedAt); log {
+		clog.Warnw("rejected web socket message",
+			"source", wsl.name,
+			"reason", reason,
+			"error", err.Error(),
+			"unlogged", unlogged)
+	}
+	if wsl.quarantine != nil {
+		if err := wsl.quarantine.Sample(reason, frame, err, rejectedAt); err != nil {
+			clog.Errorf("failed to quarantine web socket message: %s", err.Error())
+		}
+	}
+}
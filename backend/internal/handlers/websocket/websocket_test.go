@@ -0,0 +1,577 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/apperrors"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+)
+
+type MockCollectorService struct {
+	mu         sync.Mutex
+	ships      []domain.Ship
+	staticData []domain.ShipStaticData
+}
+
+func (m *MockCollectorService) Process(_ context.Context, ship domain.Ship) error {
+	// validate in the same way as the collector service
+	if err := ship.Validate(); err != nil {
+		return apperrors.NewInvalidReportErr(err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ships = append(m.ships, ship)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessStaticData(_ context.Context, data domain.ShipStaticData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.staticData = append(m.staticData, data)
+	return nil
+}
+
+func (m *MockCollectorService) ProcessAidToNavigation(_ context.Context, _ domain.AidToNavigation) error {
+	// noop
+	return nil
+}
+
+func (m *MockCollectorService) ProcessBaseStation(_ context.Context, _ domain.BaseStation) error {
+	// noop
+	return nil
+}
+
+func (m *MockCollectorService) ProcessSafetyAlert(_ context.Context, _ domain.SafetyAlert) error {
+	// noop
+	return nil
+}
+
+func (m *MockCollectorService) processed() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.ships)
+}
+
+type NoopMetricsClient struct {
+}
+
+func (mc *NoopMetricsClient) WebSocketReconnect(_ string) {}
+
+func (mc *NoopMetricsClient) WebSocketConnected(_ string, _ bool) {}
+
+func (mc *NoopMetricsClient) WebSocketMessageReceived(_ string) {}
+
+func (mc *NoopMetricsClient) PacketRejected(_, _ string) {}
+
+// RecordingMetricsClient records the sources of received messages and the reasons for rejected packets
+type RecordingMetricsClient struct {
+	NoopMetricsClient
+	mu       sync.Mutex
+	received map[string]int
+	rejected map[string]int
+}
+
+func (mc *RecordingMetricsClient) WebSocketMessageReceived(source string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.received[source]++
+}
+
+func (mc *RecordingMetricsClient) receivedFrom(source string) int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.received[source]
+}
+
+func (mc *RecordingMetricsClient) PacketRejected(_, reason string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.rejected[reason]++
+}
+
+type MockQuarantine struct {
+	mu      sync.Mutex
+	reasons []string
+}
+
+func (mq *MockQuarantine) Sample(reason string, _ []byte, _ error, _ time.Time) error {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.reasons = append(mq.reasons, reason)
+	return nil
+}
+
+// newTestServer starts a web socket server that sends the given packet to each client and then drops the connection
+func newTestServer(t *testing.T, packet []byte) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var connections atomic.Int32
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		connections.Add(1)
+
+		// wait for the subscription message before sending data
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, packet)
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "bye"))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &connections
+}
+
+func newTestListener(t *testing.T, serverURL string, collectorService *MockCollectorService) *WebSocketListener {
+	t.Helper()
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.WebSocketAPIKey = "API-KEY"
+	cfg.WebSocketReconnectMinBackoff = 10 * time.Millisecond
+	cfg.WebSocketReconnectMaxBackoff = 50 * time.Millisecond
+	cfg.WebSocketURL = "ws" + strings.TrimPrefix(serverURL, "http")
+
+	listener, err := NewWebSocketListener(*cfg, collectorService, &NoopMetricsClient{}, nil, nil)
+	require.NoError(t, err)
+	return listener
+}
+
+func TestListen_ReconnectsAfterServerClosesConnection(t *testing.T) {
+	packet, err := os.ReadFile("../../../testdata/ais_data.json")
+	require.NoError(t, err)
+	srv, connections := newTestServer(t, packet)
+
+	collectorService := &MockCollectorService{}
+	listener := newTestListener(t, srv.URL, collectorService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- listener.Listen(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return connections.Load() >= 3
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+
+	require.ErrorIs(t, <-errCh, context.Canceled)
+	assert.GreaterOrEqual(t, listener.ReconnectCount(), int64(2))
+	assert.GreaterOrEqual(t, collectorService.processed(), 2)
+	assert.Equal(t, StateDisconnected, listener.State())
+
+	collectorService.mu.Lock()
+	defer collectorService.mu.Unlock()
+	ship := collectorService.ships[0]
+	assert.Equal(t, int32(259000420), ship.MMSI)
+	assert.Equal(t, "AUGUSTSON", ship.Name)
+	require.NotNil(t, ship.SpeedOverGround)
+	assert.Equal(t, 0.0, *ship.SpeedOverGround)
+	require.NotNil(t, ship.CourseOverGround)
+	assert.Equal(t, 308.0, *ship.CourseOverGround)
+	require.NotNil(t, ship.TrueHeading)
+	assert.Equal(t, int32(235), *ship.TrueHeading)
+	assert.Nil(t, ship.NavigationalStatus) // 15 = not defined
+	// observation time is derived from the metadata time and the report's UTC second
+	assert.Equal(t, time.Date(2022, time.December, 29, 18, 22, 31, 0, time.UTC), ship.LastUpdated)
+	assert.WithinDuration(t, time.Now(), ship.ReceivedAt, 5*time.Second)
+}
+
+func TestListen_StopsOnContextCancellationWhileConnected(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// hold the connection open until the client disconnects
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	listener := newTestListener(t, srv.URL, &MockCollectorService{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- listener.Listen(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return listener.State() == StateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errCh:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not stop after context was cancelled")
+	}
+	assert.Equal(t, int64(0), listener.ReconnectCount())
+}
+
+func TestListen_SubscribesWithConfiguredBoundingBoxesAndFilters(t *testing.T) {
+	subscriptions := make(chan SubscriptionMessage, 2)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var sub SubscriptionMessage
+			if err := conn.ReadJSON(&sub); err != nil {
+				return
+			}
+			subscriptions <- sub
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.WebSocketAPIKey = "API-KEY"
+	require.NoError(t, cfg.WebSocketBoundingBoxes.Decode("north-sea:51,-4,61,10;baltic:53,9,66,30"))
+	cfg.WebSocketMMSIFilter = []string{"259000420"}
+	cfg.WebSocketMessageTypeFilter = []string{"PositionReport"}
+	cfg.WebSocketURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	listener, err := NewWebSocketListener(*cfg, &MockCollectorService{}, &NoopMetricsClient{}, nil, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = listener.Listen(ctx)
+	}()
+
+	sub := <-subscriptions
+	assert.Equal(t, SubscriptionMessage{
+		APIKey:             "API-KEY",
+		BoundingBoxes:      [][][]float64{{{51, -4}, {61, 10}}, {{53, 9}, {66, 30}}},
+		FiltersShipMMSI:    []string{"259000420"},
+		FilterMessageTypes: []string{"PositionReport"},
+	}, sub)
+
+	// reloading the config re-sends the subscription over the open connection
+	require.Eventually(t, func() bool {
+		return listener.State() == StateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, cfg.WebSocketBoundingBoxes.Decode("baltic:53,9,66,30"))
+	cfg.WebSocketMMSIFilter = nil
+	require.NoError(t, listener.UpdateSubscription(*cfg))
+
+	sub = <-subscriptions
+	assert.Equal(t, [][][]float64{{{53, 9}, {66, 30}}}, sub.BoundingBoxes)
+	assert.Empty(t, sub.FiltersShipMMSI)
+}
+
+func TestUpdateSubscription_SendsBoundingBoxesReloadedFromSubscriptionFile(t *testing.T) {
+	subscriptions := make(chan SubscriptionMessage, 2)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var sub SubscriptionMessage
+			if err := conn.ReadJSON(&sub); err != nil {
+				return
+			}
+			subscriptions <- sub
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "subscription.env")
+	require.NoError(t, os.WriteFile(path, []byte("SHIPLOC_WEBSOCKETBOUNDINGBOXES=north-sea:51,-4,61,10\n"), 0o644))
+	t.Setenv("SHIPLOC_WEBSOCKETSUBSCRIPTIONFILE", path)
+	t.Setenv("SHIPLOC_WEBSOCKETAPIKEY", "API-KEY")
+	t.Setenv("SHIPLOC_WEBSOCKETURL", "ws"+strings.TrimPrefix(srv.URL, "http"))
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	listener, err := NewWebSocketListener(*cfg, &MockCollectorService{}, &NoopMetricsClient{}, nil, nil)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = listener.Listen(ctx)
+	}()
+	assert.Equal(t, [][][]float64{{{51, -4}, {61, 10}}}, (<-subscriptions).BoundingBoxes)
+	require.Eventually(t, func() bool {
+		return listener.State() == StateConnected
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// as done by the collector on SIGHUP, once the file has been edited
+	require.NoError(t, os.WriteFile(path, []byte("SHIPLOC_WEBSOCKETBOUNDINGBOXES=baltic:53,9,66,30\n"), 0o644))
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	require.NoError(t, listener.UpdateSubscription(*cfg))
+
+	assert.Equal(t, [][][]float64{{{53, 9}, {66, 30}}}, (<-subscriptions).BoundingBoxes)
+}
+
+func TestNewWebSocketListener_RejectsInvalidMMSIFilter(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.WebSocketAPIKey = "API-KEY"
+	cfg.WebSocketMMSIFilter = []string{"not-an-mmsi"}
+
+	_, err = NewWebSocketListener(*cfg, &MockCollectorService{}, &NoopMetricsClient{}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestNewWebSocketListener_RejectsEmptyFilterEntries(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.WebSocketAPIKey = "API-KEY"
+
+	// e.g. from a trailing comma in the environment variable
+	cfg.WebSocketMMSIFilter = []string{"259000420", " "}
+	_, err = NewWebSocketListener(*cfg, &MockCollectorService{}, &NoopMetricsClient{}, nil, nil)
+	assert.Error(t, err)
+
+	cfg.WebSocketMMSIFilter = nil
+	cfg.WebSocketMessageTypeFilter = []string{"PositionReport", " "}
+	_, err = NewWebSocketListener(*cfg, &MockCollectorService{}, &NoopMetricsClient{}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestNewShardedWebSocketListeners_SubscribesEachShardOverItsOwnConnection(t *testing.T) {
+	packet, err := os.ReadFile("../../../testdata/ais_data.json")
+	require.NoError(t, err)
+	subscriptions := make(chan SubscriptionMessage, 2)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var sub SubscriptionMessage
+		if err := conn.ReadJSON(&sub); err != nil {
+			return
+		}
+		subscriptions <- sub
+		_ = conn.WriteMessage(websocket.TextMessage, packet)
+		// hold the connection open until the client disconnects
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.WebSocketAPIKey = "API-KEY"
+	cfg.WebSocketURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+	require.NoError(t, cfg.WebSocketBoundingBoxes.Decode("north-sea:51,-4,61,10;baltic:53,9,66,30;south-china-sea:0,100,25,125"))
+	require.NoError(t, cfg.WebSocketShards.Decode("europe:north-sea,baltic;asia:south-china-sea"))
+
+	collectorService := &MockCollectorService{}
+	metricsClient := &RecordingMetricsClient{received: make(map[string]int), rejected: make(map[string]int)}
+	listeners, err := NewShardedWebSocketListeners(*cfg, collectorService, metricsClient, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, listeners, 2)
+	assert.Equal(t, "aisstream:europe", listeners[0].Name())
+	assert.Equal(t, "aisstream:asia", listeners[1].Name())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, listener := range listeners {
+		go func(listener *WebSocketListener) {
+			_ = listener.Listen(ctx)
+		}(listener)
+	}
+
+	var boundingBoxes [][][][]float64
+	for i := 0; i < 2; i++ {
+		boundingBoxes = append(boundingBoxes, (<-subscriptions).BoundingBoxes)
+	}
+	assert.ElementsMatch(t, [][][][]float64{
+		{{{51, -4}, {61, 10}}, {{53, 9}, {66, 30}}},
+		{{{0, 100}, {25, 125}}},
+	}, boundingBoxes)
+
+	// both shards feed the same collector service and are counted separately
+	require.Eventually(t, func() bool {
+		return collectorService.processed() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, metricsClient.receivedFrom("aisstream:europe"))
+	assert.Equal(t, 1, metricsClient.receivedFrom("aisstream:asia"))
+
+	// a shard can't be reloaded from a config that no longer contains it
+	require.NoError(t, cfg.WebSocketShards.Decode("asia:north-sea,baltic,south-china-sea"))
+	assert.Error(t, listeners[0].UpdateSubscription(*cfg))
+}
+
+func TestNewShardedWebSocketListeners_RejectsInvalidShards(t *testing.T) {
+	tt := map[string]string{
+		"unknown bounding box":        "europe:north-sea,baltic;asia:south-china-sea",
+		"bounding box in two shards":  "europe:north-sea,baltic;asia:baltic",
+		"bounding box not in a shard": "europe:north-sea",
+		"shard configured twice":      "europe:north-sea;europe:baltic",
+	}
+
+	for name, value := range tt {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := config.Load()
+			require.NoError(t, err)
+			cfg.WebSocketAPIKey = "API-KEY"
+			require.NoError(t, cfg.WebSocketBoundingBoxes.Decode("north-sea:51,-4,61,10;baltic:53,9,66,30"))
+			require.NoError(t, cfg.WebSocketShards.Decode(value))
+
+			_, err = NewShardedWebSocketListeners(*cfg, &MockCollectorService{}, &NoopMetricsClient{}, nil, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewShardedWebSocketListeners_RejectsDuplicateBoundingBoxNames(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.WebSocketAPIKey = "API-KEY"
+	box := config.BoundingBox{Name: "north-sea", MinLatitude: 51, MinLongitude: -4, MaxLatitude: 61, MaxLongitude: 10}
+	cfg.WebSocketBoundingBoxes = config.BoundingBoxes{box, box}
+	require.NoError(t, cfg.WebSocketShards.Decode("europe:north-sea"))
+
+	_, err = NewShardedWebSocketListeners(*cfg, &MockCollectorService{}, &NoopMetricsClient{}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestListen_ProcessesShipStaticData(t *testing.T) {
+	packet, err := os.ReadFile("../../../testdata/ais_static_data.json")
+	require.NoError(t, err)
+	srv, _ := newTestServer(t, packet)
+
+	collectorService := &MockCollectorService{}
+	listener := newTestListener(t, srv.URL, collectorService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = listener.Listen(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		collectorService.mu.Lock()
+		defer collectorService.mu.Unlock()
+		return len(collectorService.staticData) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	collectorService.mu.Lock()
+	defer collectorService.mu.Unlock()
+	data := collectorService.staticData[0]
+	assert.Equal(t, int32(259000420), data.MMSI)
+	assert.Equal(t, "AUGUSTSON           ", data.Name) // normalised by the collector service
+	assert.Equal(t, int32(9000000), data.IMONumber)
+	assert.Equal(t, int32(70), data.ShipType)
+	assert.Equal(t, int32(50), data.Length())
+	assert.Equal(t, int32(11), data.Beam())
+	assert.Equal(t, 5.2, data.Draught)
+	assert.Equal(t, time.Date(2022, time.December, 29, 18, 22, 32, 318353000, time.UTC), data.LastUpdated)
+	require.NotNil(t, data.ETA)
+	assert.Equal(t, time.Date(2022, time.December, 30, 8, 30, 0, 0, time.UTC), *data.ETA)
+}
+
+func TestListen_RejectsBadMessagesWithoutStopping(t *testing.T) {
+	packet, err := os.ReadFile("../../../testdata/ais_data.json")
+	require.NoError(t, err)
+	badLatitude := strings.Replace(string(packet), `"Latitude":66.02695`, `"Latitude":91`, 1)
+	badShipName := strings.Replace(string(packet), `"ShipName":"AUGUSTSON"`, `"ShipName":1234`, 1)
+	frames := []string{
+		`not json`,
+		`{"MessageType":"PositionReport","Message":{}}`,
+		badLatitude,
+		badShipName,
+		string(packet),
+	}
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		for _, frame := range frames {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(frame))
+		}
+		// hold the connection open until the client disconnects
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.WebSocketAPIKey = "API-KEY"
+	cfg.WebSocketURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+	collectorService := &MockCollectorService{}
+	metricsClient := &RecordingMetricsClient{received: make(map[string]int), rejected: make(map[string]int)}
+	quarantine := &MockQuarantine{}
+	listener, err := NewWebSocketListener(*cfg, collectorService, metricsClient, nil, quarantine)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = listener.Listen(ctx)
+	}()
+
+	// the ship with a non-string name in its metadata is processed without a name
+	require.Eventually(t, func() bool {
+		return collectorService.processed() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, StateConnected, listener.State())
+	assert.Equal(t, int64(0), listener.ReconnectCount())
+
+	metricsClient.mu.Lock()
+	defer metricsClient.mu.Unlock()
+	assert.Equal(t, map[string]int{
+		"unmarshal_error":         1,
+		"missing_message":         1,
+		"invalid_position_report": 1,
+	}, metricsClient.rejected)
+	quarantine.mu.Lock()
+	defer quarantine.mu.Unlock()
+	assert.Equal(t, []string{"unmarshal_error", "missing_message", "invalid_position_report"}, quarantine.reasons)
+}
@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/mikeewhite/ship-locator/backend/pkg/apperrors"
+)
+
+// storeErr marks errors caused by Postgres being unreachable, restarting or out of connections as the store being
+// unavailable, leaving any other errors as they are
+func storeErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// connection exceptions (08), insufficient resources (53) and the server shutting down or starting up (57P)
+		for _, class := range []string{"08", "53", "57P"} {
+			if strings.HasPrefix(pgErr.Code, class) {
+				return apperrors.NewStoreUnavailableErr(err)
+			}
+		}
+		return err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || pgconn.Timeout(err) || pgconn.SafeToRetry(err) {
+		return apperrors.NewStoreUnavailableErr(err)
+	}
+	return err
+}
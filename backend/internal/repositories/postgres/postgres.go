@@ -26,15 +26,38 @@ type Postgres struct {
 
 const (
 	selectSQL = `
-			SELECT name, latitude, longitude, last_updated
+			SELECT name, latitude, longitude, last_updated, received_at, transponder_class, implausible_reason, speed_over_ground, course_over_ground, true_heading,
+				navigational_status, rate_of_turn, rate_of_turn_no_turn_indicator, imo_number, call_sign, ship_type, dimension_to_bow,
+				dimension_to_stern, dimension_to_port, dimension_to_starboard, draught, destination, eta, static_last_updated
 			FROM ships
-			WHERE mmsi=$1`
+			WHERE mmsi=$1 AND latitude IS NOT NULL AND longitude IS NOT NULL`
+	// reports older than those already stored are ignored so that redelivered or out of order messages can't overwrite
+	// newer data (a ship with only static data has no position to protect)
 	updateSQL = `
-			INSERT INTO ships (mmsi, name, latitude, longitude, last_updated)
-			VALUES ($1, $2, $3, $4, $5)
+			INSERT INTO ships (mmsi, name, latitude, longitude, last_updated, received_at, transponder_class,
+				implausible_reason, speed_over_ground, course_over_ground, true_heading, navigational_status, rate_of_turn,
+				rate_of_turn_no_turn_indicator)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 			ON CONFLICT (mmsi)
 			DO
-				UPDATE SET name = EXCLUDED.name, latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude, last_updated = EXCLUDED.last_updated`
+				UPDATE SET name = COALESCE(NULLIF(EXCLUDED.name, ''), ships.name), latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude, last_updated = EXCLUDED.last_updated,
+					received_at = EXCLUDED.received_at, transponder_class = EXCLUDED.transponder_class,
+					implausible_reason = EXCLUDED.implausible_reason, speed_over_ground = EXCLUDED.speed_over_ground, course_over_ground = EXCLUDED.course_over_ground,
+					true_heading = EXCLUDED.true_heading, navigational_status = EXCLUDED.navigational_status,
+					rate_of_turn = EXCLUDED.rate_of_turn, rate_of_turn_no_turn_indicator = EXCLUDED.rate_of_turn_no_turn_indicator
+				WHERE ships.latitude IS NULL OR ships.last_updated <= EXCLUDED.last_updated`
+	updateStaticDataSQL = `
+			INSERT INTO ships (mmsi, name, imo_number, call_sign, ship_type, dimension_to_bow, dimension_to_stern,
+				dimension_to_port, dimension_to_starboard, draught, destination, eta, static_last_updated)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (mmsi)
+			DO
+				UPDATE SET name = COALESCE(NULLIF(EXCLUDED.name, ''), ships.name), imo_number = EXCLUDED.imo_number,
+					call_sign = EXCLUDED.call_sign, ship_type = EXCLUDED.ship_type, dimension_to_bow = EXCLUDED.dimension_to_bow,
+					dimension_to_stern = EXCLUDED.dimension_to_stern, dimension_to_port = EXCLUDED.dimension_to_port,
+					dimension_to_starboard = EXCLUDED.dimension_to_starboard, draught = EXCLUDED.draught,
+					destination = EXCLUDED.destination, eta = EXCLUDED.eta, static_last_updated = EXCLUDED.static_last_updated
+				WHERE ships.static_last_updated IS NULL OR ships.static_last_updated <= EXCLUDED.static_last_updated`
 )
 
 func NewPostgres(ctx context.Context, cfg config.Config, metrics Metrics) (*Postgres, error) {
@@ -66,16 +89,39 @@ func (pg *Postgres) Get(ctx context.Context, mmsi int32) (domain.Ship, error) {
 	var latitude float64
 	var longitude float64
 	var updatedAt time.Time
-
-	err := pg.pool.QueryRow(ctx, selectSQL, mmsi).Scan(&name, &latitude, &longitude, &updatedAt)
+	var receivedAt *time.Time
+	var transponderClass *string
+	var implausibleReason *string
+	var kinematics domain.Kinematics
+	var static staticDataRow
+
+	err := pg.pool.QueryRow(ctx, selectSQL, mmsi).Scan(&name, &latitude, &longitude, &updatedAt, &receivedAt, &transponderClass,
+		&implausibleReason,
+		&kinematics.SpeedOverGround, &kinematics.CourseOverGround, &kinematics.TrueHeading,
+		&kinematics.NavigationalStatus, &kinematics.RateOfTurn, &kinematics.RateOfTurnNoTurnIndicator,
+		&static.imoNumber, &static.callSign, &static.shipType, &static.dimensionToBow, &static.dimensionToStern,
+		&static.dimensionToPort, &static.dimensionToStarboard, &static.draught, &static.destination, &static.eta,
+		&static.lastUpdated)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return domain.Ship{}, apperrors.NewNoShipFoundErr(mmsi)
 	}
 	if err != nil {
-		return domain.Ship{}, fmt.Errorf("error on querying ship with id '%d': %w", mmsi, err)
+		return domain.Ship{}, fmt.Errorf("error on querying ship with id '%d': %w", mmsi, storeErr(err))
 	}
 
-	return *domain.NewShip(mmsi, name, latitude, longitude, updatedAt), nil
+	ship := domain.NewShip(mmsi, name, latitude, longitude, updatedAt)
+	ship.Kinematics = kinematics
+	if receivedAt != nil {
+		ship.ReceivedAt = receivedAt.UTC()
+	}
+	if transponderClass != nil {
+		ship.TransponderClass = domain.TransponderClass(*transponderClass)
+	}
+	if implausibleReason != nil {
+		ship.ImplausibleReason = domain.ImplausibleReason(*implausibleReason)
+	}
+	ship.StaticData = static.toDomainEntity(mmsi, name)
+	return *ship, nil
 }
 
 func (pg *Postgres) Store(ctx context.Context, ships []domain.Ship) error {
@@ -83,9 +129,21 @@ func (pg *Postgres) Store(ctx context.Context, ships []domain.Ship) error {
 	defer pg.metrics.DBQueryTime("store_ship_data", start)
 
 	for _, ship := range ships {
-		_, err := pg.pool.Exec(ctx, updateSQL, ship.MMSI, ship.Name, ship.Latitude, ship.Longitude, ship.LastUpdated)
+		var transponderClass *string
+		if ship.TransponderClass != "" {
+			class := string(ship.TransponderClass)
+			transponderClass = &class
+		}
+		var implausibleReason *string
+		if ship.ImplausibleReason != "" {
+			reason := string(ship.ImplausibleReason)
+			implausibleReason = &reason
+		}
+		_, err := pg.pool.Exec(ctx, updateSQL, ship.MMSI, ship.Name, ship.Latitude, ship.Longitude, ship.LastUpdated,
+			nullableTime(ship.ReceivedAt), transponderClass, implausibleReason, ship.SpeedOverGround, ship.CourseOverGround, ship.TrueHeading, ship.NavigationalStatus, ship.RateOfTurn,
+			ship.RateOfTurnNoTurnIndicator)
 		if err != nil {
-			return fmt.Errorf("error on inserting ship with mmsi '%d': %w", ship.MMSI, err)
+			return fmt.Errorf("error on inserting ship with mmsi '%d': %w", ship.MMSI, storeErr(err))
 		}
 	}
 
@@ -93,6 +151,79 @@ func (pg *Postgres) Store(ctx context.Context, ships []domain.Ship) error {
 	return nil
 }
 
+func (pg *Postgres) StoreStaticData(ctx context.Context, data []domain.ShipStaticData) error {
+	start := time.Now()
+	defer pg.metrics.DBQueryTime("store_ship_static_data", start)
+
+	for _, d := range data {
+		_, err := pg.pool.Exec(ctx, updateStaticDataSQL, d.MMSI, d.Name, d.IMONumber, d.CallSign, d.ShipType,
+			d.DimensionToBow, d.DimensionToStern, d.DimensionToPort, d.DimensionToStarboard, d.Draught, d.Destination,
+			d.ETA, d.LastUpdated)
+		if err != nil {
+			return fmt.Errorf("error on inserting static data for ship with mmsi '%d': %w", d.MMSI, storeErr(err))
+		}
+	}
+
+	clog.Infof("Stored static data for %d ships in Postgres in %d ms", len(data), time.Since(start).Milliseconds())
+	return nil
+}
+
 func (pg *Postgres) Shutdown(ctx context.Context) {
 	pg.pool.Close()
 }
+
+// staticDataRow holds the nullable static data columns of a row in the ships table
+type staticDataRow struct {
+	imoNumber            *int32
+	callSign             *string
+	shipType             *int32
+	dimensionToBow       *int32
+	dimensionToStern     *int32
+	dimensionToPort      *int32
+	dimensionToStarboard *int32
+	draught              *float64
+	destination          *string
+	eta                  *time.Time
+	lastUpdated          *time.Time
+}
+
+func (r staticDataRow) toDomainEntity(mmsi int32, name string) *domain.ShipStaticData {
+	if r.lastUpdated == nil {
+		return nil
+	}
+	data := &domain.ShipStaticData{
+		MMSI:                 mmsi,
+		Name:                 name,
+		IMONumber:            valueOrZero(r.imoNumber),
+		CallSign:             valueOrZero(r.callSign),
+		ShipType:             valueOrZero(r.shipType),
+		DimensionToBow:       valueOrZero(r.dimensionToBow),
+		DimensionToStern:     valueOrZero(r.dimensionToStern),
+		DimensionToPort:      valueOrZero(r.dimensionToPort),
+		DimensionToStarboard: valueOrZero(r.dimensionToStarboard),
+		Draught:              valueOrZero(r.draught),
+		Destination:          valueOrZero(r.destination),
+		LastUpdated:          r.lastUpdated.UTC(),
+	}
+	if r.eta != nil {
+		eta := r.eta.UTC()
+		data.ETA = &eta
+	}
+	return data
+}
+
+func valueOrZero[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
+}
+
+// nullableTime converts a zero time into a NULL column value
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -22,12 +22,14 @@ func TestGet_NoMatchingResult(t *testing.T) {
 
 func TestStore(t *testing.T) {
 	timestamp, _ := time.Parse(time.RFC3339, "2023-09-11T17:04:05Z")
+	receivedAt := timestamp.Add(3 * time.Second)
 	ship := domain.Ship{
 		MMSI:        259000420,
 		Name:        "AUGUSTSON",
 		Latitude:    66.02695,
 		Longitude:   12.253821666666665,
 		LastUpdated: timestamp,
+		ReceivedAt:  receivedAt,
 	}
 	ships := []domain.Ship{ship}
 
@@ -43,6 +45,7 @@ func TestStore(t *testing.T) {
 	assert.Equal(t, 66.02695, returnedShip.Latitude)
 	assert.Equal(t, 12.253821666666665, returnedShip.Longitude)
 	assert.Equal(t, timestamp, returnedShip.LastUpdated)
+	assert.Equal(t, receivedAt, returnedShip.ReceivedAt)
 }
 
 func TestStore_OverwritesExistingEntries(t *testing.T) {
@@ -74,3 +77,168 @@ func TestStore_OverwritesExistingEntries(t *testing.T) {
 	// check that the updated_at field has been updated
 	assert.True(t, updatedShip.LastUpdated.After(now))
 }
+
+func TestStore_IgnoresOlderPositions(t *testing.T) {
+	timestamp, _ := time.Parse(time.RFC3339, "2023-09-11T17:04:05Z")
+	ship := domain.Ship{
+		MMSI:        259000420,
+		Name:        "AUGUSTSON",
+		Latitude:    66.02695,
+		Longitude:   12.253821666666665,
+		LastUpdated: timestamp,
+	}
+
+	tv := setup(t)
+	require.NoError(t, tv.pg.Store(context.Background(), []domain.Ship{ship}))
+
+	// a position observed before the stored one (e.g. a redelivered message) is ignored
+	older := ship
+	older.Latitude = 66.01
+	older.LastUpdated = timestamp.Add(-time.Minute)
+	require.NoError(t, tv.pg.Store(context.Background(), []domain.Ship{older}))
+
+	returnedShip, err := tv.pg.Get(context.Background(), 259000420)
+	require.NoError(t, err)
+	assert.Equal(t, 66.02695, returnedShip.Latitude)
+	assert.Equal(t, timestamp, returnedShip.LastUpdated)
+}
+
+func TestStoreStaticData_IgnoresOlderStaticData(t *testing.T) {
+	timestamp, _ := time.Parse(time.RFC3339, "2023-09-11T17:04:05Z")
+	staticData := domain.ShipStaticData{
+		MMSI:        259000420,
+		Name:        "AUGUSTSON",
+		Destination: "BODO",
+		LastUpdated: timestamp,
+	}
+	ship := domain.Ship{
+		MMSI:        259000420,
+		Latitude:    66.02695,
+		Longitude:   12.253821666666665,
+		LastUpdated: timestamp,
+	}
+
+	tv := setup(t)
+	require.NoError(t, tv.pg.StoreStaticData(context.Background(), []domain.ShipStaticData{staticData}))
+	require.NoError(t, tv.pg.Store(context.Background(), []domain.Ship{ship}))
+
+	older := staticData
+	older.Destination = "TROMSO"
+	older.LastUpdated = timestamp.Add(-time.Minute)
+	require.NoError(t, tv.pg.StoreStaticData(context.Background(), []domain.ShipStaticData{older}))
+
+	returnedShip, err := tv.pg.Get(context.Background(), 259000420)
+	require.NoError(t, err)
+	require.NotNil(t, returnedShip.StaticData)
+	assert.Equal(t, "BODO", returnedShip.StaticData.Destination)
+}
+
+func TestStoreStaticData_MergesWithPositionData(t *testing.T) {
+	positionTimestamp, _ := time.Parse(time.RFC3339, "2023-09-11T17:04:05Z")
+	staticTimestamp, _ := time.Parse(time.RFC3339, "2023-09-11T17:06:00Z")
+	eta, _ := time.Parse(time.RFC3339, "2023-09-12T08:30:00Z")
+	staticData := domain.ShipStaticData{
+		MMSI:                 259000420,
+		Name:                 "AUGUSTSON",
+		IMONumber:            9000000,
+		CallSign:             "LAGV",
+		ShipType:             70,
+		DimensionToBow:       40,
+		DimensionToStern:     10,
+		DimensionToPort:      5,
+		DimensionToStarboard: 6,
+		Draught:              5.2,
+		Destination:          "BODO",
+		ETA:                  &eta,
+		LastUpdated:          staticTimestamp,
+	}
+
+	tv := setup(t)
+
+	// ships with static data but no position cannot be located
+	require.NoError(t, tv.pg.StoreStaticData(context.Background(), []domain.ShipStaticData{staticData}))
+	_, err := tv.pg.Get(context.Background(), 259000420)
+	expErr := apperrors.NewNoShipFoundErr(259000420)
+	assert.ErrorAs(t, err, &expErr)
+
+	// storing a position (without a name) keeps the previously stored static data and name
+	ship := domain.Ship{
+		MMSI:        259000420,
+		Latitude:    66.02695,
+		Longitude:   12.253821666666665,
+		LastUpdated: positionTimestamp,
+	}
+	require.NoError(t, tv.pg.Store(context.Background(), []domain.Ship{ship}))
+
+	returnedShip, err := tv.pg.Get(context.Background(), 259000420)
+	require.NoError(t, err)
+	assert.Equal(t, "AUGUSTSON", returnedShip.Name)
+	assert.Equal(t, 66.02695, returnedShip.Latitude)
+	assert.Equal(t, positionTimestamp, returnedShip.LastUpdated)
+	require.NotNil(t, returnedShip.StaticData)
+	assert.Equal(t, staticData, *returnedShip.StaticData)
+}
+
+func TestStore_Kinematics(t *testing.T) {
+	ship := domain.Ship{
+		MMSI:        259000420,
+		Name:        "AUGUSTSON",
+		Latitude:    66.02695,
+		Longitude:   12.253821666666665,
+		Kinematics:  domain.NewKinematics(12.3, 308, 511, 0, -128),
+		LastUpdated: time.Now().UTC(),
+	}
+
+	tv := setup(t)
+	require.NoError(t, tv.pg.Store(context.Background(), []domain.Ship{ship}))
+
+	returnedShip, err := tv.pg.Get(context.Background(), 259000420)
+	require.NoError(t, err)
+	assert.Equal(t, ship.Kinematics, returnedShip.Kinematics)
+	assert.Empty(t, returnedShip.TransponderClass)
+	assert.Nil(t, returnedShip.TrueHeading)
+	assert.Nil(t, returnedShip.RateOfTurn)
+}
+
+func TestStore_TransponderClass(t *testing.T) {
+	ship := domain.Ship{
+		MMSI:             235000000,
+		Name:             "SEA BREEZE",
+		Latitude:         50.8,
+		Longitude:        -1.3,
+		Kinematics:       domain.NewClassBKinematics(5.5, 90, 511),
+		TransponderClass: domain.TransponderClassB,
+		LastUpdated:      time.Now().UTC(),
+	}
+
+	tv := setup(t)
+	require.NoError(t, tv.pg.Store(context.Background(), []domain.Ship{ship}))
+
+	returnedShip, err := tv.pg.Get(context.Background(), 235000000)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TransponderClassB, returnedShip.TransponderClass)
+}
+
+func TestStore_ImplausibleReason(t *testing.T) {
+	ship := domain.Ship{
+		MMSI:              235000000,
+		Name:              "SEA BREEZE",
+		Latitude:          50.8,
+		Longitude:         -1.3,
+		ImplausibleReason: domain.ImplausibleReasonImpliedSpeed,
+		LastUpdated:       time.Now().UTC(),
+	}
+
+	tv := setup(t)
+	require.NoError(t, tv.pg.Store(context.Background(), []domain.Ship{ship}))
+	returnedShip, err := tv.pg.Get(context.Background(), 235000000)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImplausibleReasonImpliedSpeed, returnedShip.ImplausibleReason)
+
+	// a later plausible position clears the flag
+	ship.ImplausibleReason = ""
+	require.NoError(t, tv.pg.Store(context.Background(), []domain.Ship{ship}))
+	returnedShip, err = tv.pg.Get(context.Background(), 235000000)
+	require.NoError(t, err)
+	assert.Empty(t, returnedShip.ImplausibleReason)
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+)
+
+const (
+	selectSafetyAlertsSQL = `
+			SELECT mmsi, kind, destination_mmsi, text, latitude, longitude, transmitted_at, received_at
+			FROM safety_alerts
+			WHERE transmitted_at >= $1
+			ORDER BY transmitted_at DESC, id DESC
+			LIMIT $2`
+	// alerts redelivered by Kafka are ignored
+	insertSafetyAlertSQL = `
+			INSERT INTO safety_alerts (mmsi, kind, destination_mmsi, text, latitude, longitude, transmitted_at, received_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (mmsi, kind, destination_mmsi, transmitted_at) DO NOTHING`
+)
+
+// ListSafetyAlerts returns the alerts transmitted since the given time, most recent first
+func (pg *Postgres) ListSafetyAlerts(ctx context.Context, since time.Time, limit int) ([]domain.SafetyAlert, error) {
+	defer pg.metrics.DBQueryTime("list_safety_alerts", time.Now())
+
+	rows, err := pg.pool.Query(ctx, selectSafetyAlertsSQL, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error on querying safety alerts: %w", storeErr(err))
+	}
+	alerts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.SafetyAlert, error) {
+		var alert domain.SafetyAlert
+		var kind string
+		var text *string
+		var receivedAt *time.Time
+		err := row.Scan(&alert.MMSI, &kind, &alert.DestinationMMSI, &text, &alert.Latitude, &alert.Longitude,
+			&alert.TransmittedAt, &receivedAt)
+		alert.Kind = domain.SafetyAlertKind(kind)
+		alert.Text = valueOrZero(text)
+		alert.TransmittedAt = alert.TransmittedAt.UTC()
+		if receivedAt != nil {
+			alert.ReceivedAt = receivedAt.UTC()
+		}
+		return alert, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on reading safety alerts: %w", storeErr(err))
+	}
+	return alerts, nil
+}
+
+func (pg *Postgres) StoreSafetyAlerts(ctx context.Context, alerts []domain.SafetyAlert) ([]domain.SafetyAlert, error) {
+	start := time.Now()
+	defer pg.metrics.DBQueryTime("store_safety_alerts", start)
+
+	var stored []domain.SafetyAlert
+	for _, a := range alerts {
+		tag, err := pg.pool.Exec(ctx, insertSafetyAlertSQL, a.MMSI, string(a.Kind), a.DestinationMMSI, a.Text, a.Latitude,
+			a.Longitude, a.TransmittedAt, nullableTime(a.ReceivedAt))
+		if err != nil {
+			return nil, fmt.Errorf("error on inserting safety alert from mmsi '%d': %w", a.MMSI, storeErr(err))
+		}
+		if tag.RowsAffected() > 0 {
+			stored = append(stored, a)
+		}
+	}
+
+	clog.Infof("Stored %d safety alerts in Postgres in %d ms", len(stored), time.Since(start).Milliseconds())
+	return stored, nil
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+)
+
+func TestStoreSafetyAlerts_ListsRecentAlerts(t *testing.T) {
+	timestamp, _ := time.Parse(time.RFC3339, "2023-09-11T17:04:05Z")
+	latitude, longitude := 50.8, -1.3
+	beacon := domain.SafetyAlert{
+		MMSI:          972123456,
+		Kind:          domain.SafetyAlertKindBeacon,
+		Latitude:      &latitude,
+		Longitude:     &longitude,
+		TransmittedAt: timestamp,
+		ReceivedAt:    timestamp.Add(2 * time.Second),
+	}
+	addressed := domain.SafetyAlert{
+		MMSI:            235000000,
+		Kind:            domain.SafetyAlertKindAddressed,
+		DestinationMMSI: 2320001,
+		Text:            "ENGINE FAILURE",
+		TransmittedAt:   timestamp.Add(time.Minute),
+	}
+	old := domain.SafetyAlert{
+		MMSI:          2320001,
+		Kind:          domain.SafetyAlertKindBroadcast,
+		Text:          "GALE WARNING",
+		TransmittedAt: timestamp.Add(-time.Hour),
+	}
+
+	tv := setup(t)
+	stored, err := tv.pg.StoreSafetyAlerts(context.Background(), []domain.SafetyAlert{beacon, addressed, old})
+	require.NoError(t, err)
+	assert.Len(t, stored, 3)
+	// redelivered alerts are ignored
+	stored, err = tv.pg.StoreSafetyAlerts(context.Background(), []domain.SafetyAlert{beacon})
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+
+	alerts, err := tv.pg.ListSafetyAlerts(context.Background(), timestamp, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SafetyAlert{addressed, beacon}, alerts)
+
+	alerts, err = tv.pg.ListSafetyAlerts(context.Background(), timestamp, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SafetyAlert{addressed}, alerts)
+}
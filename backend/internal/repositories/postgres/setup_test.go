@@ -33,9 +33,11 @@ func setup(t *testing.T) *testVars {
 
 	t.Cleanup(func() {
 		// wipe database
-		_, err := pg.pool.Exec(context.Background(), "DELETE FROM ships")
-		if err != nil {
-			t.Fatal(err)
+		for _, table := range []string{"ships", "aids_to_navigation", "base_stations", "safety_alerts"} {
+			_, err := pg.pool.Exec(context.Background(), "DELETE FROM "+table)
+			if err != nil {
+				t.Fatal(err)
+			}
 		}
 
 		pg.pool.Close()
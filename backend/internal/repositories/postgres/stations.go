@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
+)
+
+const (
+	selectAidsToNavigationSQL = `
+			SELECT mmsi, name, aid_type, latitude, longitude, dimension_to_bow, dimension_to_stern, dimension_to_port,
+				dimension_to_starboard, virtual_aid, off_position, last_updated, received_at
+			FROM aids_to_navigation
+			WHERE latitude BETWEEN $1 AND $3 AND longitude BETWEEN $2 AND $4
+			ORDER BY mmsi`
+	updateAidToNavigationSQL = `
+			INSERT INTO aids_to_navigation (mmsi, name, aid_type, latitude, longitude, dimension_to_bow, dimension_to_stern,
+				dimension_to_port, dimension_to_starboard, virtual_aid, off_position, last_updated, received_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (mmsi)
+			DO
+				UPDATE SET name = COALESCE(NULLIF(EXCLUDED.name, ''), aids_to_navigation.name), aid_type = EXCLUDED.aid_type,
+					latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude, dimension_to_bow = EXCLUDED.dimension_to_bow,
+					dimension_to_stern = EXCLUDED.dimension_to_stern, dimension_to_port = EXCLUDED.dimension_to_port,
+					dimension_to_starboard = EXCLUDED.dimension_to_starboard, virtual_aid = EXCLUDED.virtual_aid,
+					off_position = EXCLUDED.off_position, last_updated = EXCLUDED.last_updated, received_at = EXCLUDED.received_at
+				WHERE aids_to_navigation.last_updated <= EXCLUDED.last_updated`
+	selectBaseStationsSQL = `
+			SELECT mmsi, latitude, longitude, last_updated, received_at
+			FROM base_stations
+			ORDER BY mmsi`
+	updateBaseStationSQL = `
+			INSERT INTO base_stations (mmsi, latitude, longitude, last_updated, received_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (mmsi)
+			DO
+				UPDATE SET latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude, last_updated = EXCLUDED.last_updated,
+					received_at = EXCLUDED.received_at
+				WHERE base_stations.last_updated <= EXCLUDED.last_updated`
+)
+
+func (pg *Postgres) ListAidsToNavigation(ctx context.Context, bbox domain.BoundingBox) ([]domain.AidToNavigation, error) {
+	defer pg.metrics.DBQueryTime("list_aids_to_navigation", time.Now())
+
+	rows, err := pg.pool.Query(ctx, selectAidsToNavigationSQL, bbox.MinLatitude, bbox.MinLongitude, bbox.MaxLatitude,
+		bbox.MaxLongitude)
+	if err != nil {
+		return nil, fmt.Errorf("error on querying aids to navigation: %w", storeErr(err))
+	}
+	aids, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.AidToNavigation, error) {
+		var aid domain.AidToNavigation
+		var name *string
+		var aidType, toBow, toStern, toPort, toStarboard *int32
+		var receivedAt *time.Time
+		err := row.Scan(&aid.MMSI, &name, &aidType, &aid.Latitude, &aid.Longitude, &toBow, &toStern, &toPort,
+			&toStarboard, &aid.VirtualAid, &aid.OffPosition, &aid.LastUpdated, &receivedAt)
+		aid.Name = valueOrZero(name)
+		aid.AidType = valueOrZero(aidType)
+		aid.DimensionToBow = valueOrZero(toBow)
+		aid.DimensionToStern = valueOrZero(toStern)
+		aid.DimensionToPort = valueOrZero(toPort)
+		aid.DimensionToStarboard = valueOrZero(toStarboard)
+		aid.LastUpdated = aid.LastUpdated.UTC()
+		if receivedAt != nil {
+			aid.ReceivedAt = receivedAt.UTC()
+		}
+		return aid, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on reading aids to navigation: %w", storeErr(err))
+	}
+	return aids, nil
+}
+
+func (pg *Postgres) StoreAidsToNavigation(ctx context.Context, aids []domain.AidToNavigation) error {
+	start := time.Now()
+	defer pg.metrics.DBQueryTime("store_aids_to_navigation", start)
+
+	for _, a := range aids {
+		_, err := pg.pool.Exec(ctx, updateAidToNavigationSQL, a.MMSI, a.Name, a.AidType, a.Latitude, a.Longitude,
+			a.DimensionToBow, a.DimensionToStern, a.DimensionToPort, a.DimensionToStarboard, a.VirtualAid, a.OffPosition,
+			a.LastUpdated, nullableTime(a.ReceivedAt))
+		if err != nil {
+			return fmt.Errorf("error on inserting aid to navigation with mmsi '%d': %w", a.MMSI, storeErr(err))
+		}
+	}
+
+	clog.Infof("Stored %d aids to navigation in Postgres in %d ms", len(aids), time.Since(start).Milliseconds())
+	return nil
+}
+
+func (pg *Postgres) ListBaseStations(ctx context.Context) ([]domain.BaseStation, error) {
+	defer pg.metrics.DBQueryTime("list_base_stations", time.Now())
+
+	rows, err := pg.pool.Query(ctx, selectBaseStationsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("error on querying base stations: %w", storeErr(err))
+	}
+	stations, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.BaseStation, error) {
+		var station domain.BaseStation
+		var receivedAt *time.Time
+		err := row.Scan(&station.MMSI, &station.Latitude, &station.Longitude, &station.LastUpdated, &receivedAt)
+		station.LastUpdated = station.LastUpdated.UTC()
+		if receivedAt != nil {
+			station.ReceivedAt = receivedAt.UTC()
+		}
+		return station, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on reading base stations: %w", storeErr(err))
+	}
+	return stations, nil
+}
+
+func (pg *Postgres) StoreBaseStations(ctx context.Context, stations []domain.BaseStation) error {
+	start := time.Now()
+	defer pg.metrics.DBQueryTime("store_base_stations", start)
+
+	for _, s := range stations {
+		_, err := pg.pool.Exec(ctx, updateBaseStationSQL, s.MMSI, s.Latitude, s.Longitude, s.LastUpdated,
+			nullableTime(s.ReceivedAt))
+		if err != nil {
+			return fmt.Errorf("error on inserting base station with mmsi '%d': %w", s.MMSI, storeErr(err))
+		}
+	}
+
+	clog.Infof("Stored %d base stations in Postgres in %d ms", len(stations), time.Since(start).Milliseconds())
+	return nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+)
+
+func TestStoreAidsToNavigation_ListsAidsWithinBoundingBox(t *testing.T) {
+	timestamp, _ := time.Parse(time.RFC3339, "2023-09-11T17:04:05Z")
+	inside := domain.AidToNavigation{
+		MMSI:                 992351000,
+		Name:                 "BRAMBLE BANK",
+		AidType:              20,
+		Latitude:             50.79,
+		Longitude:            -1.29,
+		DimensionToBow:       1,
+		DimensionToStern:     1,
+		DimensionToPort:      1,
+		DimensionToStarboard: 1,
+		VirtualAid:           true,
+		LastUpdated:          timestamp,
+		ReceivedAt:           timestamp.Add(2 * time.Second),
+	}
+	outside := domain.AidToNavigation{
+		MMSI:        992576000,
+		Name:        "BODO HAVN",
+		Latitude:    67.28,
+		Longitude:   14.38,
+		LastUpdated: timestamp,
+	}
+
+	tv := setup(t)
+	require.NoError(t, tv.pg.StoreAidsToNavigation(context.Background(), []domain.AidToNavigation{inside, outside}))
+
+	aids, err := tv.pg.ListAidsToNavigation(context.Background(),
+		domain.BoundingBox{MinLatitude: 50, MinLongitude: -2, MaxLatitude: 51, MaxLongitude: -1})
+	require.NoError(t, err)
+	require.Len(t, aids, 1)
+	assert.Equal(t, inside, aids[0])
+
+	// an update without a name keeps the existing name
+	inside.Name = ""
+	inside.OffPosition = true
+	require.NoError(t, tv.pg.StoreAidsToNavigation(context.Background(), []domain.AidToNavigation{inside}))
+	aids, err = tv.pg.ListAidsToNavigation(context.Background(),
+		domain.BoundingBox{MinLatitude: 50, MinLongitude: -2, MaxLatitude: 51, MaxLongitude: -1})
+	require.NoError(t, err)
+	require.Len(t, aids, 1)
+	assert.Equal(t, "BRAMBLE BANK", aids[0].Name)
+	assert.True(t, aids[0].OffPosition)
+
+	// an update observed before the stored one is ignored
+	older := inside
+	older.OffPosition = false
+	older.LastUpdated = timestamp.Add(-time.Minute)
+	require.NoError(t, tv.pg.StoreAidsToNavigation(context.Background(), []domain.AidToNavigation{older}))
+	aids, err = tv.pg.ListAidsToNavigation(context.Background(),
+		domain.BoundingBox{MinLatitude: 50, MinLongitude: -2, MaxLatitude: 51, MaxLongitude: -1})
+	require.NoError(t, err)
+	require.Len(t, aids, 1)
+	assert.True(t, aids[0].OffPosition)
+	assert.Equal(t, timestamp, aids[0].LastUpdated)
+}
+
+func TestStoreBaseStations(t *testing.T) {
+	timestamp, _ := time.Parse(time.RFC3339, "2023-09-11T17:04:05Z")
+	station := domain.BaseStation{
+		MMSI:        2320001,
+		Latitude:    50.8,
+		Longitude:   -1.3,
+		LastUpdated: timestamp,
+	}
+
+	tv := setup(t)
+	require.NoError(t, tv.pg.StoreBaseStations(context.Background(), []domain.BaseStation{station}))
+
+	station.LastUpdated = timestamp.Add(10 * time.Second)
+	station.ReceivedAt = timestamp.Add(11 * time.Second)
+	require.NoError(t, tv.pg.StoreBaseStations(context.Background(), []domain.BaseStation{station}))
+
+	// an update observed before the stored one is ignored
+	older := station
+	older.Latitude = 50.9
+	older.LastUpdated = timestamp
+	require.NoError(t, tv.pg.StoreBaseStations(context.Background(), []domain.BaseStation{older}))
+
+	stations, err := tv.pg.ListBaseStations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stations, 1)
+	assert.Equal(t, station, stations[0])
+}
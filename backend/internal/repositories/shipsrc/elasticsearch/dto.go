@@ -6,15 +6,30 @@ import (
 )
 
 type shipDTO struct {
-	MMSI int32  `json:"mmsi"`
-	Name string `json:"name"`
+	MMSI        int32  `json:"mmsi"`
+	Name        string `json:"name"`
+	Flag        string `json:"flag,omitempty"`
+	StationType string `json:"stationType,omitempty"`
 }
 
 func toShipDTO(s domain.ShipSearchResult) shipDTO {
-	return shipDTO{
+	dto := shipDTO{
 		MMSI: s.MMSI,
 		Name: s.Name,
 	}
+	// the flag and station type are indexed so that searches can be filtered on them
+	if info, err := domain.ClassifyMMSI(s.MMSI); err == nil {
+		dto.Flag = info.Flag
+		dto.StationType = string(info.StationType)
+	}
+	if s.StationType != "" {
+		dto.StationType = string(s.StationType)
+	}
+	return dto
+}
+
+func (s *shipDTO) toDomainEntity() domain.ShipSearchResult {
+	return domain.NewShipSearchResult(s.MMSI, s.Name)
 }
 
 func (s *shipDTO) toJSON() ([]byte, error) {
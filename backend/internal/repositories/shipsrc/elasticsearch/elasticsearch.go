@@ -5,13 +5,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/clearscroll"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/scroll"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/core/search"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/core/update"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/create"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/putmapping"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
 	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
 	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
 	"github.com/mikeewhite/ship-locator/backend/pkg/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/mikeewhite/ship-locator/elasticsearch"
+
+const (
+	// backfillBatchSize is the number of entries re-indexed at a time when backfilling the index
+	backfillBatchSize = 1000
+	// backfillScrollTimeout is how long the search context is kept between batches when backfilling the index
+	backfillScrollTimeout = "1m"
 )
 
 type Repository struct {
@@ -39,32 +55,15 @@ func New(ctx context.Context, cfg config.Config) (*Repository, error) {
 	if err = repo.createIndexes(ctx); err != nil {
 		return nil, fmt.Errorf("failed to create indexes: %w", err)
 	}
-
-	exists, err := client.Indices.Exists(repo.indexName).Do(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check if %s index exists: %w", repo.indexName, err)
-	}
-	if !exists {
-		_, err := client.Indices.Create(repo.indexName).
-			Request(&create.Request{
-				Mappings: &types.TypeMapping{
-					Properties: map[string]types.Property{
-						"name": types.NewTextProperty(),
-						"mmsi": types.NewTextProperty(),
-					},
-				},
-			}).
-			Do(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create %s index: %w", repo.indexName, err)
-		}
+	if err = repo.backfill(ctx); err != nil {
+		return nil, fmt.Errorf("failed to backfill %s index: %w", repo.indexName, err)
 	}
 
 	return repo, nil
 }
 
 // TODO - Add metrics, logging, and tracing
-func (r *Repository) Search(ctx context.Context, nameOrMMSI string) ([]domain.ShipSearchResult, error) {
+func (r *Repository) Search(ctx context.Context, nameOrMMSI string, filter domain.ShipSearchFilter) ([]domain.ShipSearchResult, error) {
 	var results []domain.ShipSearchResult
 
 	// create a match_phrase_prefix query to support matching before user has typed the full name
@@ -95,6 +94,26 @@ func (r *Repository) Search(ctx context.Context, nameOrMMSI string) ([]domain.Sh
 		},
 	}
 
+	// the filters must all match but do not affect the score
+	var filters []types.Query
+	if filter.Flag != "" {
+		filters = append(filters, types.Query{
+			Match: map[string]types.MatchQuery{"flag": {Query: filter.Flag}},
+		})
+	}
+	if filter.StationType != "" {
+		filters = append(filters, types.Query{
+			Match: map[string]types.MatchQuery{"stationType": {Query: string(filter.StationType)}},
+		})
+	}
+
+	var mustNot []types.Query
+	for _, stationType := range filter.ExcludedStationTypes() {
+		mustNot = append(mustNot, types.Query{
+			Match: map[string]types.MatchQuery{"stationType": {Query: string(stationType)}},
+		})
+	}
+
 	resp, err := r.client.Search().
 		Index(r.indexName).
 		Request(&search.Request{
@@ -107,31 +126,51 @@ func (r *Repository) Search(ctx context.Context, nameOrMMSI string) ([]domain.Sh
 						fuzzyNameQuery,
 						fuzzyMMSIQuery,
 					},
+					// without this the should clauses become optional once there are filters
+					MinimumShouldMatch: 1,
+					Filter:             filters,
+					MustNot:            mustNot,
 				},
 			},
 		}).Do(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search for ships: %w", err)
+		return nil, fmt.Errorf("failed to search for ships: %w", storeErr(err))
 	}
 
 	if resp.Hits.Total != nil && resp.Hits.Total.Value > 0 {
 		for _, hit := range resp.Hits.Hits {
-			var ssr domain.ShipSearchResult
+			var dto shipDTO
 
 			source_ := hit.Source_
-			err := json.Unmarshal(source_, &ssr)
+			err := json.Unmarshal(source_, &dto)
 			if err != nil {
 				return nil, fmt.Errorf("failed to unmarshal ship search result: %w", err)
 			}
 			clog.Infof("source: %+v", string(source_))
-			results = append(results, ssr)
+			results = append(results, dto.toDomainEntity())
 		}
 	}
 
 	return results, nil
 }
 
-func (r *Repository) Index(ctx context.Context, ships []domain.ShipSearchResult) error {
+func (r *Repository) Index(ctx context.Context, ships []domain.ShipSearchResult) (err error) {
+	// See https://opentelemetry.io/docs/specs/otel/trace/semantic_conventions/database/
+	ctx, span := otel.Tracer(tracerName).Start(ctx, fmt.Sprintf("update %s", r.indexName),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemElasticsearch,
+			semconv.DBOperation("update"),
+			semconv.DBName(r.indexName),
+		))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	// TODO - bulk index ships
 	upsert := true
 	for _, ship := range ships {
@@ -147,7 +186,7 @@ func (r *Repository) Index(ctx context.Context, ships []domain.ShipSearchResult)
 			Doc:         query,
 		}).Do(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to index ship data: %w", err)
+			return fmt.Errorf("failed to index ship data: %w", storeErr(err))
 		}
 	}
 	return nil
@@ -168,8 +207,10 @@ func (r *Repository) createIndexes(ctx context.Context) error {
 			Request(&create.Request{
 				Mappings: &types.TypeMapping{
 					Properties: map[string]types.Property{
-						"name": types.NewTextProperty(),
-						"mmsi": types.NewTextProperty(),
+						"name":        types.NewTextProperty(),
+						"mmsi":        types.NewTextProperty(),
+						"flag":        types.NewKeywordProperty(),
+						"stationType": types.NewKeywordProperty(),
 					},
 				},
 			}).
@@ -177,6 +218,82 @@ func (r *Repository) createIndexes(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("failed to create %s index: %w", r.indexName, err)
 		}
+		return nil
+	}
+
+	// indices created before the flag and station type were added don't have their mappings, without which they'd
+	// be mapped dynamically as text on first use (a field's mapping can't be changed once set, so an index where this
+	// has already happened must be deleted and rebuilt)
+	_, err = r.client.Indices.PutMapping(r.indexName).
+		Request(&putmapping.Request{
+			Properties: map[string]types.Property{
+				"flag":        types.NewKeywordProperty(),
+				"stationType": types.NewKeywordProperty(),
+			},
+		}).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update %s index mappings: %w", r.indexName, err)
+	}
+	return nil
+}
+
+// backfill re-indexes the entries indexed before the flag and station type were added, so that filtered searches
+// match them without waiting for their next report. Entries whose MMSI can't be classified are left as they are.
+func (r *Repository) backfill(ctx context.Context) error {
+	resp, err := r.client.Search().
+		Index(r.indexName).
+		Scroll(backfillScrollTimeout).
+		Size(backfillBatchSize).
+		Request(&search.Request{
+			Query: &types.Query{
+				Bool: &types.BoolQuery{
+					MustNot: []types.Query{{Exists: &types.ExistsQuery{Field: "stationType"}}},
+				},
+			},
+		}).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to search for entries to backfill: %w", storeErr(err))
+	}
+	hits, scrollID := resp.Hits.Hits, resp.ScrollId_
+	defer func() {
+		if scrollID != nil {
+			_, _ = r.client.ClearScroll().
+				Request(&clearscroll.Request{ScrollId: []string{*scrollID}}).
+				Do(context.Background())
+		}
+	}()
+
+	backfilled := 0
+	for len(hits) > 0 {
+		ships := make([]domain.ShipSearchResult, len(hits))
+		for i, hit := range hits {
+			var dto shipDTO
+			if err := json.Unmarshal(hit.Source_, &dto); err != nil {
+				return fmt.Errorf("failed to unmarshal entry to backfill: %w", err)
+			}
+			ships[i] = dto.toDomainEntity()
+		}
+		if err := r.Index(ctx, ships); err != nil {
+			return err
+		}
+		backfilled += len(ships)
+
+		if scrollID == nil {
+			break
+		}
+		next, err := r.client.Scroll().
+			Request(&scroll.Request{Scroll: backfillScrollTimeout, ScrollId: *scrollID}).
+			Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to scroll through entries to backfill: %w", storeErr(err))
+		}
+		hits, scrollID = next.Hits.Hits, next.ScrollId_
+	}
+	if backfilled > 0 {
+		clog.Infow("backfilled flags and station types",
+			"index", r.indexName,
+			"entries", backfilled)
 	}
 	return nil
 }
@@ -2,7 +2,10 @@ package elasticsearch
 
 import (
 	"context"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/create"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
 	"github.com/mikeewhite/ship-locator/backend/internal/core/domain"
+	"github.com/mikeewhite/ship-locator/backend/pkg/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -11,7 +14,7 @@ import (
 
 func TestSearch_NoMatchingResults(t *testing.T) {
 	tv := setup(t)
-	matches, err := tv.elasticsearch.Search(context.Background(), "AUGUSTSON")
+	matches, err := tv.elasticsearch.Search(context.Background(), "AUGUSTSON", domain.ShipSearchFilter{})
 	require.NoError(t, err)
 	assert.Empty(t, matches)
 }
@@ -39,7 +42,7 @@ func TestSearch_MatchesOnNameAndMMSI(t *testing.T) {
 
 	for name, tc := range tt {
 		t.Run(name, func(t *testing.T) {
-			matches, err := tv.elasticsearch.Search(context.Background(), tc.query)
+			matches, err := tv.elasticsearch.Search(context.Background(), tc.query, domain.ShipSearchFilter{})
 			require.NoError(t, err)
 			require.Len(t, matches, 1)
 			assert.Equal(t, ship, matches[0])
@@ -64,8 +67,97 @@ func TestSearch_ReIndexesEntriesWithTheSameMMSI(t *testing.T) {
 	// allow time for indexing
 	time.Sleep(1 * time.Second)
 
-	matches, err := tv.elasticsearch.Search(context.Background(), "259000420")
+	matches, err := tv.elasticsearch.Search(context.Background(), "259000420", domain.ShipSearchFilter{})
 	require.NoError(t, err)
 	require.Len(t, matches, 1)
 	assert.Equal(t, "AUGUSTSEN", ship.Name)
 }
+
+func TestSearch_FiltersOnFlagAndStationType(t *testing.T) {
+	tv := setup(t)
+
+	// a Norwegian ship and a British aid to navigation with similar names
+	ship := domain.NewShipSearchResult(259000420, "BRAMBLE")
+	aid := domain.NewShipSearchResult(992351000, "BRAMBLE BANK")
+	require.NoError(t, tv.elasticsearch.Index(context.Background(), []domain.ShipSearchResult{ship, aid}))
+	// allow time for indexing
+	time.Sleep(1 * time.Second)
+
+	matches, err := tv.elasticsearch.Search(context.Background(), "BRAMBLE",
+		domain.ShipSearchFilter{Flag: "GB", StationType: domain.StationTypeAidToNavigation})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, aid, matches[0])
+
+	matches, err = tv.elasticsearch.Search(context.Background(), "BRAMBLE",
+		domain.ShipSearchFilter{StationType: domain.StationTypeShip})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, ship, matches[0])
+}
+
+func TestSearch_ExcludesAidsToNavigationAndCoastStationsByDefault(t *testing.T) {
+	tv := setup(t)
+
+	ship := domain.NewShipSearchResult(259000420, "BRAMBLE")
+	// stations are indexed with their station type regardless of their MMSI
+	aid := domain.ShipSearchResult{MMSI: 235000001, Name: "BRAMBLE BANK", StationType: domain.StationTypeAidToNavigation}
+	station := domain.ShipSearchResult{MMSI: 2320001, StationType: domain.StationTypeCoastStation}
+	require.NoError(t, tv.elasticsearch.Index(context.Background(), []domain.ShipSearchResult{ship, aid, station}))
+	// allow time for indexing
+	time.Sleep(1 * time.Second)
+
+	matches, err := tv.elasticsearch.Search(context.Background(), "BRAMBLE", domain.ShipSearchFilter{})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, ship, matches[0])
+	matches, err = tv.elasticsearch.Search(context.Background(), "2320001", domain.ShipSearchFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	matches, err = tv.elasticsearch.Search(context.Background(), "BRAMBLE",
+		domain.ShipSearchFilter{StationType: domain.StationTypeAidToNavigation})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, int32(235000001), matches[0].MMSI)
+}
+
+func TestNew_BackfillsEntriesIndexedBeforeFlagsWereAdded(t *testing.T) {
+	tv := setup(t)
+	client, indexName := tv.elasticsearch.client, tv.elasticsearch.indexName
+
+	// recreate the index as it was before the flag and station type were added
+	_, err := client.Indices.Delete(indexName).Do(context.Background())
+	require.NoError(t, err)
+	_, err = client.Indices.Create(indexName).
+		Request(&create.Request{
+			Mappings: &types.TypeMapping{
+				Properties: map[string]types.Property{
+					"name": types.NewTextProperty(),
+					"mmsi": types.NewTextProperty(),
+				},
+			},
+		}).
+		Do(context.Background())
+	require.NoError(t, err)
+	ship := domain.NewShipSearchResult(259000420, "BRAMBLE")
+	_, err = client.Index(indexName).Id("259000420").Request(shipDTO{MMSI: ship.MMSI, Name: ship.Name}).
+		Do(context.Background())
+	require.NoError(t, err)
+	// allow time for indexing
+	time.Sleep(1 * time.Second)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.ElasticsearchIndex = indexName
+	repo, err := New(context.Background(), *cfg)
+	require.NoError(t, err)
+	// allow time for indexing
+	time.Sleep(1 * time.Second)
+
+	matches, err := repo.Search(context.Background(), "BRAMBLE",
+		domain.ShipSearchFilter{Flag: "NO", StationType: domain.StationTypeShip})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, ship, matches[0])
+}
@@ -0,0 +1,28 @@
+package elasticsearch
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+
+	"github.com/mikeewhite/ship-locator/backend/pkg/apperrors"
+)
+
+// storeErr marks errors caused by Elasticsearch being unreachable or overloaded as the store being unavailable,
+// leaving any other errors as they are
+func storeErr(err error) error {
+	var esErr *types.ElasticsearchError
+	if errors.As(err, &esErr) {
+		if esErr.Status >= http.StatusInternalServerError || esErr.Status == http.StatusTooManyRequests {
+			return apperrors.NewStoreUnavailableErr(err)
+		}
+		return err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return apperrors.NewStoreUnavailableErr(err)
+	}
+	return err
+}
@@ -1,4 +1,7 @@
-CREATE TABLE "ships" (
+-- Every statement can be re-run, so that this script also upgrades an existing database. Columns added after a table
+-- was first created are added with ALTER TABLE rather than in its CREATE TABLE.
+
+CREATE TABLE IF NOT EXISTS "ships" (
      "id" bigserial PRIMARY KEY,
      "mmsi" bigint NOT NULL UNIQUE,
      "name" varchar,
@@ -7,8 +10,138 @@ CREATE TABLE "ships" (
      "last_updated" timestamptz NOT NULL DEFAULT (now())
 );
 
-CREATE INDEX ON "ships" ("mmsi");
+CREATE INDEX IF NOT EXISTS "ships_mmsi_idx" ON "ships" ("mmsi");
+
+CREATE INDEX IF NOT EXISTS "ships_name_idx" ON "ships" ("name");
+
+-- static data
+ALTER TABLE "ships"
+    ALTER COLUMN "latitude" DROP NOT NULL,
+    ALTER COLUMN "longitude" DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS "imo_number" integer,
+    ADD COLUMN IF NOT EXISTS "call_sign" varchar,
+    ADD COLUMN IF NOT EXISTS "ship_type" integer,
+    ADD COLUMN IF NOT EXISTS "dimension_to_bow" integer,
+    ADD COLUMN IF NOT EXISTS "dimension_to_stern" integer,
+    ADD COLUMN IF NOT EXISTS "dimension_to_port" integer,
+    ADD COLUMN IF NOT EXISTS "dimension_to_starboard" integer,
+    ADD COLUMN IF NOT EXISTS "draught" double precision,
+    ADD COLUMN IF NOT EXISTS "destination" varchar,
+    ADD COLUMN IF NOT EXISTS "eta" timestamptz,
+    ADD COLUMN IF NOT EXISTS "static_last_updated" timestamptz;
+
+-- kinematics
+ALTER TABLE "ships"
+    ADD COLUMN IF NOT EXISTS "speed_over_ground" double precision,
+    ADD COLUMN IF NOT EXISTS "course_over_ground" double precision,
+    ADD COLUMN IF NOT EXISTS "true_heading" integer,
+    ADD COLUMN IF NOT EXISTS "navigational_status" integer,
+    ADD COLUMN IF NOT EXISTS "rate_of_turn" double precision,
+    ADD COLUMN IF NOT EXISTS "rate_of_turn_no_turn_indicator" boolean NOT NULL DEFAULT false;
 
-CREATE INDEX ON "ships" ("name");
+-- time the report was received
+ALTER TABLE "ships"
+    ADD COLUMN IF NOT EXISTS "received_at" timestamptz;
+
+-- transponder class
+ALTER TABLE "ships"
+    ADD COLUMN IF NOT EXISTS "transponder_class" text;
+
+-- plausibility
+ALTER TABLE "ships"
+    ADD COLUMN IF NOT EXISTS "implausible_reason" text;
 
 COMMENT ON COLUMN "ships"."name" IS 'may be empty';
+
+COMMENT ON COLUMN "ships"."latitude" IS 'null if only static data has been received for the ship';
+
+COMMENT ON COLUMN "ships"."longitude" IS 'null if only static data has been received for the ship';
+
+COMMENT ON COLUMN "ships"."last_updated" IS 'time the position was observed by the transponder';
+
+COMMENT ON COLUMN "ships"."received_at" IS 'time the position report was received by the collector';
+
+COMMENT ON COLUMN "ships"."transponder_class" IS 'class of AIS transponder (A or B) that reported the position';
+
+COMMENT ON COLUMN "ships"."implausible_reason" IS 'reason the collector flagged the position as implausible (null if plausible)';
+
+COMMENT ON COLUMN "ships"."speed_over_ground" IS 'knots (null if not available)';
+
+COMMENT ON COLUMN "ships"."course_over_ground" IS 'degrees (null if not available)';
+
+COMMENT ON COLUMN "ships"."true_heading" IS 'degrees (null if not available)';
+
+COMMENT ON COLUMN "ships"."rate_of_turn" IS 'degrees per minute (null if not available)';
+
+COMMENT ON COLUMN "ships"."rate_of_turn_no_turn_indicator" IS 'true if rate_of_turn only holds the rate (10 degrees per minute) that a ship without a turn indicator is known to exceed';
+
+COMMENT ON COLUMN "ships"."static_last_updated" IS 'null if no static data has been received for the ship';
+
+CREATE TABLE IF NOT EXISTS "aids_to_navigation" (
+     "id" bigserial PRIMARY KEY,
+     "mmsi" bigint NOT NULL UNIQUE,
+     "name" varchar,
+     "aid_type" integer,
+     "latitude" double precision NOT NULL,
+     "longitude" double precision NOT NULL,
+     "dimension_to_bow" integer,
+     "dimension_to_stern" integer,
+     "dimension_to_port" integer,
+     "dimension_to_starboard" integer,
+     "virtual_aid" boolean NOT NULL DEFAULT false,
+     "off_position" boolean NOT NULL DEFAULT false,
+     "last_updated" timestamptz NOT NULL DEFAULT (now()),
+     "received_at" timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS "aids_to_navigation_latitude_longitude_idx" ON "aids_to_navigation" ("latitude", "longitude");
+
+COMMENT ON COLUMN "aids_to_navigation"."aid_type" IS 'AIS aid to navigation type code (0 if not specified)';
+
+COMMENT ON COLUMN "aids_to_navigation"."virtual_aid" IS 'true if the aid is broadcast by a shore station but does not physically exist';
+
+COMMENT ON COLUMN "aids_to_navigation"."off_position" IS 'true if a floating aid has drifted from its assigned position';
+
+COMMENT ON COLUMN "aids_to_navigation"."last_updated" IS 'time the position was observed by the transponder';
+
+COMMENT ON COLUMN "aids_to_navigation"."received_at" IS 'time the report was received by the collector';
+
+CREATE TABLE IF NOT EXISTS "base_stations" (
+     "id" bigserial PRIMARY KEY,
+     "mmsi" bigint NOT NULL UNIQUE,
+     "latitude" double precision NOT NULL,
+     "longitude" double precision NOT NULL,
+     "last_updated" timestamptz NOT NULL DEFAULT (now()),
+     "received_at" timestamptz
+);
+
+COMMENT ON COLUMN "base_stations"."last_updated" IS 'time the report was made according to the base station clock';
+
+COMMENT ON COLUMN "base_stations"."received_at" IS 'time the report was received by the collector';
+
+CREATE TABLE IF NOT EXISTS "safety_alerts" (
+     "id" bigserial PRIMARY KEY,
+     "mmsi" bigint NOT NULL,
+     "kind" text NOT NULL,
+     "destination_mmsi" bigint NOT NULL DEFAULT 0,
+     "text" varchar,
+     "latitude" double precision,
+     "longitude" double precision,
+     "transmitted_at" timestamptz NOT NULL,
+     "received_at" timestamptz,
+     UNIQUE ("mmsi", "kind", "destination_mmsi", "transmitted_at")
+);
+
+CREATE INDEX IF NOT EXISTS "safety_alerts_transmitted_at_idx" ON "safety_alerts" ("transmitted_at");
+
+COMMENT ON COLUMN "safety_alerts"."kind" IS 'broadcast (message type 14), addressed (message type 12) or beacon (AIS-SART, MOB or EPIRB-AIS position report)';
+
+COMMENT ON COLUMN "safety_alerts"."destination_mmsi" IS '0 unless the alert was addressed to a station';
+
+COMMENT ON COLUMN "safety_alerts"."latitude" IS 'null unless the alert was raised by a beacon';
+
+COMMENT ON COLUMN "safety_alerts"."longitude" IS 'null unless the alert was raised by a beacon';
+
+COMMENT ON COLUMN "safety_alerts"."transmitted_at" IS 'time the alert was transmitted (or received by the AIS receiver for safety messages)';
+
+COMMENT ON COLUMN "safety_alerts"."received_at" IS 'time the alert was received by the collector';
@@ -0,0 +1,70 @@
+package aivdm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bits holds a de-armoured payload with one element per bit
+type bits []byte
+
+// unarmour converts a 6-bit ASCII armoured payload into its bits, discarding the trailing fill bits
+func unarmour(payload string, fillBits int) (bits, error) {
+	b := make(bits, 0, len(payload)*6)
+	for i := 0; i < len(payload); i++ {
+		c := payload[i]
+		if c < '0' || c > 'w' || (c > 'W' && c < '`') {
+			return nil, fmt.Errorf("%w: invalid payload character '%c'", ErrMalformedSentence, c)
+		}
+		v := c - '0'
+		if v > 40 {
+			v -= 8
+		}
+		for shift := 5; shift >= 0; shift-- {
+			b = append(b, (v>>shift)&1)
+		}
+	}
+	if fillBits > len(b) {
+		return nil, fmt.Errorf("%w: fill bits exceed payload length", ErrMalformedSentence)
+	}
+	return b[:len(b)-fillBits], nil
+}
+
+// uint reads an unsigned integer. Bits beyond the end of the payload are read as zero.
+func (b bits) uint(start, length int) uint32 {
+	var v uint32
+	for i := start; i < start+length; i++ {
+		v <<= 1
+		if i < len(b) {
+			v |= uint32(b[i])
+		}
+	}
+	return v
+}
+
+// int reads a two's complement signed integer
+func (b bits) int(start, length int) int32 {
+	v := b.uint(start, length)
+	if length > 0 && v&(1<<(length-1)) != 0 {
+		return int32(v) - int32(1<<length)
+	}
+	return int32(v)
+}
+
+func (b bits) bool(start int) bool {
+	return b.uint(start, 1) == 1
+}
+
+// text reads a string of 6-bit ASCII characters, dropping the trailing '@' and space padding used for unset
+// characters
+func (b bits) text(start, length int) string {
+	var sb strings.Builder
+	for i := start; i+6 <= start+length && i < len(b); i += 6 {
+		c := byte(b.uint(i, 6))
+		if c < 32 {
+			c += 64
+		}
+		sb.WriteByte(c)
+	}
+	return strings.TrimRight(sb.String(), "@ ")
+}
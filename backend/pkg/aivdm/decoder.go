@@ -0,0 +1,82 @@
+package aivdm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// fragmentTimeout is how long the fragments of a partially received multi-sentence message are kept for
+const fragmentTimeout = time.Minute
+
+type fragmentBuffer struct {
+	payload  strings.Builder
+	next     int
+	received time.Time
+}
+
+// Decoder decodes AIVDM/AIVDO sentences into AIS messages, reassembling messages that are split across multiple
+// sentences. A Decoder is not safe for concurrent use and should be used with a single stream of sentences.
+type Decoder struct {
+	fragments map[string]*fragmentBuffer
+	now       func() time.Time
+}
+
+func NewDecoder() *Decoder {
+	return &Decoder{
+		fragments: make(map[string]*fragmentBuffer),
+		now:       time.Now,
+	}
+}
+
+// Decode parses the given sentence and returns the decoded message. A nil message and nil error are returned when
+// the sentence is a fragment of a message that is still waiting on further fragments.
+func (d *Decoder) Decode(line string) (Message, error) {
+	s, err := ParseSentence(line)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.FragmentCount == 1 {
+		return decodePayload(s.Payload, s.FillBits)
+	}
+
+	d.evictStaleFragments()
+	key := fmt.Sprintf("%s/%s/%d", s.Channel, s.SequentialID, s.FragmentCount)
+	buf, ok := d.fragments[key]
+	if s.FragmentNumber == 1 {
+		// a first fragment always starts a new message, discarding any incomplete message with the same key
+		buf = &fragmentBuffer{next: 1, received: d.now()}
+		d.fragments[key] = buf
+	} else if !ok || buf.next != s.FragmentNumber {
+		delete(d.fragments, key)
+		return nil, fmt.Errorf("%w: fragment %d of %d with sequential id '%s'",
+			ErrOutOfSequenceFragment, s.FragmentNumber, s.FragmentCount, s.SequentialID)
+	}
+
+	buf.payload.WriteString(s.Payload)
+	buf.next++
+	if s.FragmentNumber < s.FragmentCount {
+		return nil, nil
+	}
+
+	delete(d.fragments, key)
+	return decodePayload(buf.payload.String(), s.FillBits)
+}
+
+func (d *Decoder) evictStaleFragments() {
+	cutoff := d.now().Add(-fragmentTimeout)
+	for key, buf := range d.fragments {
+		if buf.received.Before(cutoff) {
+			delete(d.fragments, key)
+		}
+	}
+}
+
+func decodePayload(payload string, fillBits int) (Message, error) {
+	b, err := unarmour(payload, fillBits)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMessage(b)
+}
@@ -0,0 +1,307 @@
+package aivdm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// payloadBuilder builds armoured payloads so that each message type can be tested field by field
+type payloadBuilder struct {
+	bits []byte
+}
+
+func (pb *payloadBuilder) uint(v uint32, length int) *payloadBuilder {
+	for shift := length - 1; shift >= 0; shift-- {
+		pb.bits = append(pb.bits, byte(v>>shift)&1)
+	}
+	return pb
+}
+
+func (pb *payloadBuilder) int(v int32, length int) *payloadBuilder {
+	return pb.uint(uint32(v)&(1<<length-1), length)
+}
+
+func (pb *payloadBuilder) text(s string, length int) *payloadBuilder {
+	for i := 0; i < length/6; i++ {
+		c := byte('@')
+		if i < len(s) {
+			c = s[i]
+		}
+		if c >= 64 {
+			c -= 64
+		}
+		pb.uint(uint32(c), 6)
+	}
+	return pb
+}
+
+func (pb *payloadBuilder) sentence() string {
+	fillBits := (6 - len(pb.bits)%6) % 6
+	padded := append(append([]byte{}, pb.bits...), make([]byte, fillBits)...)
+	payload := make([]byte, 0, len(padded)/6)
+	for i := 0; i < len(padded); i += 6 {
+		var v byte
+		for _, bit := range padded[i : i+6] {
+			v = v<<1 | bit
+		}
+		if v >= 40 {
+			v += 8
+		}
+		payload = append(payload, v+'0')
+	}
+	body := fmt.Sprintf("AIVDM,1,1,,A,%s,%d", payload, fillBits)
+	return fmt.Sprintf("!%s*%02X", body, checksum(body))
+}
+
+func header(messageType, mmsi uint32) *payloadBuilder {
+	pb := &payloadBuilder{}
+	return pb.uint(messageType, 6).uint(0, 2).uint(mmsi, 30)
+}
+
+func TestDecode_PositionReport(t *testing.T) {
+	msg, err := NewDecoder().Decode("!AIVDM,1,1,,B,177KQJ5000G?tO`K>RA1wUbN0TKH,0*5C")
+	require.NoError(t, err)
+
+	report, ok := msg.(PositionReport)
+	require.True(t, ok)
+	assert.Equal(t, Header{MessageType: 1, MMSI: 477553000}, report.MessageHeader())
+	assert.Equal(t, int32(5), report.NavigationalStatus)
+	assert.Equal(t, int32(0), report.RateOfTurn)
+	assert.Equal(t, 0.0, report.SpeedOverGround)
+	assert.InDelta(t, -122.345833, report.Longitude, 0.000001)
+	assert.InDelta(t, 47.582833, report.Latitude, 0.000001)
+	assert.Equal(t, 51.0, report.CourseOverGround)
+	assert.Equal(t, int32(181), report.TrueHeading)
+	assert.Equal(t, int32(15), report.Timestamp)
+}
+
+func TestDecode_BaseStationReport(t *testing.T) {
+	pb := header(4, 2320001).uint(2023, 14).uint(9, 4).uint(11, 5).uint(17, 5).uint(4, 6).uint(5, 6).uint(1, 1).
+		int(-780000, 28).int(30480000, 27).uint(7, 4).uint(0, 10).uint(0, 1).uint(0, 19)
+
+	msg, err := NewDecoder().Decode(pb.sentence())
+	require.NoError(t, err)
+	assert.Equal(t, BaseStationReport{
+		Header:           Header{MessageType: 4, MMSI: 2320001},
+		Year:             2023,
+		Month:            9,
+		Day:              11,
+		Hour:             17,
+		Minute:           4,
+		Second:           5,
+		PositionAccuracy: true,
+		Longitude:        -1.3,
+		Latitude:         50.8,
+	}, msg)
+}
+
+func TestDecode_ReassemblesMultiSentenceStaticVoyageData(t *testing.T) {
+	decoder := NewDecoder()
+	msg, err := decoder.Decode("!AIVDM,2,1,1,A,55?MbV02;H;s<HtKR20EHE:0@T4@Dn2222222216L961O5Gf0NSQEp6ClRp8,0*1C")
+	require.NoError(t, err)
+	assert.Nil(t, msg)
+
+	msg, err = decoder.Decode("!AIVDM,2,2,1,A,88888888880,2*25")
+	require.NoError(t, err)
+
+	data, ok := msg.(StaticVoyageData)
+	require.True(t, ok)
+	assert.Equal(t, StaticVoyageData{
+		Header:               Header{MessageType: 5, MMSI: 351759000},
+		IMONumber:            9134270,
+		CallSign:             "3FOF8",
+		Name:                 "EVER DIADEM",
+		ShipType:             70,
+		DimensionToBow:       225,
+		DimensionToStern:     70,
+		DimensionToPort:      1,
+		DimensionToStarboard: 31,
+		ETAMonth:             5,
+		ETADay:               15,
+		ETAHour:              14,
+		ETAMinute:            0,
+		Draught:              12.2,
+		Destination:          "NEW YORK",
+	}, data)
+}
+
+func TestDecode_OutOfSequenceFragment(t *testing.T) {
+	_, err := NewDecoder().Decode("!AIVDM,2,2,1,A,88888888880,2*25")
+	assert.ErrorIs(t, err, ErrOutOfSequenceFragment)
+}
+
+func TestDecode_DiscardsStaleFragments(t *testing.T) {
+	now := time.Now()
+	decoder := NewDecoder()
+	decoder.now = func() time.Time { return now }
+	_, err := decoder.Decode("!AIVDM,2,1,1,A,55?MbV02;H;s<HtKR20EHE:0@T4@Dn2222222216L961O5Gf0NSQEp6ClRp8,0*1C")
+	require.NoError(t, err)
+
+	now = now.Add(2 * fragmentTimeout)
+	_, err = decoder.Decode("!AIVDM,2,2,1,A,88888888880,2*25")
+	assert.ErrorIs(t, err, ErrOutOfSequenceFragment)
+}
+
+func TestDecode_IgnoresTagBlock(t *testing.T) {
+	msg, err := NewDecoder().Decode(`\s:2573315,c:1672338151*0A\!AIVDM,1,1,,B,177KQJ5000G?tO` + "`" + `K>RA1wUbN0TKH,0*5C`)
+	require.NoError(t, err)
+	assert.Equal(t, int32(477553000), msg.MessageHeader().MMSI)
+}
+
+func TestDecode_StandardClassBPositionReport(t *testing.T) {
+	pb := header(18, 367430530).uint(0, 8).uint(123, 10).uint(1, 1).
+		int(-42625000, 28).int(25410000, 27).uint(2345, 12).uint(511, 9).uint(49, 6).uint(0, 29)
+
+	msg, err := NewDecoder().Decode(pb.sentence())
+	require.NoError(t, err)
+	assert.Equal(t, StandardClassBPositionReport{
+		Header:           Header{MessageType: 18, MMSI: 367430530},
+		SpeedOverGround:  12.3,
+		PositionAccuracy: true,
+		Longitude:        -71.041666666666667,
+		Latitude:         42.35,
+		CourseOverGround: 234.5,
+		TrueHeading:      511,
+		Timestamp:        49,
+	}, msg)
+}
+
+func TestDecode_ExtendedClassBPositionReport(t *testing.T) {
+	pb := header(19, 367430530).uint(0, 8).uint(55, 10).uint(0, 1).
+		int(-42625000, 28).int(25410000, 27).uint(900, 12).uint(90, 9).uint(12, 6).uint(0, 4).
+		text("SEA BREEZE", 120).uint(37, 8).uint(10, 9).uint(5, 9).uint(2, 6).uint(2, 6).uint(1, 4).uint(0, 11)
+
+	msg, err := NewDecoder().Decode(pb.sentence())
+	require.NoError(t, err)
+	assert.Equal(t, ExtendedClassBPositionReport{
+		Header:               Header{MessageType: 19, MMSI: 367430530},
+		SpeedOverGround:      5.5,
+		Longitude:            -71.041666666666667,
+		Latitude:             42.35,
+		CourseOverGround:     90,
+		TrueHeading:          90,
+		Timestamp:            12,
+		Name:                 "SEA BREEZE",
+		ShipType:             37,
+		DimensionToBow:       10,
+		DimensionToStern:     5,
+		DimensionToPort:      2,
+		DimensionToStarboard: 2,
+	}, msg)
+}
+
+func TestDecode_AidToNavigationReport(t *testing.T) {
+	pb := header(21, 992351000).uint(14, 5).text("BRAMBLE BANK BUOY", 120).uint(1, 1).
+		int(-780000, 28).int(30480000, 27).uint(1, 9).uint(1, 9).uint(1, 6).uint(1, 6).uint(7, 4).uint(61, 6).
+		uint(1, 1).uint(0, 8).uint(0, 1).uint(1, 1).uint(0, 1).uint(0, 1)
+
+	msg, err := NewDecoder().Decode(pb.sentence())
+	require.NoError(t, err)
+	assert.Equal(t, AidToNavigationReport{
+		Header:               Header{MessageType: 21, MMSI: 992351000},
+		AidType:              14,
+		Name:                 "BRAMBLE BANK BUOY",
+		PositionAccuracy:     true,
+		Longitude:            -1.3,
+		Latitude:             50.8,
+		DimensionToBow:       1,
+		DimensionToStern:     1,
+		DimensionToPort:      1,
+		DimensionToStarboard: 1,
+		Timestamp:            61,
+		OffPosition:          true,
+		VirtualAid:           true,
+	}, msg)
+}
+
+func TestDecode_AddressedSafetyMessage(t *testing.T) {
+	pb := header(12, 235000000).uint(2, 2).uint(2320001, 30).uint(1, 1).uint(0, 1).text("ENGINE FAILURE", 84)
+
+	msg, err := NewDecoder().Decode(pb.sentence())
+	require.NoError(t, err)
+	assert.Equal(t, AddressedSafetyMessage{
+		Header:          Header{MessageType: 12, MMSI: 235000000},
+		SequenceNumber:  2,
+		DestinationMMSI: 2320001,
+		Retransmitted:   true,
+		Text:            "ENGINE FAILURE",
+	}, msg)
+}
+
+func TestDecode_SafetyBroadcastMessage(t *testing.T) {
+	pb := header(14, 972123456).uint(0, 2).text("MOB ACTIVE", 60)
+
+	msg, err := NewDecoder().Decode(pb.sentence())
+	require.NoError(t, err)
+	assert.Equal(t, SafetyBroadcastMessage{
+		Header: Header{MessageType: 14, MMSI: 972123456},
+		Text:   "MOB ACTIVE",
+	}, msg)
+}
+
+func TestDecode_StaticDataReport(t *testing.T) {
+	decoder := NewDecoder()
+
+	partA := header(24, 367430530).uint(0, 2).text("SEA BREEZE", 120)
+	msg, err := decoder.Decode(partA.sentence())
+	require.NoError(t, err)
+	assert.Equal(t, StaticDataReport{
+		Header: Header{MessageType: 24, MMSI: 367430530},
+		Name:   "SEA BREEZE",
+	}, msg)
+
+	partB := header(24, 367430530).uint(1, 2).uint(37, 8).text("ABC", 18).uint(0, 24).text("WDF1234", 42).
+		uint(10, 9).uint(5, 9).uint(2, 6).uint(2, 6).uint(0, 6)
+	msg, err = decoder.Decode(partB.sentence())
+	require.NoError(t, err)
+	assert.Equal(t, StaticDataReport{
+		Header:               Header{MessageType: 24, MMSI: 367430530},
+		PartNumber:           1,
+		ShipType:             37,
+		CallSign:             "WDF1234",
+		DimensionToBow:       10,
+		DimensionToStern:     5,
+		DimensionToPort:      2,
+		DimensionToStarboard: 2,
+	}, msg)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tt := map[string]struct {
+		sentence string
+		err      error
+	}{
+		"invalid checksum": {
+			sentence: "!AIVDM,1,1,,B,177KQJ5000G?tO`K>RA1wUbN0TKH,0*5D",
+			err:      ErrInvalidChecksum,
+		},
+		"missing checksum": {
+			sentence: "!AIVDM,1,1,,B,177KQJ5000G?tO`K>RA1wUbN0TKH,0",
+			err:      ErrMalformedSentence,
+		},
+		"unsupported sentence": {
+			sentence: "$GPGLL,4916.45,N,12311.12,W,225444,A*31",
+			err:      ErrUnsupportedSentence,
+		},
+		"unsupported message type": {
+			sentence: header(27, 367430530).uint(0, 58).sentence(),
+			err:      ErrUnsupportedMessageType,
+		},
+		"payload too short": {
+			sentence: header(1, 367430530).uint(0, 30).sentence(),
+			err:      ErrPayloadTooShort,
+		},
+	}
+
+	for name, tc := range tt {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := NewDecoder().Decode(tc.sentence)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
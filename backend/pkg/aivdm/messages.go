@@ -0,0 +1,357 @@
+package aivdm
+
+import "fmt"
+
+// Values used by AIS to indicate that a position is not available
+const (
+	LatitudeNotAvailable  = 91
+	LongitudeNotAvailable = 181
+)
+
+// Message is a decoded AIS message
+type Message interface {
+	MessageHeader() Header
+}
+
+// Header holds the fields common to all AIS messages
+type Header struct {
+	MessageType     int
+	RepeatIndicator int
+	MMSI            int32
+}
+
+func (h Header) MessageHeader() Header {
+	return h
+}
+
+// PositionReport is a Class A position report (message types 1, 2 and 3)
+type PositionReport struct {
+	Header
+	NavigationalStatus int32
+	// RateOfTurn is the raw AIS rate of turn indicator
+	RateOfTurn int32
+	// SpeedOverGround is the speed in knots (102.3 = not available)
+	SpeedOverGround  float64
+	PositionAccuracy bool
+	Longitude        float64
+	Latitude         float64
+	// CourseOverGround is the course in degrees (360 = not available)
+	CourseOverGround float64
+	// TrueHeading is the heading in degrees (511 = not available)
+	TrueHeading int32
+	// Timestamp is the UTC second when the report was generated (60 or above = not available)
+	Timestamp int32
+}
+
+// BaseStationReport is a report from a fixed shore station giving its position and the UTC time kept by its clock
+// (message type 4)
+type BaseStationReport struct {
+	Header
+	// Year is 0 if the time is not available
+	Year int32
+	// Month is 0 if the time is not available
+	Month int32
+	// Day is 0 if the time is not available
+	Day int32
+	// Hour is 24 if the time is not available
+	Hour int32
+	// Minute is 60 if the time is not available
+	Minute int32
+	// Second is 60 if the time is not available
+	Second           int32
+	PositionAccuracy bool
+	Longitude        float64
+	Latitude         float64
+}
+
+// AddressedSafetyMessage is a safety related text message addressed to a single station (message type 12)
+type AddressedSafetyMessage struct {
+	Header
+	SequenceNumber  int
+	DestinationMMSI int32
+	Retransmitted   bool
+	Text            string
+}
+
+// SafetyBroadcastMessage is a safety related text message broadcast to all stations (message type 14). AIS-SART, MOB
+// and EPIRB-AIS devices broadcast "SART ACTIVE", "MOB ACTIVE" or "EPIRB ACTIVE" (or "... TEST" when being tested).
+type SafetyBroadcastMessage struct {
+	Header
+	Text string
+}
+
+// StaticVoyageData is a Class A ship static and voyage related data report (message type 5)
+type StaticVoyageData struct {
+	Header
+	IMONumber            int32
+	CallSign             string
+	Name                 string
+	ShipType             int32
+	DimensionToBow       int32
+	DimensionToStern     int32
+	DimensionToPort      int32
+	DimensionToStarboard int32
+	ETAMonth             int32
+	ETADay               int32
+	ETAHour              int32
+	ETAMinute            int32
+	// Draught is the maximum present static draught in metres
+	Draught     float64
+	Destination string
+}
+
+// StandardClassBPositionReport is a Class B position report (message type 18)
+type StandardClassBPositionReport struct {
+	Header
+	SpeedOverGround  float64
+	PositionAccuracy bool
+	Longitude        float64
+	Latitude         float64
+	CourseOverGround float64
+	TrueHeading      int32
+	Timestamp        int32
+}
+
+// ExtendedClassBPositionReport is a Class B position report that also carries static data (message type 19)
+type ExtendedClassBPositionReport struct {
+	Header
+	SpeedOverGround      float64
+	PositionAccuracy     bool
+	Longitude            float64
+	Latitude             float64
+	CourseOverGround     float64
+	TrueHeading          int32
+	Timestamp            int32
+	Name                 string
+	ShipType             int32
+	DimensionToBow       int32
+	DimensionToStern     int32
+	DimensionToPort      int32
+	DimensionToStarboard int32
+}
+
+// AidToNavigationReport is a report from an aid to navigation such as a buoy or lighthouse (message type 21)
+type AidToNavigationReport struct {
+	Header
+	AidType              int32
+	Name                 string
+	PositionAccuracy     bool
+	Longitude            float64
+	Latitude             float64
+	DimensionToBow       int32
+	DimensionToStern     int32
+	DimensionToPort      int32
+	DimensionToStarboard int32
+	Timestamp            int32
+	OffPosition          bool
+	VirtualAid           bool
+}
+
+// StaticDataReport is a Class B static data report (message type 24). Part A carries the name and part B the
+// remaining static data.
+type StaticDataReport struct {
+	Header
+	PartNumber           int
+	Name                 string
+	ShipType             int32
+	CallSign             string
+	DimensionToBow       int32
+	DimensionToStern     int32
+	DimensionToPort      int32
+	DimensionToStarboard int32
+}
+
+// minimum payload lengths (in bits) accepted for each message type. Some transponders omit trailing spare bits so
+// these are shorter than the lengths given in ITU-R M.1371.
+const (
+	positionReportLength    = 149
+	baseStationReportLength = 134
+	staticVoyageDataLength  = 420
+	classBPositionLength    = 139
+	extendedClassBLength    = 301
+	aidToNavigationLength   = 270
+	staticDataReportALength = 160
+	staticDataReportBLength = 162
+	addressedSafetyLength   = 72
+	safetyBroadcastLength   = 40
+	headerLength            = 38
+)
+
+func decodeMessage(b bits) (Message, error) {
+	if len(b) < headerLength {
+		return nil, fmt.Errorf("%w: %d bits", ErrPayloadTooShort, len(b))
+	}
+	header := Header{
+		MessageType:     int(b.uint(0, 6)),
+		RepeatIndicator: int(b.uint(6, 2)),
+		MMSI:            int32(b.uint(8, 30)),
+	}
+
+	switch header.MessageType {
+	case 1, 2, 3:
+		if err := checkLength(header, b, positionReportLength); err != nil {
+			return nil, err
+		}
+		return PositionReport{
+			Header:             header,
+			NavigationalStatus: int32(b.uint(38, 4)),
+			RateOfTurn:         b.int(42, 8),
+			SpeedOverGround:    float64(b.uint(50, 10)) / 10,
+			PositionAccuracy:   b.bool(60),
+			Longitude:          coordinate(b.int(61, 28)),
+			Latitude:           coordinate(b.int(89, 27)),
+			CourseOverGround:   float64(b.uint(116, 12)) / 10,
+			TrueHeading:        int32(b.uint(128, 9)),
+			Timestamp:          int32(b.uint(137, 6)),
+		}, nil
+	case 4:
+		if err := checkLength(header, b, baseStationReportLength); err != nil {
+			return nil, err
+		}
+		return BaseStationReport{
+			Header:           header,
+			Year:             int32(b.uint(38, 14)),
+			Month:            int32(b.uint(52, 4)),
+			Day:              int32(b.uint(56, 5)),
+			Hour:             int32(b.uint(61, 5)),
+			Minute:           int32(b.uint(66, 6)),
+			Second:           int32(b.uint(72, 6)),
+			PositionAccuracy: b.bool(78),
+			Longitude:        coordinate(b.int(79, 28)),
+			Latitude:         coordinate(b.int(107, 27)),
+		}, nil
+	case 5:
+		if err := checkLength(header, b, staticVoyageDataLength); err != nil {
+			return nil, err
+		}
+		return StaticVoyageData{
+			Header:               header,
+			IMONumber:            int32(b.uint(40, 30)),
+			CallSign:             b.text(70, 42),
+			Name:                 b.text(112, 120),
+			ShipType:             int32(b.uint(232, 8)),
+			DimensionToBow:       int32(b.uint(240, 9)),
+			DimensionToStern:     int32(b.uint(249, 9)),
+			DimensionToPort:      int32(b.uint(258, 6)),
+			DimensionToStarboard: int32(b.uint(264, 6)),
+			ETAMonth:             int32(b.uint(274, 4)),
+			ETADay:               int32(b.uint(278, 5)),
+			ETAHour:              int32(b.uint(283, 5)),
+			ETAMinute:            int32(b.uint(288, 6)),
+			Draught:              float64(b.uint(294, 8)) / 10,
+			Destination:          b.text(302, 120),
+		}, nil
+	case 12:
+		if err := checkLength(header, b, addressedSafetyLength); err != nil {
+			return nil, err
+		}
+		return AddressedSafetyMessage{
+			Header:          header,
+			SequenceNumber:  int(b.uint(38, 2)),
+			DestinationMMSI: int32(b.uint(40, 30)),
+			Retransmitted:   b.bool(70),
+			Text:            b.text(72, len(b)-72),
+		}, nil
+	case 14:
+		if err := checkLength(header, b, safetyBroadcastLength); err != nil {
+			return nil, err
+		}
+		return SafetyBroadcastMessage{
+			Header: header,
+			Text:   b.text(40, len(b)-40),
+		}, nil
+	case 18:
+		if err := checkLength(header, b, classBPositionLength); err != nil {
+			return nil, err
+		}
+		return StandardClassBPositionReport{
+			Header:           header,
+			SpeedOverGround:  float64(b.uint(46, 10)) / 10,
+			PositionAccuracy: b.bool(56),
+			Longitude:        coordinate(b.int(57, 28)),
+			Latitude:         coordinate(b.int(85, 27)),
+			CourseOverGround: float64(b.uint(112, 12)) / 10,
+			TrueHeading:      int32(b.uint(124, 9)),
+			Timestamp:        int32(b.uint(133, 6)),
+		}, nil
+	case 19:
+		if err := checkLength(header, b, extendedClassBLength); err != nil {
+			return nil, err
+		}
+		return ExtendedClassBPositionReport{
+			Header:               header,
+			SpeedOverGround:      float64(b.uint(46, 10)) / 10,
+			PositionAccuracy:     b.bool(56),
+			Longitude:            coordinate(b.int(57, 28)),
+			Latitude:             coordinate(b.int(85, 27)),
+			CourseOverGround:     float64(b.uint(112, 12)) / 10,
+			TrueHeading:          int32(b.uint(124, 9)),
+			Timestamp:            int32(b.uint(133, 6)),
+			Name:                 b.text(143, 120),
+			ShipType:             int32(b.uint(263, 8)),
+			DimensionToBow:       int32(b.uint(271, 9)),
+			DimensionToStern:     int32(b.uint(280, 9)),
+			DimensionToPort:      int32(b.uint(289, 6)),
+			DimensionToStarboard: int32(b.uint(295, 6)),
+		}, nil
+	case 21:
+		if err := checkLength(header, b, aidToNavigationLength); err != nil {
+			return nil, err
+		}
+		return AidToNavigationReport{
+			Header:               header,
+			AidType:              int32(b.uint(38, 5)),
+			Name:                 b.text(43, 120) + b.text(272, len(b)-272),
+			PositionAccuracy:     b.bool(163),
+			Longitude:            coordinate(b.int(164, 28)),
+			Latitude:             coordinate(b.int(192, 27)),
+			DimensionToBow:       int32(b.uint(219, 9)),
+			DimensionToStern:     int32(b.uint(228, 9)),
+			DimensionToPort:      int32(b.uint(237, 6)),
+			DimensionToStarboard: int32(b.uint(243, 6)),
+			Timestamp:            int32(b.uint(253, 6)),
+			OffPosition:          b.bool(259),
+			VirtualAid:           b.bool(269),
+		}, nil
+	case 24:
+		report := StaticDataReport{
+			Header:     header,
+			PartNumber: int(b.uint(38, 2)),
+		}
+		switch report.PartNumber {
+		case 0:
+			if err := checkLength(header, b, staticDataReportALength); err != nil {
+				return nil, err
+			}
+			report.Name = b.text(40, 120)
+		case 1:
+			if err := checkLength(header, b, staticDataReportBLength); err != nil {
+				return nil, err
+			}
+			report.ShipType = int32(b.uint(40, 8))
+			report.CallSign = b.text(90, 42)
+			report.DimensionToBow = int32(b.uint(132, 9))
+			report.DimensionToStern = int32(b.uint(141, 9))
+			report.DimensionToPort = int32(b.uint(150, 6))
+			report.DimensionToStarboard = int32(b.uint(156, 6))
+		default:
+			return nil, fmt.Errorf("%w: type 24 part number %d", ErrUnsupportedMessageType, report.PartNumber)
+		}
+		return report, nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedMessageType, header.MessageType)
+	}
+}
+
+func checkLength(header Header, b bits, minLength int) error {
+	if len(b) < minLength {
+		return fmt.Errorf("%w: message type %d requires at least %d bits but has %d",
+			ErrPayloadTooShort, header.MessageType, minLength, len(b))
+	}
+	return nil
+}
+
+// coordinate converts a latitude or longitude from 1/10000 minutes into degrees
+func coordinate(raw int32) float64 {
+	return float64(raw) / 600000
+}
@@ -0,0 +1,99 @@
+package aivdm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMalformedSentence      = errors.New("malformed sentence")
+	ErrInvalidChecksum        = errors.New("invalid checksum")
+	ErrUnsupportedSentence    = errors.New("unsupported sentence type")
+	ErrOutOfSequenceFragment  = errors.New("out of sequence fragment")
+	ErrUnsupportedMessageType = errors.New("unsupported message type")
+	ErrPayloadTooShort        = errors.New("payload too short")
+)
+
+// Sentence is a single NMEA 0183 AIVDM (reports from other vessels) or AIVDO (reports from own vessel) sentence
+type Sentence struct {
+	// Talker is the talker and sentence identifier, e.g. "AIVDM"
+	Talker string
+	// FragmentCount is the number of sentences the message is split across
+	FragmentCount int
+	// FragmentNumber is the (one-indexed) position of this sentence within the message
+	FragmentNumber int
+	// SequentialID groups the fragments of a multi-sentence message (empty for single sentence messages)
+	SequentialID string
+	// Channel is the VHF radio channel (A or B) the message was received on
+	Channel string
+	// Payload is the 6-bit ASCII armoured message data
+	Payload string
+	// FillBits is the number of bits appended to the final payload character to pad it to 6 bits
+	FillBits int
+}
+
+// ParseSentence parses and validates the checksum of an AIVDM/AIVDO sentence. Any leading NMEA 4.0 tag block
+// (e.g. "\s:receiver,c:1672338151*5C\") is ignored.
+func ParseSentence(line string) (Sentence, error) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, `\`) {
+		end := strings.Index(line[1:], `\`)
+		if end < 0 {
+			return Sentence{}, fmt.Errorf("%w: unterminated tag block", ErrMalformedSentence)
+		}
+		line = line[end+2:]
+	}
+
+	if len(line) == 0 || (line[0] != '!' && line[0] != '$') {
+		return Sentence{}, fmt.Errorf("%w: missing start delimiter", ErrMalformedSentence)
+	}
+	star := strings.LastIndexByte(line, '*')
+	if star < 0 || len(line) < star+3 {
+		return Sentence{}, fmt.Errorf("%w: missing checksum", ErrMalformedSentence)
+	}
+	expected, err := strconv.ParseUint(line[star+1:star+3], 16, 8)
+	if err != nil {
+		return Sentence{}, fmt.Errorf("%w: invalid checksum '%s'", ErrMalformedSentence, line[star+1:star+3])
+	}
+	if actual := checksum(line[1:star]); actual != byte(expected) {
+		return Sentence{}, fmt.Errorf("%w: expected %02X but was %02X", ErrInvalidChecksum, expected, actual)
+	}
+
+	fields := strings.Split(line[1:star], ",")
+	if len(fields) != 7 {
+		return Sentence{}, fmt.Errorf("%w: expected 7 fields but found %d", ErrMalformedSentence, len(fields))
+	}
+	// the talker ID varies between receivers (e.g. AB, AI, BS) so only the sentence identifier is checked
+	if len(fields[0]) != 5 || (fields[0][2:] != "VDM" && fields[0][2:] != "VDO") {
+		return Sentence{}, fmt.Errorf("%w: '%s'", ErrUnsupportedSentence, fields[0])
+	}
+
+	s := Sentence{
+		Talker:       fields[0],
+		SequentialID: fields[3],
+		Channel:      fields[4],
+		Payload:      fields[5],
+	}
+	if s.FragmentCount, err = strconv.Atoi(fields[1]); err != nil || s.FragmentCount < 1 {
+		return Sentence{}, fmt.Errorf("%w: invalid fragment count '%s'", ErrMalformedSentence, fields[1])
+	}
+	if s.FragmentNumber, err = strconv.Atoi(fields[2]); err != nil || s.FragmentNumber < 1 || s.FragmentNumber > s.FragmentCount {
+		return Sentence{}, fmt.Errorf("%w: invalid fragment number '%s'", ErrMalformedSentence, fields[2])
+	}
+	if s.FillBits, err = strconv.Atoi(fields[6]); err != nil || s.FillBits < 0 || s.FillBits > 5 {
+		return Sentence{}, fmt.Errorf("%w: invalid fill bits '%s'", ErrMalformedSentence, fields[6])
+	}
+
+	return s, nil
+}
+
+// checksum returns the XOR of all characters between the start delimiter and the '*'
+func checksum(s string) byte {
+	var sum byte
+	for i := 0; i < len(s); i++ {
+		sum ^= s[i]
+	}
+	return sum
+}
@@ -1,6 +1,9 @@
 package apperrors
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type NoShipFoundErr struct {
 	id int32
@@ -13,3 +16,53 @@ func (e *NoShipFoundErr) Error() string {
 func NewNoShipFoundErr(id int32) *NoShipFoundErr {
 	return &NoShipFoundErr{id: id}
 }
+
+// ErrStoreUnavailable is matched by errors from a data store that couldn't be reached (e.g. because it's restarting),
+// which callers can retry later without treating the data they were storing as bad
+var ErrStoreUnavailable = errors.New("store unavailable")
+
+type storeUnavailableErr struct {
+	err error
+}
+
+func (e *storeUnavailableErr) Error() string {
+	return e.err.Error()
+}
+
+func (e *storeUnavailableErr) Unwrap() error {
+	return e.err
+}
+
+func (e *storeUnavailableErr) Is(target error) bool {
+	return target == ErrStoreUnavailable
+}
+
+// NewStoreUnavailableErr marks err as being caused by a data store being unavailable
+func NewStoreUnavailableErr(err error) error {
+	return &storeUnavailableErr{err: err}
+}
+
+// ErrInvalidReport is matched by errors from the collector service for reports that failed validation, as opposed to
+// valid reports that it couldn't accept
+var ErrInvalidReport = errors.New("invalid report")
+
+type invalidReportErr struct {
+	err error
+}
+
+func (e *invalidReportErr) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidReportErr) Unwrap() error {
+	return e.err
+}
+
+func (e *invalidReportErr) Is(target error) bool {
+	return target == ErrInvalidReport
+}
+
+// NewInvalidReportErr marks err as being caused by a report failing validation
+func NewInvalidReportErr(err error) error {
+	return &invalidReportErr{err: err}
+}
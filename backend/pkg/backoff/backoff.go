@@ -0,0 +1,48 @@
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff calculates jittered exponential delays to wait between successive retry attempts
+type Backoff struct {
+	// Min is the delay used for the first attempt
+	Min time.Duration
+	// Max caps the delay regardless of the number of attempts
+	Max time.Duration
+	// Factor is the multiplier applied to the delay on each subsequent attempt
+	Factor float64
+	// Jitter is the fraction (0-1) of the delay that is randomised to avoid retries happening in lock-step
+	Jitter float64
+}
+
+// New creates a Backoff that doubles the delay on each attempt with 20% jitter
+func New(min, max time.Duration) Backoff {
+	return Backoff{
+		Min:    min,
+		Max:    max,
+		Factor: 2,
+		Jitter: 0.2,
+	}
+}
+
+// Duration returns the delay to wait before the given attempt (zero-indexed)
+func (b Backoff) Duration(attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	delay := float64(b.Min) * math.Pow(b.Factor, float64(attempt))
+	if delay > float64(b.Max) || math.IsInf(delay, 0) || math.IsNaN(delay) {
+		delay = float64(b.Max)
+	}
+
+	if b.Jitter > 0 {
+		// randomly reduce the delay by up to the jitter fraction
+		delay -= delay * b.Jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
@@ -0,0 +1,29 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuration_GrowsExponentiallyUpToMax(t *testing.T) {
+	b := Backoff{Min: time.Second, Max: 10 * time.Second, Factor: 2}
+
+	assert.Equal(t, 1*time.Second, b.Duration(0))
+	assert.Equal(t, 2*time.Second, b.Duration(1))
+	assert.Equal(t, 4*time.Second, b.Duration(2))
+	assert.Equal(t, 8*time.Second, b.Duration(3))
+	assert.Equal(t, 10*time.Second, b.Duration(4))
+	assert.Equal(t, 10*time.Second, b.Duration(1000))
+}
+
+func TestDuration_AppliesJitter(t *testing.T) {
+	b := New(time.Second, time.Minute)
+
+	for i := 0; i < 100; i++ {
+		d := b.Duration(3)
+		assert.LessOrEqual(t, d, 8*time.Second)
+		assert.GreaterOrEqual(t, d, time.Duration(float64(8*time.Second)*0.8))
+	}
+}
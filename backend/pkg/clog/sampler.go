@@ -0,0 +1,41 @@
+package clog
+
+import (
+	"sync"
+	"time"
+)
+
+// Sampler limits how often a repeated event (e.g. a rejected message) is logged to at most once per key per interval,
+// so that a flood of bad input can't flood the logs
+type Sampler struct {
+	mu       sync.Mutex
+	interval time.Duration
+	// lastLogged and unlogged track when each key was last logged and how many events for it have gone unlogged since
+	lastLogged map[string]time.Time
+	unlogged   map[string]int
+}
+
+// NewSampler returns a sampler that logs each key at most once per interval (0 logs every event)
+func NewSampler(interval time.Duration) *Sampler {
+	return &Sampler{
+		interval:   interval,
+		lastLogged: make(map[string]time.Time),
+		unlogged:   make(map[string]int),
+	}
+}
+
+// Sample reports whether an event for the key should be logged. It also returns the number of events for the key that
+// weren't logged since the last one that was.
+func (s *Sampler) Sample(key string, now time.Time) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.lastLogged[key]; ok && now.Sub(last) < s.interval {
+		s.unlogged[key]++
+		return false, 0
+	}
+	unlogged := s.unlogged[key]
+	s.lastLogged[key] = now
+	s.unlogged[key] = 0
+	return true, unlogged
+}
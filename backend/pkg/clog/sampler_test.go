@@ -0,0 +1,40 @@
+package clog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampler_Sample(t *testing.T) {
+	s := NewSampler(time.Second)
+	now := time.Date(2023, time.September, 11, 17, 4, 5, 0, time.UTC)
+
+	log, unlogged := s.Sample("null_island", now)
+	assert.True(t, log)
+	assert.Zero(t, unlogged)
+
+	// further events for the same key within the interval aren't logged, unlike those for other keys
+	log, _ = s.Sample("null_island", now.Add(100*time.Millisecond))
+	assert.False(t, log)
+	log, _ = s.Sample("null_island", now.Add(200*time.Millisecond))
+	assert.False(t, log)
+	log, _ = s.Sample("implied_speed", now.Add(200*time.Millisecond))
+	assert.True(t, log)
+
+	log, unlogged = s.Sample("null_island", now.Add(time.Second))
+	assert.True(t, log)
+	assert.Equal(t, 2, unlogged)
+}
+
+func TestSampler_Sample_ZeroIntervalLogsEveryEvent(t *testing.T) {
+	s := NewSampler(0)
+	now := time.Date(2023, time.September, 11, 17, 4, 5, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		log, unlogged := s.Sample("null_island", now)
+		assert.True(t, log)
+		assert.Zero(t, unlogged)
+	}
+}
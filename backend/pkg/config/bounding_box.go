@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BoundingBox is a named geographic area defined by two opposing corners
+type BoundingBox struct {
+	Name         string
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// BoundingBoxes is a list of bounding boxes that can be decoded from an env var of the form
+// "name:lat1,lon1,lat2,lon2;name:lat1,lon1,lat2,lon2"
+type BoundingBoxes []BoundingBox
+
+// Decode implements the envconfig.Decoder interface
+func (b *BoundingBoxes) Decode(value string) error {
+	var boxes BoundingBoxes
+	names := make(map[string]bool)
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		box, err := parseBoundingBox(entry)
+		if err != nil {
+			return fmt.Errorf("invalid bounding box '%s': %w", entry, err)
+		}
+		// shards refer to bounding boxes by name, so the names must be unique
+		if names[box.Name] {
+			return fmt.Errorf("bounding box '%s' is configured more than once", box.Name)
+		}
+		names[box.Name] = true
+		boxes = append(boxes, box)
+	}
+	*b = boxes
+	return nil
+}
+
+// Names returns the names of the bounding boxes
+func (b BoundingBoxes) Names() []string {
+	names := make([]string, len(b))
+	for i, box := range b {
+		names[i] = box.Name
+	}
+	return names
+}
+
+func parseBoundingBox(entry string) (BoundingBox, error) {
+	name, coords, found := strings.Cut(entry, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return BoundingBox{}, fmt.Errorf("expected format 'name:lat1,lon1,lat2,lon2'")
+	}
+
+	parts := strings.Split(coords, ",")
+	if len(parts) != 4 {
+		return BoundingBox{}, fmt.Errorf("expected 4 coordinates but got %d", len(parts))
+	}
+	values := make([]float64, len(parts))
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return BoundingBox{}, fmt.Errorf("invalid coordinate '%s': %w", part, err)
+		}
+		values[i] = v
+	}
+
+	box := BoundingBox{
+		Name:         strings.TrimSpace(name),
+		MinLatitude:  minFloat(values[0], values[2]),
+		MinLongitude: minFloat(values[1], values[3]),
+		MaxLatitude:  maxFloat(values[0], values[2]),
+		MaxLongitude: maxFloat(values[1], values[3]),
+	}
+	if box.MinLatitude < -90 || box.MaxLatitude > 90 {
+		return BoundingBox{}, fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if box.MinLongitude < -180 || box.MaxLongitude > 180 {
+		return BoundingBox{}, fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return box, nil
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundingBoxes_Decode(t *testing.T) {
+	var boxes BoundingBoxes
+	require.NoError(t, boxes.Decode("north-sea:51,-4,61,10; baltic:66,30,53,9"))
+
+	require.Len(t, boxes, 2)
+	assert.Equal(t, BoundingBox{Name: "north-sea", MinLatitude: 51, MinLongitude: -4, MaxLatitude: 61, MaxLongitude: 10}, boxes[0])
+	// corners are normalised regardless of the order they are given in
+	assert.Equal(t, BoundingBox{Name: "baltic", MinLatitude: 53, MinLongitude: 9, MaxLatitude: 66, MaxLongitude: 30}, boxes[1])
+	assert.Equal(t, []string{"north-sea", "baltic"}, boxes.Names())
+}
+
+func TestBoundingBoxes_Decode_InvalidValues(t *testing.T) {
+	tt := map[string]string{
+		"missing name":           "51,-4,61,10",
+		"too few coordinates":    "north-sea:51,-4,61",
+		"non-numeric":            "north-sea:51,-4,61,east",
+		"latitude out of range":  "north-sea:51,-4,91,10",
+		"longitude out of range": "north-sea:51,-181,61,10",
+		"duplicate name":         "north-sea:51,-4,61,10;north-sea:53,9,66,30",
+	}
+
+	for name, value := range tt {
+		t.Run(name, func(t *testing.T) {
+			var boxes BoundingBoxes
+			assert.Error(t, boxes.Decode(value))
+		})
+	}
+}
+
+func TestLoad_DefaultsToWholeWorld(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Len(t, cfg.WebSocketBoundingBoxes, 1)
+	assert.Equal(t, BoundingBox{Name: "world", MinLatitude: -90, MinLongitude: -180, MaxLatitude: 90, MaxLongitude: 180},
+		cfg.WebSocketBoundingBoxes[0])
+}
@@ -2,12 +2,76 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
-	WebSocketAPIKey string
+	CollectorSources   []string `default:"aisstream"`
+	CollectorQueueSize int      `default:"500"`
+	CollectorWorkers   int      `default:"5"`
+	// CollectorOverflowPolicy is one of block, drop_oldest, drop_newest or coalesce
+	CollectorOverflowPolicy string `default:"block"`
+	// CollectorBlockTimeout is how long the block policy waits for space in the queue (0 waits indefinitely)
+	CollectorBlockTimeout time.Duration `default:"5s"`
+	// CollectorDrainTimeout is how long the collector spends publishing buffered reports when shutting down
+	CollectorDrainTimeout time.Duration `default:"10s"`
+	// CollectorDedupTTL is how long the last accepted position for a ship is remembered to suppress duplicate and
+	// out-of-order positions (0 disables suppression)
+	CollectorDedupTTL time.Duration `default:"1m"`
+	// CollectorDedupCacheSize caps the number of ships remembered by the dedup cache
+	CollectorDedupCacheSize int `default:"100000"`
+	// CollectorPlausibilityMode is one of off, reject or flag
+	CollectorPlausibilityMode string `default:"reject"`
+	// CollectorPlausibilityMaxSpeed is the fastest speed in knots implied by consecutive positions that is plausible
+	CollectorPlausibilityMaxSpeed float64 `default:"60"`
+	// CollectorPlausibilityTTL is how long the last plausible position for a ship is remembered
+	CollectorPlausibilityTTL time.Duration `default:"10m"`
+	// CollectorPlausibilityCacheSize caps the number of ships remembered by the plausibility checks
+	CollectorPlausibilityCacheSize int `default:"100000"`
+	// CollectorPlausibilityLogInterval is the minimum time between logging rejected positions for the same reason (zero
+	// logs every rejection)
+	CollectorPlausibilityLogInterval time.Duration `default:"1s"`
+	// CollectorThrottleWindow limits each ship to publishing at most one position per window (0 disables throttling)
+	CollectorThrottleWindow time.Duration
+	// CollectorThrottleDistance is the distance in metres a ship must move to publish a position within the window
+	CollectorThrottleDistance float64 `default:"50"`
+
+	WebSocketURL                 string `default:"wss://stream.aisstream.io/v0/stream"`
+	WebSocketAPIKey              string
+	WebSocketPingInterval        time.Duration `default:"30s"`
+	WebSocketPongTimeout         time.Duration `default:"60s"`
+	WebSocketReconnectMinBackoff time.Duration `default:"1s"`
+	WebSocketReconnectMaxBackoff time.Duration `default:"2m"`
+	WebSocketBoundingBoxes       BoundingBoxes `default:"world:-90,-180,90,180"`
+	WebSocketMMSIFilter          []string
+	WebSocketMessageTypeFilter   []string
+	// WebSocketSubscriptionFile optionally overrides the bounding boxes and filters above. Unlike the env vars it can be
+	// changed while the collector is running, which reloads it on SIGHUP.
+	WebSocketSubscriptionFile string
+	// WebSocketShards splits the bounding boxes across parallel connections, one per shard (if unset then every
+	// bounding box is subscribed to over a single connection)
+	WebSocketShards Shards
+	// WebSocketRejectLogInterval is the minimum time between logging rejected messages for the same reason (zero logs
+	// every rejection)
+	WebSocketRejectLogInterval time.Duration `default:"1s"`
+
+	NMEAProtocol            string        `default:"tcp"`
+	NMEAAddresses           []string      `default:"localhost:10110"`
+	NMEAReadTimeout         time.Duration `default:"2m"`
+	NMEAReconnectMinBackoff time.Duration `default:"1s"`
+	NMEAReconnectMaxBackoff time.Duration `default:"2m"`
+
+	ReplayFiles []string
+	ReplaySpeed float64 `default:"1"`
+
+	CaptureDirectory   string
+	CaptureMaxFileSize int64         `default:"104857600"`
+	CaptureMaxFileAge  time.Duration `default:"1h"`
+
+	QuarantineFile           string
+	QuarantineSampleInterval time.Duration `default:"1s"`
 
 	ElasticsearchAddress string `default:"http://localhost:9200"`
 	ElasticsearchIndex   string `default:"ship_search_index"`
@@ -18,7 +82,28 @@ type Config struct {
 	KafkaAddress        string `default:"localhost:9092"`
 	KafkaShipDataTopic  string `default:"ship-data-topic"`
 	KafkaShipEventTopic string `default:"ship-event-topic"`
-	KafkaConsumerGroup  string `default:"consumer-group-1"`
+	// KafkaSafetyAlertTopic receives safety related messages and distress beacon positions
+	KafkaSafetyAlertTopic string `default:"safety-alert-topic"`
+	KafkaConsumerGroup    string `default:"consumer-group-1"`
+	// KafkaSafetyAlertConsumerGroup is the consumer group each instance of the ship data service uses to notify its
+	// subscribers of safety alerts, which must be unique to the instance so that every instance receives every alert
+	// (defaults to the consumer group suffixed with the hostname)
+	KafkaSafetyAlertConsumerGroup string
+	// KafkaCommitInterval is how often the offsets of consumed messages are committed (0 commits after every message)
+	KafkaCommitInterval time.Duration `default:"1s"`
+	// KafkaDeadLetterTopic receives the messages that consumers could not process, along with the reason why
+	KafkaDeadLetterTopic string `default:"dead-letter-topic"`
+	// KafkaRetryMaxAttempts is the number of times a consumer tries to process a message before it is dead-lettered
+	// (messages that can never be processed, such as those that can't be decoded, are dead-lettered straight away and
+	// messages that fail because a data store or the schema registry is unavailable are retried until it recovers)
+	KafkaRetryMaxAttempts int           `default:"5"`
+	KafkaRetryMinBackoff  time.Duration `default:"500ms"`
+	KafkaRetryMaxBackoff  time.Duration `default:"30s"`
+	// KafkaSerializationFormat is the encoding of ship positions and ship location updated events (json, protobuf or
+	// avro). Consumers read every format so producers can be switched first.
+	KafkaSerializationFormat string `default:"json"`
+	// KafkaSchemaRegistryURL is the schema registry holding the Protobuf and Avro schemas
+	KafkaSchemaRegistryURL string `default:"http://localhost:8081"`
 
 	PostgresUsername string `default:"postgres"`
 	PostgresPassword string `default:"postgres"`
@@ -28,6 +113,9 @@ type Config struct {
 	PrometheusServerAddress string `default:":2112"`
 
 	TracingCollectorAddress string `default:"localhost:4318"`
+	// TracingSampleRatio is the fraction (0-1) of new traces that are sampled. Spans continuing a trace from another
+	// service follow that service's decision, so a trace is either exported in full or not at all.
+	TracingSampleRatio float64 `default:"1"`
 }
 
 func Load() (*Config, error) {
@@ -36,5 +124,10 @@ func Load() (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error on reading config env vars :%w", err)
 	}
+	if cfg.WebSocketSubscriptionFile != "" {
+		if err := cfg.applySubscriptionFile(); err != nil {
+			return nil, fmt.Errorf("error on reading subscription file: %w", err)
+		}
+	}
 	return &cfg, nil
 }
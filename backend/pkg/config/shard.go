@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shard is a named group of bounding boxes that are subscribed to over their own connection
+type Shard struct {
+	Name          string
+	BoundingBoxes []string
+}
+
+// Shards is a list of shards that can be decoded from an env var of the form "name:box1,box2;name:box3" where each
+// box is the name of a bounding box
+type Shards []Shard
+
+// Decode implements the envconfig.Decoder interface
+func (s *Shards) Decode(value string) error {
+	var shards Shards
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		shard, err := parseShard(entry)
+		if err != nil {
+			return fmt.Errorf("invalid shard '%s': %w", entry, err)
+		}
+		shards = append(shards, shard)
+	}
+	*s = shards
+	return nil
+}
+
+// Names returns the names of the shards
+func (s Shards) Names() []string {
+	names := make([]string, len(s))
+	for i, shard := range s {
+		names[i] = shard.Name
+	}
+	return names
+}
+
+func parseShard(entry string) (Shard, error) {
+	name, boxes, found := strings.Cut(entry, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return Shard{}, fmt.Errorf("expected format 'name:box1,box2'")
+	}
+
+	shard := Shard{Name: strings.TrimSpace(name)}
+	for _, box := range strings.Split(boxes, ",") {
+		if box = strings.TrimSpace(box); box != "" {
+			shard.BoundingBoxes = append(shard.BoundingBoxes, box)
+		}
+	}
+	if len(shard.BoundingBoxes) == 0 {
+		return Shard{}, fmt.Errorf("at least one bounding box must be given")
+	}
+	return shard, nil
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShards_Decode(t *testing.T) {
+	var shards Shards
+	require.NoError(t, shards.Decode("europe:north-sea, baltic; asia:south-china-sea"))
+
+	require.Len(t, shards, 2)
+	assert.Equal(t, Shard{Name: "europe", BoundingBoxes: []string{"north-sea", "baltic"}}, shards[0])
+	assert.Equal(t, Shard{Name: "asia", BoundingBoxes: []string{"south-china-sea"}}, shards[1])
+	assert.Equal(t, []string{"europe", "asia"}, shards.Names())
+}
+
+func TestShards_Decode_InvalidValues(t *testing.T) {
+	tt := map[string]string{
+		"missing name":           "north-sea,baltic",
+		"missing bounding boxes": "europe:",
+		"blank bounding boxes":   "europe: , ",
+	}
+
+	for name, value := range tt {
+		t.Run(name, func(t *testing.T) {
+			var shards Shards
+			assert.Error(t, shards.Decode(value))
+		})
+	}
+}
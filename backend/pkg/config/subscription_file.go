@@ -0,0 +1,71 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Keys of the settings that can be set in the subscription file, which are the same as those of the env vars
+const (
+	subscriptionKeyBoundingBoxes     = "SHIPLOC_WEBSOCKETBOUNDINGBOXES"
+	subscriptionKeyMMSIFilter        = "SHIPLOC_WEBSOCKETMMSIFILTER"
+	subscriptionKeyMessageTypeFilter = "SHIPLOC_WEBSOCKETMESSAGETYPEFILTER"
+)
+
+// applySubscriptionFile overrides the web socket bounding boxes and filters with those set in the subscription file.
+// The file holds KEY="value" lines in the same format as the env vars, and blank lines and lines starting with '#' are
+// ignored.
+func (c *Config) applySubscriptionFile() error {
+	f, err := os.Open(c.WebSocketSubscriptionFile)
+	if err != nil {
+		return fmt.Errorf("failed to open subscription file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid subscription file line %d: expected format 'KEY=value'", line)
+		}
+		key, value = strings.TrimSpace(key), unquote(strings.TrimSpace(value))
+
+		switch key {
+		case subscriptionKeyBoundingBoxes:
+			if err := c.WebSocketBoundingBoxes.Decode(value); err != nil {
+				return fmt.Errorf("invalid subscription file line %d: %w", line, err)
+			}
+		case subscriptionKeyMMSIFilter:
+			c.WebSocketMMSIFilter = splitList(value)
+		case subscriptionKeyMessageTypeFilter:
+			c.WebSocketMessageTypeFilter = splitList(value)
+		default:
+			return fmt.Errorf("invalid subscription file line %d: unknown key '%s'", line, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read subscription file: %w", err)
+	}
+	return nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// splitList splits a comma separated list in the same way as envconfig does, with an empty value clearing the list
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
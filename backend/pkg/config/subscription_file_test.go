@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_SubscriptionFileOverridesEnvVars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subscription.env")
+	require.NoError(t, os.WriteFile(path, []byte(`# only the North Sea
+SHIPLOC_WEBSOCKETBOUNDINGBOXES="north-sea:51,-4,61,10"
+
+SHIPLOC_WEBSOCKETMMSIFILTER=259000420,257000000
+`), 0o644))
+	t.Setenv("SHIPLOC_WEBSOCKETSUBSCRIPTIONFILE", path)
+	t.Setenv("SHIPLOC_WEBSOCKETMESSAGETYPEFILTER", "PositionReport")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"north-sea"}, cfg.WebSocketBoundingBoxes.Names())
+	assert.Equal(t, []string{"259000420", "257000000"}, cfg.WebSocketMMSIFilter)
+	// settings that aren't in the file are left as they are
+	assert.Equal(t, []string{"PositionReport"}, cfg.WebSocketMessageTypeFilter)
+
+	// the file is read again each time the config is loaded
+	require.NoError(t, os.WriteFile(path, []byte(`SHIPLOC_WEBSOCKETBOUNDINGBOXES="baltic:53,9,66,30"
+SHIPLOC_WEBSOCKETMESSAGETYPEFILTER=""
+`), 0o644))
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baltic"}, cfg.WebSocketBoundingBoxes.Names())
+	assert.Empty(t, cfg.WebSocketMMSIFilter)
+	assert.Empty(t, cfg.WebSocketMessageTypeFilter)
+}
+
+func TestLoad_InvalidSubscriptionFile(t *testing.T) {
+	tt := map[string]string{
+		"missing value":        "SHIPLOC_WEBSOCKETBOUNDINGBOXES\n",
+		"unknown key":          "SHIPLOC_WEBSOCKETAPIKEY=API-KEY\n",
+		"invalid bounding box": "SHIPLOC_WEBSOCKETBOUNDINGBOXES=north-sea:51,-4,61\n",
+	}
+
+	for name, contents := range tt {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "subscription.env")
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+			t.Setenv("SHIPLOC_WEBSOCKETSUBSCRIPTIONFILE", path)
+
+			_, err := Load()
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv("SHIPLOC_WEBSOCKETSUBSCRIPTIONFILE", filepath.Join(t.TempDir(), "missing.env"))
+		_, err := Load()
+		assert.Error(t, err)
+	})
+}
@@ -16,8 +16,19 @@ import (
 type Client struct {
 	httpServer *http.Server
 
-	dbQueryTimeHistogram      *prometheus.HistogramVec
-	kafkaConsumeTimeHistogram *prometheus.HistogramVec
+	dbQueryTimeHistogram        *prometheus.HistogramVec
+	kafkaConsumeTimeHistogram   *prometheus.HistogramVec
+	kafkaRetriedCounter         *prometheus.CounterVec
+	kafkaDeadLetteredCounter    *prometheus.CounterVec
+	webSocketReconnectCounter   *prometheus.CounterVec
+	webSocketConnectedGauge     *prometheus.GaugeVec
+	webSocketReceivedCounter    *prometheus.CounterVec
+	packetRejectedCounter       *prometheus.CounterVec
+	collectorQueueDepthGauge    prometheus.Gauge
+	collectorDroppedCounter     *prometheus.CounterVec
+	collectorThrottledCounter   prometheus.Counter
+	collectorSuppressedCounter  *prometheus.CounterVec
+	collectorImplausibleCounter *prometheus.CounterVec
 }
 
 func New(cfg config.Config) *Client {
@@ -46,6 +57,61 @@ func New(cfg config.Config) *Client {
 		Help: "Kafka consume time",
 	}, []string{"topic"})
 
+	client.kafkaRetriedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_messages_retried_total",
+		Help: "Number of times a consumer has retried processing a Kafka message",
+	}, []string{"topic"})
+
+	client.kafkaDeadLetteredCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_messages_dead_lettered_total",
+		Help: "Number of Kafka messages routed to the dead-letter topic",
+	}, []string{"topic"})
+
+	client.webSocketReconnectCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "websocket_reconnects_total",
+		Help: "Number of times a web socket connection has been re-established",
+	}, []string{"source"})
+
+	client.webSocketConnectedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "websocket_connected",
+		Help: "Whether a web socket connection is currently established (1) or not (0)",
+	}, []string{"source"})
+
+	client.webSocketReceivedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "websocket_messages_received_total",
+		Help: "Number of messages received over a web socket connection",
+	}, []string{"source"})
+
+	client.packetRejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "packets_rejected_total",
+		Help: "Number of packets received from a source that were rejected",
+	}, []string{"source", "reason"})
+
+	client.collectorQueueDepthGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "collector_queue_depth",
+		Help: "Number of reports waiting to be published by the collector",
+	})
+
+	client.collectorDroppedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "collector_reports_dropped_total",
+		Help: "Number of reports dropped by the collector's queue overflow policy",
+	}, []string{"reason"})
+
+	client.collectorThrottledCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "collector_positions_throttled_total",
+		Help: "Number of ship positions held back by the collector's per-MMSI throttle",
+	})
+
+	client.collectorSuppressedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "collector_positions_suppressed_total",
+		Help: "Number of duplicate or out-of-order ship positions suppressed by the collector",
+	}, []string{"reason"})
+
+	client.collectorImplausibleCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "collector_positions_implausible_total",
+		Help: "Number of implausible ship positions rejected or flagged by the collector",
+	}, []string{"reason"})
+
 	return client
 }
 
@@ -11,3 +11,51 @@ func (c *Client) DBQueryTime(queryName string, startTime time.Time) {
 func (c *Client) KafkaConsumeTime(topic string, startTime time.Time) {
 	c.kafkaConsumeTimeHistogram.WithLabelValues(topic).Observe(time.Since(startTime).Seconds())
 }
+
+func (c *Client) KafkaMessageRetried(topic string) {
+	c.kafkaRetriedCounter.WithLabelValues(topic).Inc()
+}
+
+func (c *Client) KafkaMessageDeadLettered(topic string) {
+	c.kafkaDeadLetteredCounter.WithLabelValues(topic).Inc()
+}
+
+func (c *Client) WebSocketReconnect(source string) {
+	c.webSocketReconnectCounter.WithLabelValues(source).Inc()
+}
+
+func (c *Client) WebSocketConnected(source string, connected bool) {
+	value := 0.0
+	if connected {
+		value = 1.0
+	}
+	c.webSocketConnectedGauge.WithLabelValues(source).Set(value)
+}
+
+func (c *Client) WebSocketMessageReceived(source string) {
+	c.webSocketReceivedCounter.WithLabelValues(source).Inc()
+}
+
+func (c *Client) PacketRejected(source, reason string) {
+	c.packetRejectedCounter.WithLabelValues(source, reason).Inc()
+}
+
+func (c *Client) CollectorQueueDepth(depth int) {
+	c.collectorQueueDepthGauge.Set(float64(depth))
+}
+
+func (c *Client) CollectorReportDropped(reason string) {
+	c.collectorDroppedCounter.WithLabelValues(reason).Inc()
+}
+
+func (c *Client) CollectorPositionThrottled() {
+	c.collectorThrottledCounter.Inc()
+}
+
+func (c *Client) CollectorPositionSuppressed(reason string) {
+	c.collectorSuppressedCounter.WithLabelValues(reason).Inc()
+}
+
+func (c *Client) CollectorPositionImplausible(reason string) {
+	c.collectorImplausibleCounter.WithLabelValues(reason).Inc()
+}
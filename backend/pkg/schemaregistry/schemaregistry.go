@@ -0,0 +1,166 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schema types as named by the registry. Schemas registered without a type are Avro schemas.
+const (
+	SchemaTypeAvro     = "AVRO"
+	SchemaTypeProtobuf = "PROTOBUF"
+	SchemaTypeJSON     = "JSON"
+)
+
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// ErrUnavailable is wrapped by errors caused by the registry being unreachable or failing, which may succeed if retried
+var ErrUnavailable = errors.New("schema registry unavailable")
+
+// Schema is a schema stored in the registry
+type Schema struct {
+	Type   string
+	Schema string
+}
+
+// Client registers and looks up schemas via the HTTP API of a Confluent compatible schema registry. Schema IDs never
+// change once assigned so lookups are cached.
+type Client struct {
+	url        string
+	httpClient *http.Client
+
+	mu      sync.RWMutex
+	ids     map[string]int
+	schemas map[int]Schema
+}
+
+func New(registryURL string) *Client {
+	return &Client{
+		url:        strings.TrimSuffix(registryURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		ids:        make(map[string]int),
+		schemas:    make(map[int]Schema),
+	}
+}
+
+type schemaRequest struct {
+	SchemaType string `json:"schemaType,omitempty"`
+	Schema     string `json:"schema"`
+}
+
+type schemaResponse struct {
+	ID         int    `json:"id"`
+	SchemaType string `json:"schemaType"`
+	Schema     string `json:"schema"`
+}
+
+type errorResponse struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// Register registers the schema under the subject (if it isn't already registered) and returns its ID
+func (c *Client) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	cacheKey := subject + "\x00" + schema.Type + "\x00" + schema.Schema
+	c.mu.RLock()
+	id, found := c.ids[cacheKey]
+	c.mu.RUnlock()
+	if found {
+		return id, nil
+	}
+
+	body, err := json.Marshal(schemaRequest{SchemaType: schemaTypeForRequest(schema.Type), Schema: schema.Schema})
+	if err != nil {
+		return 0, fmt.Errorf("error on marshalling schema: %w", err)
+	}
+	var resp schemaResponse
+	path := fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject))
+	if err := c.do(ctx, http.MethodPost, path, body, &resp); err != nil {
+		return 0, fmt.Errorf("error on registering schema for subject '%s': %w", subject, err)
+	}
+
+	c.mu.Lock()
+	c.ids[cacheKey] = resp.ID
+	c.schemas[resp.ID] = schema
+	c.mu.Unlock()
+	return resp.ID, nil
+}
+
+// SchemaByID returns the schema with the given ID
+func (c *Client) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	c.mu.RLock()
+	schema, found := c.schemas[id]
+	c.mu.RUnlock()
+	if found {
+		return schema, nil
+	}
+
+	var resp schemaResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &resp); err != nil {
+		return Schema{}, fmt.Errorf("error on looking up schema %d: %w", id, err)
+	}
+	schema = Schema{Type: resp.SchemaType, Schema: resp.Schema}
+	if schema.Type == "" {
+		schema.Type = SchemaTypeAvro
+	}
+
+	c.mu.Lock()
+	c.schemas[id] = schema
+	c.mu.Unlock()
+	return schema, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error on creating request: %w", err)
+	}
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: error on reading response: %s", ErrUnavailable, err.Error())
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		message := strings.TrimSpace(string(b))
+		if json.Unmarshal(b, &errResp) == nil && errResp.Message != "" {
+			message = errResp.Message
+		}
+		err := fmt.Errorf("registry responded with status %d: %s", resp.StatusCode, message)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+		}
+		return err
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("error on unmarshalling response: %w", err)
+	}
+	return nil
+}
+
+// schemaTypeForRequest omits the type for Avro schemas so that registries pre-dating schema types accept them
+func schemaTypeForRequest(schemaType string) string {
+	if schemaType == SchemaTypeAvro {
+		return ""
+	}
+	return schemaType
+}
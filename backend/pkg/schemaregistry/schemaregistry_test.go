@@ -0,0 +1,72 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RegisterAndLookup(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/subjects/shiplocator.v1.ShipPosition/versions":
+			var req schemaRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, SchemaTypeProtobuf, req.SchemaType)
+			assert.Equal(t, "syntax = \"proto3\";", req.Schema)
+			_, _ = w.Write([]byte(`{"id":7}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/schemas/ids/8":
+			// Avro schemas are returned without a type
+			_, _ = w.Write([]byte(`{"schema":"{\"type\":\"string\"}"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+		}
+	}))
+	defer server.Close()
+	client := New(server.URL)
+	ctx := context.Background()
+
+	schema := Schema{Type: SchemaTypeProtobuf, Schema: "syntax = \"proto3\";"}
+	id, err := client.Register(ctx, "shiplocator.v1.ShipPosition", schema)
+	require.NoError(t, err)
+	assert.Equal(t, 7, id)
+	id, err = client.Register(ctx, "shiplocator.v1.ShipPosition", schema)
+	require.NoError(t, err)
+	assert.Equal(t, 7, id)
+	registered, err := client.SchemaByID(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, schema, registered)
+	assert.Equal(t, int32(1), requests.Load(), "registered schemas should be cached")
+
+	avro, err := client.SchemaByID(ctx, 8)
+	require.NoError(t, err)
+	assert.Equal(t, Schema{Type: SchemaTypeAvro, Schema: `{"type":"string"}`}, avro)
+
+	_, err = client.SchemaByID(ctx, 9)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Schema not found")
+	assert.NotErrorIs(t, err, ErrUnavailable)
+}
+
+func TestClient_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	client := New(server.URL)
+
+	_, err := client.SchemaByID(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	server.Close()
+	_, err = client.Register(context.Background(), "shiplocator.v1.ShipPosition", Schema{Type: SchemaTypeAvro})
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 
 	"github.com/mikeewhite/ship-locator/backend/pkg/clog"
@@ -31,6 +32,7 @@ func NewTraceProvider(ctx context.Context, cfg config.Config, appName string) (*
 	}
 	traceProvider := tracesdk.NewTracerProvider(
 		tracesdk.WithBatcher(exporter),
+		tracesdk.WithSampler(tracesdk.ParentBased(tracesdk.TraceIDRatioBased(cfg.TracingSampleRatio))),
 		tracesdk.WithResource(resource.NewWithAttributes(
 			semconv.SchemaURL,
 			semconv.ServiceName(appName),
@@ -39,8 +41,11 @@ func NewTraceProvider(ctx context.Context, cfg config.Config, appName string) (*
 
 	// register the TraceProvider as the global instance
 	otel.SetTracerProvider(traceProvider)
+	// trace context is passed between services in W3C traceparent/tracestate (and baggage) headers
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 
-	clog.Infof("Exporting traces for '%s' to collector at %s", appName, cfg.TracingCollectorAddress)
+	clog.Infof("Exporting traces for '%s' to collector at %s (sample ratio %g)", appName, cfg.TracingCollectorAddress,
+		cfg.TracingSampleRatio)
 
 	return &TraceProvider{traceProvider: traceProvider}, nil
 }